@@ -35,8 +35,7 @@ export interface CreateTaskRequest {
   name: string
   types: string
   params: string
-  total_steps: number
-  current_state: string
+  priority?: number
 }
 
 export interface UpdateTaskRequest {
@@ -52,6 +51,8 @@ export interface UpdateTaskRequest {
 export interface TaskPlanDetail {
   pid: string
   name: string
+  before_pid: string
+  next: string
   index: number
   status: string
   params: string
@@ -62,6 +63,11 @@ export interface TaskPlanDetail {
   updated_at: string
 }
 
+export interface TaskPlanEdge {
+  from: string
+  to: string
+}
+
 export interface TaskVisualization {
   tid: string
   name: string
@@ -71,6 +77,7 @@ export interface TaskVisualization {
   total_steps: number
   current_step: number
   plans: TaskPlanDetail[]
+  edges: TaskPlanEdge[]
   created_at: string
   updated_at: string
 }
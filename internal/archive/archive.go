@@ -0,0 +1,245 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/XXueTu/wise/internal/svc"
+)
+
+// Version 当前归档格式版本, 结构不兼容时递增
+// 版本 2 增加标签别名、标签规则和资源关键词
+const Version = 2
+
+// 支持的导出格式
+const (
+	FormatJson     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// ErrUnsupportedVersion 归档版本高于当前程序支持的版本
+var ErrUnsupportedVersion = errors.New("unsupported archive version")
+
+// Archive 知识库归档
+type Archive struct {
+	Version    int        `json:"version"`     // 归档格式版本
+	ExportedAt time.Time  `json:"exported_at"` // 导出时间
+	Tags       []Tag      `json:"tags"`        // 标签
+	Resources  []Resource `json:"resources"`   // 资源
+	Rules      []Rule     `json:"rules"`       // 标签规则
+	Models     []Model    `json:"models"`      // 模型, 不含密钥
+}
+
+// Tag 标签, 导入时按唯一标识匹配
+type Tag struct {
+	Uid         string   `json:"uid"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Color       string   `json:"color"`
+	Icon        string   `json:"icon"`
+	ParentUid   string   `json:"parent_uid,omitempty"` // 父标签唯一标识
+	Aliases     []string `json:"aliases,omitempty"`    // 别名
+}
+
+// Resource 资源, 导入时按链接匹配
+type Resource struct {
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Summary   string    `json:"summary"` // 摘要
+	Content   string    `json:"content"`
+	Type      string    `json:"type"`
+	Tags      []string  `json:"tags"`               // 标签唯一标识
+	Keywords  []Keyword `json:"keywords,omitempty"` // 关键词
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Keyword 资源关键词
+type Keyword struct {
+	Keyword string  `json:"keyword"`
+	Type    string  `json:"type"`
+	Weight  float64 `json:"weight"`
+}
+
+// Rule 标签规则, 导入时按唯一标识匹配
+type Rule struct {
+	Uid      string `json:"uid"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Field    string `json:"field"`
+	Pattern  string `json:"pattern"`
+	Action   string `json:"action"`
+	TagUid   string `json:"tag_uid"` // 标签唯一标识
+	Priority int64  `json:"priority"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// Model 模型配置, 密钥已移除
+type Model struct {
+	BaseUrl       string `json:"base_url"`
+	Config        string `json:"config"`
+	Type          string `json:"type"`
+	ModelName     string `json:"model_name"`
+	ModelRealName string `json:"model_real_name"`
+	Status        string `json:"status"`
+	Tag           string `json:"tag"`
+}
+
+// Export 读取知识库生成归档
+func Export(ctx context.Context, svcCtx *svc.ServiceContext) (*Archive, error) {
+	tags, err := svcCtx.TagsModel.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := svcCtx.ResourceModel.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	models, err := svcCtx.ModelsModel.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	links, err := svcCtx.ResourceTagsModel.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resourceTags := make(map[int64][]string)
+	for _, link := range links {
+		resourceTags[link.ResourceID] = append(resourceTags[link.ResourceID], link.TagUid)
+	}
+	aliases, err := svcCtx.TagAliasesModel.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tagAliases := make(map[string][]string)
+	for _, alias := range aliases {
+		tagAliases[alias.TagUid] = append(tagAliases[alias.TagUid], alias.Alias)
+	}
+	keywords, err := svcCtx.ResourceKeywordsModel.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resourceKeywords := make(map[int64][]Keyword)
+	for _, keyword := range keywords {
+		resourceKeywords[keyword.ResourceID] = append(resourceKeywords[keyword.ResourceID], Keyword{
+			Keyword: keyword.Keyword,
+			Type:    keyword.Type,
+			Weight:  keyword.Weight,
+		})
+	}
+	rules, err := svcCtx.TagRulesModel.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	archive := &Archive{
+		Version:    Version,
+		ExportedAt: time.Now(),
+		Tags:       make([]Tag, 0, len(tags)),
+		Resources:  make([]Resource, 0, len(resources)),
+		Rules:      make([]Rule, 0, len(rules)),
+		Models:     make([]Model, 0, len(models)),
+	}
+	for _, tag := range tags {
+		archive.Tags = append(archive.Tags, Tag{
+			Uid:         tag.Uid,
+			Name:        tag.Name,
+			Description: tag.Description,
+			Color:       tag.Color,
+			Icon:        tag.Icon,
+			ParentUid:   tag.ParentUid,
+			Aliases:     tagAliases[tag.Uid],
+		})
+	}
+	for _, resource := range resources {
+		tags := resourceTags[resource.ID]
+		if tags == nil {
+			tags = make([]string, 0)
+		}
+		archive.Resources = append(archive.Resources, Resource{
+			URL:       resource.URL,
+			Title:     resource.Title,
+			Summary:   resource.Describe,
+			Content:   resource.Content,
+			Type:      resource.Type,
+			Tags:      tags,
+			Keywords:  resourceKeywords[resource.ID],
+			CreatedAt: resource.CreatedAt,
+			UpdatedAt: resource.UpdatedAt,
+		})
+	}
+	for _, rule := range rules {
+		archive.Rules = append(archive.Rules, Rule{
+			Uid:      rule.Uid,
+			Name:     rule.Name,
+			Type:     rule.Type,
+			Field:    rule.Field,
+			Pattern:  rule.Pattern,
+			Action:   rule.Action,
+			TagUid:   rule.TagUid,
+			Priority: rule.Priority,
+			Enabled:  rule.Enabled,
+		})
+	}
+	for _, model := range models {
+		config, _ := RedactConfig(model.Config)
+		archive.Models = append(archive.Models, Model{
+			BaseUrl:       model.BaseUrl,
+			Config:        config,
+			Type:          model.Type,
+			ModelName:     model.ModelName,
+			ModelRealName: model.ModelRealName,
+			Status:        model.Status,
+			Tag:           model.Tag,
+		})
+	}
+	return archive, nil
+}
+
+// WriteJson 以 JSON 写出归档
+func WriteJson(w io.Writer, archive *Archive) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(archive)
+}
+
+// ReadJson 读取 JSON 归档并校验版本
+func ReadJson(r io.Reader) (*Archive, error) {
+	var archive Archive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, err
+	}
+	if archive.Version < 1 || archive.Version > Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, archive.Version)
+	}
+	return &archive, nil
+}
+
+// 配置中名称包含以下关键字的字段视为密钥
+var secretKeys = []string{"key", "secret", "token", "password"}
+
+// RedactConfig 移除模型配置中的密钥, 返回处理后的配置和是否移除了字段
+func RedactConfig(config string) (string, bool) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(config), &fields); err != nil {
+		// 无法解析的配置整体丢弃, 避免泄露
+		return "{}", config != "" && config != "{}"
+	}
+	redacted := false
+	for name := range fields {
+		lower := strings.ToLower(name)
+		for _, key := range secretKeys {
+			if strings.Contains(lower, key) {
+				delete(fields, name)
+				redacted = true
+				break
+			}
+		}
+	}
+	data, _ := json.Marshal(fields)
+	return string(data), redacted
+}
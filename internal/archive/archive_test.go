@@ -0,0 +1,266 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/model/migrations"
+	"github.com/XXueTu/wise/internal/svc"
+)
+
+func TestRedactConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       string
+		want         string
+		wantRedacted bool
+	}{
+		{name: "api-key", config: `{"apiKey":"secret","temperature":0.5}`, want: `{"temperature":0.5}`, wantRedacted: true},
+		{name: "no-secret", config: `{"temperature":0.5}`, want: `{"temperature":0.5}`, wantRedacted: false},
+		{name: "access-token", config: `{"AccessToken":"x","Password":"y"}`, want: `{}`, wantRedacted: true},
+		{name: "invalid", config: `apiKey=secret`, want: `{}`, wantRedacted: true},
+		{name: "empty", config: `{}`, want: `{}`, wantRedacted: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, redacted := RedactConfig(tt.config)
+			if got != tt.want || redacted != tt.wantRedacted {
+				t.Errorf("RedactConfig() = %s, %v, want %s, %v", got, redacted, tt.want, tt.wantRedacted)
+			}
+		})
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	created := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	archive := &Archive{
+		Version:    Version,
+		ExportedAt: created,
+		Tags:       []Tag{{Uid: "u1", Name: "Go 语言"}},
+		Resources: []Resource{
+			{URL: "https://go.dev", Title: "Go: blog", Summary: "摘要", Content: "正文", Tags: []string{"u1", "旧标签"}, CreatedAt: created},
+			{URL: "https://go.dev/2", Title: "Go: blog", CreatedAt: created},
+			{URL: "https://example.com/a", CreatedAt: created},
+		},
+		Models: []Model{},
+	}
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, archive); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	wantNames := []string{"Go- blog.md", "Go- blog 2.md", "example.com.md", ArchiveEntry}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("files = %v, want %v", names, wantNames)
+	}
+
+	f, _ := zr.Open("Go- blog.md")
+	content, _ := io.ReadAll(f)
+	for _, want := range []string{"title: \"Go: blog\"\n", "tags:\n  - \"Go-语言\"\n  - \"旧标签\"\n", "created: 2024-05-01T08:00:00Z\n", "> 摘要\n", "正文\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("markdown missing %q:\n%s", want, content)
+		}
+	}
+
+	restored, err := ReadZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored, archive) {
+		t.Errorf("ReadZip() = %+v, want %+v", restored, archive)
+	}
+}
+
+func TestReadJsonVersion(t *testing.T) {
+	if _, err := ReadJson(strings.NewReader(`{"version":99}`)); err == nil {
+		t.Error("ReadJson() should reject newer version")
+	}
+	if _, err := ReadJson(strings.NewReader(`{"version":1}`)); err != nil {
+		t.Errorf("ReadJson() error = %v", err)
+	}
+}
+
+func newTestDB(t *testing.T) *bun.DB {
+	sqldb, err := sql.Open(sqliteshim.ShimName, filepath.Join(t.TempDir(), "wise.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { _ = db.Close() })
+	if _, err := migrations.Up(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestImportRollback(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	// 最后写入的模型失败, 之前的标签和资源也不应写入
+	if _, err := db.ExecContext(ctx, "CREATE TRIGGER fail_models BEFORE INSERT ON models BEGIN SELECT RAISE(ABORT, 'boom'); END"); err != nil {
+		t.Fatal(err)
+	}
+	svcCtx := &svc.ServiceContext{DB: &model.DB{Writer: db, Reader: db}}
+	archive := &Archive{
+		Version:   Version,
+		Tags:      []Tag{{Uid: "go", Name: "Go"}},
+		Resources: []Resource{{URL: "https://go.dev", Title: "Go", Tags: []string{"go"}}},
+		Models:    []Model{{BaseUrl: "http://localhost", ModelName: "m", ModelRealName: "m", Config: "{}"}},
+	}
+	if _, err := Import(ctx, svcCtx, archive); err == nil {
+		t.Fatal("Import() should fail")
+	}
+	for _, m := range []any{(*model.Tags)(nil), (*model.Resource)(nil), (*model.ResourceTags)(nil)} {
+		count, err := db.NewSelect().Model(m).Count(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%T rows after failed import = %d, want 0", m, count)
+		}
+	}
+}
+
+func TestImportTags(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	svcCtx := &svc.ServiceContext{DB: &model.DB{Writer: db, Reader: db}}
+	if _, err := db.NewInsert().Model(&model.Tags{Uid: "local-go", Name: "Golang"}).Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// go 按名称匹配本地标签, a 和 b 互为父标签, c 的父标签不存在
+	archive := &Archive{
+		Version: Version,
+		Tags: []Tag{
+			{Uid: "go", Name: "golang"},
+			{Uid: "a", Name: "A", ParentUid: "b"},
+			{Uid: "b", Name: "B", ParentUid: "a"},
+			{Uid: "c", Name: "C", ParentUid: "missing"},
+			{Uid: "gin", Name: "Gin", ParentUid: "go"},
+		},
+		Resources: []Resource{{URL: "https://go.dev", Title: "Go", Tags: []string{"go", "missing", "a"}}},
+	}
+	result, err := Import(ctx, svcCtx, archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ImportResult{TagsCreated: 4, TagsUpdated: 1, ParentsSkipped: 2, ResourcesCreated: 1, ResourceTagsSkipped: 1}
+	if *result != want {
+		t.Errorf("Import() = %+v, want %+v", *result, want)
+	}
+
+	var tags []*model.Tags
+	if err := db.NewSelect().Model(&tags).Order("id ASC").Scan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tag := range tags {
+		got = append(got, tag.Uid+":"+tag.Name+">"+tag.ParentUid)
+	}
+	if strings.Join(got, ",") != "local-go:golang>,a:A>b,b:B>,c:C>,gin:Gin>local-go" {
+		t.Errorf("tags = %v", got)
+	}
+	var uids []string
+	if err := db.NewSelect().Model((*model.ResourceTags)(nil)).Column("tag_uid").Order("tag_uid ASC").Scan(ctx, &uids); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(uids, ",") != "a,local-go" {
+		t.Errorf("resource tags = %v, want [a local-go]", uids)
+	}
+}
+
+func newTestSvc(t *testing.T) *svc.ServiceContext {
+	db := &model.DB{Writer: newTestDB(t)}
+	db.Reader = db.Writer
+	return &svc.ServiceContext{
+		DB:                    db,
+		TagsModel:             model.NewTagsModel(db),
+		TagAliasesModel:       model.NewTagAliasesModel(db),
+		TagRulesModel:         model.NewTagRulesModel(db),
+		ResourceModel:         model.NewResourceModel(db),
+		ResourceTagsModel:     model.NewResourceTagsModel(db),
+		ResourceKeywordsModel: model.NewResourceKeywordsModel(db),
+		ModelsModel:           model.NewModelsModel(db),
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	source := newTestSvc(t)
+	if err := source.TagsModel.CreateBatch(ctx, []model.Tags{{Uid: "go", Name: "Go"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.TagAliasesModel.CreateBatch(ctx, []*model.TagAliases{{Alias: "Golang", TagUid: "go"}}); err != nil {
+		t.Fatal(err)
+	}
+	rule := &model.TagRules{Uid: "r1", Name: "go.dev", Type: model.TagRuleTypeDomain, Pattern: "go.dev", Action: model.TagRuleActionAdd, TagUid: "go", Priority: 1}
+	if err := source.TagRulesModel.Create(ctx, rule); err != nil {
+		t.Fatal(err)
+	}
+	resource := &model.Resource{URL: "https://go.dev", Title: "Go"}
+	if err := source.ResourceModel.Create(ctx, resource); err != nil {
+		t.Fatal(err)
+	}
+	keywords := []*model.ResourceKeywords{{Keyword: "goroutine", Type: model.KeywordTypeKeyword, Weight: 0.8}}
+	if err := source.ResourceKeywordsModel.SetKeywords(ctx, resource.ID, keywords); err != nil {
+		t.Fatal(err)
+	}
+	exported, err := Export(ctx, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exported.Tags[0].Aliases) != 1 || len(exported.Rules) != 1 || len(exported.Resources[0].Keywords) != 1 {
+		t.Fatalf("Export() = %+v, want alias, rule and keyword", exported)
+	}
+
+	target := newTestSvc(t)
+	result, err := Import(ctx, target, exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AliasesCreated != 1 || result.RulesCreated != 1 {
+		t.Errorf("Import() = %+v, want 1 alias and 1 rule", *result)
+	}
+	// 重复导入时别名和规则不会重复创建
+	result, err = Import(ctx, target, exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AliasesCreated != 0 || result.RulesUpdated != 1 {
+		t.Errorf("second Import() = %+v, want 0 aliases and 1 updated rule", *result)
+	}
+	imported, err := Export(ctx, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(imported.Tags, exported.Tags) {
+		t.Errorf("tags = %+v, want %+v", imported.Tags, exported.Tags)
+	}
+	if !reflect.DeepEqual(imported.Rules, exported.Rules) {
+		t.Errorf("rules = %+v, want %+v", imported.Rules, exported.Rules)
+	}
+	if !reflect.DeepEqual(imported.Resources[0].Keywords, exported.Resources[0].Keywords) {
+		t.Errorf("keywords = %+v, want %+v", imported.Resources[0].Keywords, exported.Resources[0].Keywords)
+	}
+}
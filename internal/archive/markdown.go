@@ -0,0 +1,137 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArchiveEntry Markdown 压缩包中保存完整归档的文件, 用于重新导入
+const ArchiveEntry = "wise.json"
+
+// 文件名中不允许出现的字符, 兼容 Windows 和 Obsidian 链接语法
+var filenameReplacer = strings.NewReplacer(
+	"/", "-", "\\", "-", ":", "-", "*", "-", "?", "-", "\"", "-",
+	"<", "-", ">", "-", "|", "-", "#", "-", "^", "-", "[", "(", "]", ")",
+)
+
+// WriteMarkdown 写出 zip 压缩包, 每个资源一个 Markdown 文件, 标签写入 front-matter, 可直接作为 Obsidian 库打开
+func WriteMarkdown(w io.Writer, archive *Archive) error {
+	zw := zip.NewWriter(w)
+	tagNames := make(map[string]string, len(archive.Tags))
+	for _, tag := range archive.Tags {
+		tagNames[tag.Uid] = tag.Name
+	}
+	used := make(map[string]int)
+	for _, resource := range archive.Resources {
+		name := markdownFilename(resource, used)
+		f, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: resource.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(renderMarkdown(resource, tagNames)); err != nil {
+			return err
+		}
+	}
+	f, err := zw.Create(ArchiveEntry)
+	if err != nil {
+		return err
+	}
+	if err := WriteJson(f, archive); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// ReadZip 读取 Markdown 压缩包中的归档
+func ReadZip(r io.ReaderAt, size int64) (*Archive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	f, err := zr.Open(ArchiveEntry)
+	if err != nil {
+		return nil, fmt.Errorf("压缩包中缺少 %s: %w", ArchiveEntry, err)
+	}
+	defer f.Close()
+	return ReadJson(f)
+}
+
+// markdownFilename 按标题生成文件名, 重名时追加序号
+func markdownFilename(resource Resource, used map[string]int) string {
+	name := strings.TrimSpace(filenameReplacer.Replace(resource.Title))
+	if name == "" {
+		if u, err := url.Parse(resource.URL); err == nil && u.Host != "" {
+			name = u.Host
+		} else {
+			name = "untitled"
+		}
+	}
+	if runes := []rune(name); len(runes) > 80 {
+		name = string(runes[:80])
+	}
+	used[name]++
+	if used[name] > 1 {
+		name += " " + strconv.Itoa(used[name])
+	}
+	return name + ".md"
+}
+
+// renderMarkdown 生成带 front-matter 的 Markdown
+func renderMarkdown(resource Resource, tagNames map[string]string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	fmt.Fprintf(&buf, "title: %s\n", strconv.Quote(resource.Title))
+	fmt.Fprintf(&buf, "url: %s\n", strconv.Quote(resource.URL))
+	if resource.Type != "" {
+		fmt.Fprintf(&buf, "type: %s\n", resource.Type)
+	}
+	tags := make([]string, 0, len(resource.Tags))
+	for _, uid := range resource.Tags {
+		// 未找到的标签保留原值, 兼容历史数据中直接保存的标签名称
+		name := uid
+		if n, ok := tagNames[uid]; ok {
+			name = n
+		}
+		if tag := obsidianTag(name); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		buf.WriteString("tags:\n")
+		for _, tag := range tags {
+			fmt.Fprintf(&buf, "  - %s\n", strconv.Quote(tag))
+		}
+	}
+	if !resource.CreatedAt.IsZero() {
+		fmt.Fprintf(&buf, "created: %s\n", resource.CreatedAt.Format(time.RFC3339))
+	}
+	buf.WriteString("---\n\n")
+	fmt.Fprintf(&buf, "# %s\n\n", resource.Title)
+	if resource.Summary != "" {
+		for _, line := range strings.Split(resource.Summary, "\n") {
+			fmt.Fprintf(&buf, "> %s\n", line)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(resource.Content)
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+// obsidianTag Obsidian 标签不能包含空白和 #, 替换为 -
+func obsidianTag(name string) string {
+	name = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '#' || r == ','
+	}), "-")
+}
@@ -0,0 +1,344 @@
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/tagname"
+)
+
+// ImportResult 导入结果统计
+type ImportResult struct {
+	TagsCreated         int64
+	TagsUpdated         int64
+	ParentsSkipped      int64 // 父标签不存在或形成环而未设置的标签数
+	AliasesCreated      int64 // 新建别名数, 与已有别名重复的跳过
+	ResourcesCreated    int64
+	ResourcesUpdated    int64
+	ResourceTagsSkipped int64 // 标签不存在而跳过的资源标签数
+	RulesCreated        int64
+	RulesUpdated        int64
+	RulesSkipped        int64 // 标签不存在而跳过的规则数
+	ModelsCreated       int64
+	ModelsSkipped       int64
+}
+
+// Import 恢复归档, 标签按唯一标识、名称, 资源按链接, 规则按唯一标识匹配, 已存在的更新, 重复导入结果一致
+// 父标签在全部标签写入后设置, 不存在或形成环时跳过; 资源和规则关联的标签不存在时跳过
+// 别名只添加不删除, 与已有别名重复时保留本地别名; 版本 1 的归档不含关键词, 不替换资源的关键词
+// 模型已存在时跳过, 不覆盖本地密钥; 新建的模型缺少密钥时置为未激活
+// 全部写入在一个事务中, 任一条失败时不导入任何数据
+func Import(ctx context.Context, svcCtx *svc.ServiceContext, archive *Archive) (*ImportResult, error) {
+	result := &ImportResult{}
+	err := svcCtx.DB.Writer.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return importArchive(ctx, tx, archive, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func importArchive(ctx context.Context, tx bun.Tx, archive *Archive, result *ImportResult) error {
+	// 归档中的标签唯一标识对应的本地标签唯一标识
+	uids := make(map[string]string, len(archive.Tags))
+	for _, tag := range archive.Tags {
+		if tag.Uid == "" {
+			continue
+		}
+		uid, created, err := importTag(ctx, tx, tag)
+		if err != nil {
+			logx.Errorf("Import tag uid: %s, error: %v", tag.Uid, err)
+			return err
+		}
+		uids[tag.Uid] = uid
+		if created {
+			result.TagsCreated++
+		} else {
+			result.TagsUpdated++
+		}
+		aliases, err := importAliases(ctx, tx, uid, tag.Aliases)
+		if err != nil {
+			logx.Errorf("Import tag aliases uid: %s, error: %v", tag.Uid, err)
+			return err
+		}
+		result.AliasesCreated += aliases
+	}
+	parents, err := loadParents(ctx, tx)
+	if err != nil {
+		logx.Errorf("Import load tags error: %v", err)
+		return err
+	}
+	localUid := func(uid string) string {
+		if local, ok := uids[uid]; ok {
+			return local
+		}
+		if _, ok := parents[uid]; ok {
+			return uid
+		}
+		return ""
+	}
+	for _, tag := range archive.Tags {
+		if tag.Uid == "" {
+			continue
+		}
+		set, err := importParent(ctx, tx, parents, uids[tag.Uid], tag.ParentUid, localUid)
+		if err != nil {
+			logx.Errorf("Import tag parent uid: %s, parentUid: %s, error: %v", tag.Uid, tag.ParentUid, err)
+			return err
+		}
+		if !set {
+			result.ParentsSkipped++
+		}
+	}
+	for _, resource := range archive.Resources {
+		if resource.URL == "" {
+			continue
+		}
+		tags := make([]string, 0, len(resource.Tags))
+		for _, uid := range resource.Tags {
+			if local := localUid(uid); local != "" {
+				tags = append(tags, local)
+			} else {
+				result.ResourceTagsSkipped++
+			}
+		}
+		resource.Tags = tags
+		created, err := importResource(ctx, tx, resource, archive.Version >= 2)
+		if err != nil {
+			logx.Errorf("Import resource url: %s, error: %v", resource.URL, err)
+			return err
+		}
+		if created {
+			result.ResourcesCreated++
+		} else {
+			result.ResourcesUpdated++
+		}
+	}
+	for _, rule := range archive.Rules {
+		if rule.Uid == "" {
+			continue
+		}
+		if rule.TagUid = localUid(rule.TagUid); rule.TagUid == "" {
+			result.RulesSkipped++
+			continue
+		}
+		created, err := importRule(ctx, tx, rule)
+		if err != nil {
+			logx.Errorf("Import rule uid: %s, error: %v", rule.Uid, err)
+			return err
+		}
+		if created {
+			result.RulesCreated++
+		} else {
+			result.RulesUpdated++
+		}
+	}
+	for _, m := range archive.Models {
+		exists, err := tx.NewSelect().Model((*model.Models)(nil)).
+			Where("base_url = ?", m.BaseUrl).
+			Where("model_name = ?", m.ModelName).
+			Where("model_real_name = ?", m.ModelRealName).
+			Exists(ctx)
+		if err != nil {
+			logx.Errorf("Import model name: %s, error: %v", m.ModelName, err)
+			return err
+		}
+		if exists {
+			result.ModelsSkipped++
+			continue
+		}
+		config, redacted := RedactConfig(m.Config)
+		status := m.Status
+		if redacted || config == "{}" {
+			status = "inactive"
+		}
+		_, err = tx.NewInsert().Model(&model.Models{
+			BaseUrl:       m.BaseUrl,
+			Config:        config,
+			Type:          m.Type,
+			ModelName:     m.ModelName,
+			ModelRealName: m.ModelRealName,
+			Status:        status,
+			Tag:           m.Tag,
+		}).Exec(ctx)
+		if err != nil {
+			logx.Errorf("Import model name: %s, error: %v", m.ModelName, err)
+			return err
+		}
+		result.ModelsCreated++
+	}
+	return nil
+}
+
+// importTag 按唯一标识匹配标签, 未匹配时按规范化的名称匹配, 避免重复创建, 返回本地标签唯一标识
+// 父标签在全部标签写入后由 importParent 设置
+func importTag(ctx context.Context, tx bun.Tx, tag Tag) (string, bool, error) {
+	existing := new(model.Tags)
+	err := tx.NewSelect().Model(existing).Where("uid = ?", tag.Uid).Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = tx.NewSelect().Model(existing).Where("name_key = ?", tagname.Key(tag.Name)).Order("id ASC").Limit(1).Scan(ctx)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err := tx.NewInsert().Model(&model.Tags{
+			Uid:         tag.Uid,
+			Name:        tag.Name,
+			Description: tag.Description,
+			Color:       tag.Color,
+			Icon:        tag.Icon,
+		}).Exec(ctx)
+		return tag.Uid, true, err
+	}
+	if err != nil {
+		return "", false, err
+	}
+	existing.Name = tag.Name
+	existing.Description = tag.Description
+	existing.Color = tag.Color
+	existing.Icon = tag.Icon
+	_, err = tx.NewUpdate().Model(existing).WherePK().Exec(ctx)
+	return existing.Uid, false, err
+}
+
+// importAliases 为标签添加别名, 规范化后与已有别名相同的跳过, 返回新建的别名数
+func importAliases(ctx context.Context, tx bun.Tx, uid string, names []string) (int64, error) {
+	aliases := make([]*model.TagAliases, 0, len(names))
+	for _, name := range names {
+		if tagname.Key(name) != "" {
+			aliases = append(aliases, &model.TagAliases{Alias: name, TagUid: uid})
+		}
+	}
+	if len(aliases) == 0 {
+		return 0, nil
+	}
+	res, err := tx.NewInsert().Model(&aliases).On("CONFLICT DO NOTHING").Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// loadParents 读取全部标签的父标签
+func loadParents(ctx context.Context, tx bun.Tx) (map[string]string, error) {
+	var tags []*model.Tags
+	if err := tx.NewSelect().Model(&tags).Column("uid", "parent_uid").Scan(ctx); err != nil {
+		return nil, err
+	}
+	parents := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		parents[tag.Uid] = tag.ParentUid
+	}
+	return parents, nil
+}
+
+// importParent 设置标签的父标签, 与 TagsModel.SetParent 的校验一致
+// 父标签不存在或是标签自身及其子孙标签时保留原有父标签, 返回 false
+func importParent(ctx context.Context, tx bun.Tx, parents map[string]string, uid, parentUid string, localUid func(string) string) (bool, error) {
+	if parentUid != "" {
+		parentUid = localUid(parentUid)
+		if parentUid == "" {
+			return false, nil
+		}
+		seen := make(map[string]bool)
+		for ancestor := parentUid; ancestor != "" && !seen[ancestor]; ancestor = parents[ancestor] {
+			if ancestor == uid {
+				return false, nil
+			}
+			seen[ancestor] = true
+		}
+	}
+	if parents[uid] == parentUid {
+		return true, nil
+	}
+	_, err := tx.NewUpdate().Model((*model.Tags)(nil)).
+		Set("parent_uid = ?", parentUid).
+		Where("uid = ?", uid).
+		Exec(ctx)
+	parents[uid] = parentUid
+	return true, err
+}
+
+// importResource 按链接匹配资源, replaceKeywords 为 true 时替换资源的关键词
+func importResource(ctx context.Context, tx bun.Tx, resource Resource, replaceKeywords bool) (bool, error) {
+	existing := new(model.Resource)
+	err := tx.NewSelect().Model(existing).Where("url = ?", resource.URL).Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		created := &model.Resource{
+			URL:       resource.URL,
+			Title:     resource.Title,
+			Describe:  resource.Summary,
+			Content:   resource.Content,
+			Type:      resource.Type,
+			CreatedAt: resource.CreatedAt,
+			UpdatedAt: resource.UpdatedAt,
+		}
+		if _, err := tx.NewInsert().Model(created).Exec(ctx); err != nil {
+			return true, err
+		}
+		return true, importResourceLinks(ctx, tx, created.ID, resource, replaceKeywords)
+	}
+	if err != nil {
+		return false, err
+	}
+	existing.Title = resource.Title
+	existing.Describe = resource.Summary
+	existing.Content = resource.Content
+	existing.Type = resource.Type
+	if !resource.CreatedAt.IsZero() {
+		existing.CreatedAt = resource.CreatedAt
+	}
+	if _, err := tx.NewUpdate().Model(existing).WherePK().Exec(ctx); err != nil {
+		return false, err
+	}
+	return false, importResourceLinks(ctx, tx, existing.ID, resource, replaceKeywords)
+}
+
+// importResourceLinks 替换资源的标签和关键词
+func importResourceLinks(ctx context.Context, tx bun.Tx, resourceID int64, resource Resource, replaceKeywords bool) error {
+	if err := model.ReplaceResourceTags(ctx, tx, resourceID, resource.Tags); err != nil {
+		return err
+	}
+	if !replaceKeywords {
+		return nil
+	}
+	keywords := make([]*model.ResourceKeywords, 0, len(resource.Keywords))
+	for _, keyword := range resource.Keywords {
+		keywords = append(keywords, &model.ResourceKeywords{
+			Keyword: keyword.Keyword,
+			Type:    keyword.Type,
+			Weight:  keyword.Weight,
+		})
+	}
+	return model.ReplaceResourceKeywords(ctx, tx, resourceID, keywords)
+}
+
+// importRule 按唯一标识匹配规则, 规则的标签已转换为本地标签唯一标识
+func importRule(ctx context.Context, tx bun.Tx, rule Rule) (bool, error) {
+	existing := new(model.TagRules)
+	err := tx.NewSelect().Model(existing).Where("uid = ?", rule.Uid).Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		existing = &model.TagRules{Uid: rule.Uid}
+	} else if err != nil {
+		return false, err
+	}
+	existing.Name = rule.Name
+	existing.Type = rule.Type
+	existing.Field = rule.Field
+	existing.Pattern = rule.Pattern
+	existing.Action = rule.Action
+	existing.TagUid = rule.TagUid
+	existing.Priority = rule.Priority
+	existing.Enabled = rule.Enabled
+	if existing.ID == 0 {
+		_, err = tx.NewInsert().Model(existing).Exec(ctx)
+		return true, err
+	}
+	_, err = tx.NewUpdate().Model(existing).WherePK().Exec(ctx)
+	return false, err
+}
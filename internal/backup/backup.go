@@ -0,0 +1,525 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/model/migrations"
+)
+
+const (
+	snapshotPrefix = "wise-"
+	snapshotExt    = ".db"
+	checksumExt    = ".sha256"
+
+	// 恢复前等待执行中的任务结束的时间
+	pauseTimeout = time.Minute
+	// 恢复期间暂停所有实例调度的时长, 恢复结束时解除, 进程异常退出时到期自动解除
+	pauseTTL = 10 * time.Minute
+	// 等待其他进程中执行的任务结束时, 检查的间隔
+	runningPoll = time.Second
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+	ErrChecksumMismatch = errors.New("snapshot checksum mismatch")
+	ErrUnsupported      = errors.New("backup only supports sqlite")
+	ErrSchemaMismatch   = errors.New("snapshot migrations differ from current database")
+)
+
+// 迁移记录表描述的是当前库的结构, 恢复时保留当前库的记录
+// 数据版本在恢复写入时由触发器递增, 不能回退到快照中的版本, 否则会命中恢复前的缓存
+// 调度暂停属于正在运行的实例, 恢复期间需要保持
+var skipTables = map[string]bool{
+	migrations.TableName:      true,
+	migrations.LocksTableName: true,
+	model.DataVersionsTable:   true,
+	model.TaskPausesTable:     true,
+}
+
+// Scheduler 恢复期间需要暂停的任务调度器
+type Scheduler interface {
+	Pause(timeout time.Duration) error
+	Resume()
+}
+
+// Snapshot 数据库快照
+type Snapshot struct {
+	Name      string    // 文件名
+	Size      int64     // 文件大小
+	Checksum  string    // sha256
+	CreatedAt time.Time // 创建时间
+}
+
+// Manager 管理数据库在线备份, 使用 VACUUM INTO 生成一致的快照, 不阻塞读写
+type Manager struct {
+	c            config.BackupConfig
+	db           *bun.DB
+	pauses       *model.TaskPausesModel
+	pauseTimeout time.Duration
+	mu           sync.Mutex // 串行化备份和恢复
+	scheduler    Scheduler
+	stop         chan struct{}
+	stopOnce     sync.Once
+}
+
+func NewManager(c config.BackupConfig, db *bun.DB) *Manager {
+	return &Manager{
+		c:            c,
+		db:           db,
+		pauses:       model.NewTaskPausesModel(&model.DB{Writer: db, Reader: db}),
+		pauseTimeout: pauseTimeout,
+		stop:         make(chan struct{}),
+	}
+}
+
+// SetScheduler 设置恢复时需要暂停的本进程调度器, 其他进程中的 worker 通过数据库暂停
+func (m *Manager) SetScheduler(scheduler Scheduler) {
+	m.scheduler = scheduler
+}
+
+// Start 按配置的间隔定时备份, 间隔为 0 时不启动
+func (m *Manager) Start() {
+	if m.c.Interval <= 0 {
+		return
+	}
+	if !m.supported() {
+		logx.Infof("数据库为 %s, 不启动定时备份", m.db.Dialect().Name())
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(m.c.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := m.Create(context.Background()); err != nil {
+					logx.Errorf("定时备份失败: %v", err)
+				}
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定时备份
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// supported 快照依赖 SQLite 的 VACUUM INTO 和 ATTACH, PostgreSQL 使用 pg_dump 备份
+func (m *Manager) supported() bool {
+	return m.db.Dialect().Name() == dialect.SQLite
+}
+
+// Create 创建快照并按保留规则清理旧快照
+func (m *Manager) Create(ctx context.Context) (*Snapshot, error) {
+	if !m.supported() {
+		return nil, ErrUnsupported
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot, err := m.create(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	m.prune(snapshot.Name)
+	return snapshot, nil
+}
+
+func (m *Manager) create(ctx context.Context, suffix string) (*Snapshot, error) {
+	if err := os.MkdirAll(m.c.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	name := snapshotPrefix + now.Format("20060102-150405.000")
+	if suffix != "" {
+		name += "-" + suffix
+	}
+	name += snapshotExt
+	path := filepath.Join(m.c.Dir, name)
+	// VACUUM INTO 要求目标文件不存在, 写入临时文件完成后再重命名
+	tmp := path + ".tmp"
+	_ = os.Remove(tmp)
+	if _, err := m.db.ExecContext(ctx, "VACUUM INTO ?", tmp); err != nil {
+		_ = os.Remove(tmp)
+		logx.Errorf("backup VACUUM INTO path: %s, error: %v", tmp, err)
+		return nil, err
+	}
+	checksum, size, err := fileChecksum(tmp)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	// 与 sha256sum 输出格式一致, 可直接使用 sha256sum -c 校验
+	line := fmt.Sprintf("%s  %s\n", checksum, name)
+	if err := os.WriteFile(path+checksumExt, []byte(line), 0o644); err != nil {
+		return nil, err
+	}
+	logx.Infof("数据库备份完成: %s, size: %d", name, size)
+	return &Snapshot{Name: name, Size: size, Checksum: checksum, CreatedAt: now}, nil
+}
+
+// List 列出快照, 按创建时间倒序
+func (m *Manager) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(m.c.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]Snapshot, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !validName(name) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{
+			Name:      name,
+			Size:      info.Size(),
+			Checksum:  m.expectedChecksum(name),
+			CreatedAt: info.ModTime(),
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Name > snapshots[j].Name
+	})
+	return snapshots, nil
+}
+
+// Path 返回快照文件路径, 快照不存在时返回 ErrSnapshotNotFound
+func (m *Manager) Path(name string) (string, error) {
+	if !validName(name) {
+		return "", ErrSnapshotNotFound
+	}
+	path := filepath.Join(m.c.Dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", ErrSnapshotNotFound
+	}
+	return path, nil
+}
+
+// Verify 校验快照的 sha256 和数据库完整性
+func (m *Manager) Verify(ctx context.Context, name string) error {
+	path, err := m.Path(name)
+	if err != nil {
+		return err
+	}
+	expected := m.expectedChecksum(name)
+	checksum, _, err := fileChecksum(path)
+	if err != nil {
+		return err
+	}
+	if expected == "" || expected != checksum {
+		return ErrChecksumMismatch
+	}
+	var result string
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS verify", path); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "DETACH DATABASE verify")
+	if err := conn.QueryRowContext(ctx, "PRAGMA verify.integrity_check").Scan(&result); err != nil {
+		return err
+	}
+	if result != "ok" {
+		return fmt.Errorf("snapshot integrity check failed: %s", result)
+	}
+	return nil
+}
+
+// Restore 将数据库恢复到快照, 恢复前暂停调度器并自动备份当前数据
+// 恢复在同一个连接的事务内逐表替换数据, 失败时回滚, 不需要重启服务
+func (m *Manager) Restore(ctx context.Context, name string) (*Snapshot, error) {
+	if !m.supported() {
+		return nil, ErrUnsupported
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.Verify(ctx, name); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(m.c.Dir, name)
+	if err := m.checkMigrations(ctx, path); err != nil {
+		return nil, err
+	}
+	// 所有实例停止领取任务, 领取时在同一条语句中检查暂停, 暂停后不会再有任务开始执行
+	if err := m.pauses.Pause(ctx, model.TaskPauseRestore, time.Now().Add(pauseTTL)); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = m.pauses.Resume(context.Background(), model.TaskPauseRestore)
+	}()
+	if m.scheduler != nil {
+		if err := m.scheduler.Pause(m.pauseTimeout); err != nil {
+			return nil, err
+		}
+		defer m.scheduler.Resume()
+	}
+	if err := m.waitRunning(ctx); err != nil {
+		return nil, err
+	}
+	current, err := m.create(ctx, "pre-restore")
+	if err != nil {
+		return nil, err
+	}
+	if err := m.restore(ctx, path); err != nil {
+		logx.Errorf("Restore name: %s, error: %v", name, err)
+		return nil, err
+	}
+	logx.Infof("数据库已恢复到快照: %s, 恢复前的数据已备份为: %s", name, current.Name)
+	return current, nil
+}
+
+// waitRunning 等待其他进程中执行的任务结束, 超时返回错误
+func (m *Manager) waitRunning(ctx context.Context) error {
+	deadline := time.Now().Add(m.pauseTimeout)
+	for {
+		running, err := m.db.NewSelect().Model((*model.Tasks)(nil)).
+			Where("status = ?", model.TaskStatusRunning).
+			Count(ctx)
+		if err != nil {
+			return err
+		}
+		if running == 0 {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("有 %d 个任务正在执行, 等待超时, 请稍后再恢复", running)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(runningPoll):
+		}
+	}
+}
+
+// checkMigrations 快照和当前库执行过的迁移必须一致
+// 表结构不同时按字段复制会丢失数据, 如旧版本 resources.tags 中的标签, 需要先升级快照所在的版本再备份
+func (m *Manager) checkMigrations(ctx context.Context, path string) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS snapshot", path); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "DETACH DATABASE snapshot")
+	current, err := appliedMigrations(ctx, conn, "main")
+	if err != nil {
+		return err
+	}
+	snapshot, err := appliedMigrations(ctx, conn, "snapshot")
+	if err != nil {
+		return err
+	}
+	if strings.Join(current, ",") != strings.Join(snapshot, ",") {
+		return fmt.Errorf("%w: snapshot %v, current %v", ErrSchemaMismatch, snapshot, current)
+	}
+	return nil
+}
+
+func (m *Manager) restore(ctx context.Context, path string) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS snapshot", path); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "DETACH DATABASE snapshot")
+
+	tables, err := tableNames(ctx, conn, "main")
+	if err != nil {
+		return err
+	}
+	snapshotTables, err := tableNames(ctx, conn, "snapshot")
+	if err != nil {
+		return err
+	}
+	exists := make(map[string]bool, len(snapshotTables))
+	for _, table := range snapshotTables {
+		exists[table] = true
+	}
+
+	return conn.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, table := range tables {
+			if skipTables[table] {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM main.%q", table)); err != nil {
+				return err
+			}
+			// 迁移一致时表相同, 快照中没有的表只可能是手动创建的, 清空即可
+			if !exists[table] {
+				continue
+			}
+			columns, err := commonColumns(ctx, tx, table)
+			if err != nil {
+				return err
+			}
+			if len(columns) == 0 {
+				continue
+			}
+			list := strings.Join(columns, ", ")
+			query := fmt.Sprintf("INSERT INTO main.%q (%s) SELECT %s FROM snapshot.%q", table, list, list, table)
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// prune 按数量和时长清理旧快照, 保留刚创建的快照
+func (m *Manager) prune(keep string) {
+	snapshots, err := m.List()
+	if err != nil {
+		logx.Errorf("prune backups error: %v", err)
+		return
+	}
+	now := time.Now()
+	for i, snapshot := range snapshots {
+		if snapshot.Name == keep {
+			continue
+		}
+		expired := m.c.MaxAge > 0 && now.Sub(snapshot.CreatedAt) > m.c.MaxAge
+		overflow := m.c.Keep > 0 && i >= m.c.Keep
+		if !expired && !overflow {
+			continue
+		}
+		path := filepath.Join(m.c.Dir, snapshot.Name)
+		if err := os.Remove(path); err != nil {
+			logx.Errorf("prune backup name: %s, error: %v", snapshot.Name, err)
+			continue
+		}
+		_ = os.Remove(path + checksumExt)
+		logx.Infof("清理过期备份: %s", snapshot.Name)
+	}
+}
+
+// expectedChecksum 读取快照记录的 sha256, 不存在时返回空
+func (m *Manager) expectedChecksum(name string) string {
+	data, err := os.ReadFile(filepath.Join(m.c.Dir, name+checksumExt))
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// validName 只允许访问备份目录下由本程序生成的快照
+func validName(name string) bool {
+	return strings.HasPrefix(name, snapshotPrefix) && strings.HasSuffix(name, snapshotExt) &&
+		filepath.Base(name) == name
+}
+
+func fileChecksum(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+func tableNames(ctx context.Context, conn bun.Conn, schema string) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT name FROM %s.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%%'", schema))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanStrings(rows)
+}
+
+// appliedMigrations 返回已执行的迁移名称, 没有迁移记录表的库视为未执行任何迁移
+func appliedMigrations(ctx context.Context, conn bun.Conn, schema string) ([]string, error) {
+	var count int
+	err := conn.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT COUNT(*) FROM %s.sqlite_master WHERE type = 'table' AND name = ?", schema),
+		migrations.TableName).Scan(&count)
+	if err != nil || count == 0 {
+		return []string{}, err
+	}
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT name FROM %s.%q ORDER BY name", schema, migrations.TableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanStrings(rows)
+}
+
+// commonColumns 当前库和快照都有的字段, 兼容快照之后新增的字段
+func commonColumns(ctx context.Context, tx bun.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT m.name FROM pragma_table_info(?, 'main') m JOIN pragma_table_info(?, 'snapshot') s ON m.name = s.name ORDER BY m.cid",
+		table, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := scanStrings(rows)
+	if err != nil {
+		return nil, err
+	}
+	for i, column := range columns {
+		columns[i] = fmt.Sprintf("%q", column)
+	}
+	return columns, nil
+}
+
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	result := make([]string, 0)
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,252 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+
+	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/model/migrations"
+)
+
+type fakeScheduler struct {
+	paused  int
+	resumed int
+}
+
+func (s *fakeScheduler) Pause(time.Duration) error {
+	s.paused++
+	return nil
+}
+
+func (s *fakeScheduler) Resume() {
+	s.resumed++
+}
+
+func newTestManager(t *testing.T, keep int) (*Manager, *bun.DB) {
+	dir := t.TempDir()
+	sqldb, err := sql.Open(sqliteshim.ShimName, filepath.Join(dir, "wise.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { _ = db.Close() })
+	if _, err := migrations.Up(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return NewManager(config.BackupConfig{Dir: filepath.Join(dir, "backups"), Keep: keep}, db), db
+}
+
+func countTags(t *testing.T, db *bun.DB) int {
+	count, err := db.NewSelect().Model((*model.Tags)(nil)).Count(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	m, db := newTestManager(t, 0)
+	scheduler := &fakeScheduler{}
+	m.SetScheduler(scheduler)
+	tags := model.NewTagsModel(&model.DB{Writer: db, Reader: db})
+	if err := tags.Create(ctx, &model.Tags{Uid: "a", Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := m.Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tags.Create(ctx, &model.Tags{Uid: "b", Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := m.Restore(ctx, snapshot.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := countTags(t, db); got != 1 {
+		t.Errorf("tags after restore = %d, want 1", got)
+	}
+	if group, err := migrations.Up(ctx, db); err != nil || !group.IsZero() {
+		t.Errorf("migrations after restore = %v, %v, want none", group, err)
+	}
+	if scheduler.paused != 1 || scheduler.resumed != 1 {
+		t.Errorf("scheduler paused %d resumed %d, want 1 1", scheduler.paused, scheduler.resumed)
+	}
+
+	// 恢复前的数据已自动备份, 可以回退
+	if _, err := m.Restore(ctx, current.Name); err != nil {
+		t.Fatal(err)
+	}
+	if got := countTags(t, db); got != 2 {
+		t.Errorf("tags after undo = %d, want 2", got)
+	}
+}
+
+func TestRestoreRejectsTamperedSnapshot(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t, 0)
+	snapshot, err := m.Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, _ := m.Path(snapshot.Name)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("tampered")
+	_ = f.Close()
+	if _, err := m.Restore(ctx, snapshot.Name); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Restore() error = %v, want %v", err, ErrChecksumMismatch)
+	}
+	if _, err := m.Restore(ctx, "../wise.db"); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("Restore() error = %v, want %v", err, ErrSnapshotNotFound)
+	}
+}
+
+func TestRestoreRejectsOldSchemaSnapshot(t *testing.T) {
+	ctx := context.Background()
+	m, db := newTestManager(t, 0)
+	tags := model.NewTagsModel(&model.DB{Writer: db, Reader: db})
+	if err := tags.Create(ctx, &model.Tags{Uid: "a", Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := m.Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 将快照改为 0005 迁移之前的版本, 标签仍在 resources.tags 中
+	path, _ := m.Path(snapshot.Name)
+	old, err := sql.Open(sqliteshim.ShimName, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range []string{
+		"DELETE FROM schema_migrations WHERE name >= '0005'",
+		"ALTER TABLE resources ADD COLUMN tags TEXT NOT NULL DEFAULT ''",
+	} {
+		if _, err := old.ExecContext(ctx, query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = old.Close()
+	checksum, _, err := fileChecksum(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+checksumExt, []byte(checksum+"  "+snapshot.Name+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tags.Create(ctx, &model.Tags{Uid: "b", Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Restore(ctx, snapshot.Name); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("Restore() error = %v, want %v", err, ErrSchemaMismatch)
+	}
+	if got := countTags(t, db); got != 2 {
+		t.Errorf("tags after rejected restore = %d, want 2", got)
+	}
+	// 迁移记录不受影响, 再次启动不会重复执行迁移
+	group, err := migrations.Up(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !group.IsZero() {
+		t.Errorf("migrations after rejected restore = %v, want none", group.Migrations)
+	}
+}
+
+func TestRestorePausesWorkers(t *testing.T) {
+	ctx := context.Background()
+	m, db := newTestManager(t, 0)
+	m.pauseTimeout = 5 * time.Second
+	snapshot, err := m.Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks := model.NewTasksModel(&model.DB{Writer: db, Reader: db})
+	pauses := model.NewTaskPausesModel(&model.DB{Writer: db, Reader: db})
+	for _, tid := range []string{"running", "init"} {
+		task := &model.Tasks{Tid: tid, Status: model.TaskStatusInit, Params: "{}", Result: "{}", Extend: "{}"}
+		if err := tasks.Create(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 其他进程中的 worker 正在执行任务
+	if claimed, err := tasks.Claim(ctx, "running", "worker", time.Now().Add(time.Minute), time.Now()); err != nil || !claimed {
+		t.Fatalf("Claim = %v, %v, want true", claimed, err)
+	}
+
+	// 恢复等待期间其他实例不能领取任务, 执行中的任务结束后继续恢复
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		time.Sleep(200 * time.Millisecond)
+		if claimed, err := tasks.Claim(ctx, "init", "worker", time.Now().Add(time.Minute), time.Now()); err != nil || claimed {
+			t.Errorf("Claim during restore = %v, %v, want false", claimed, err)
+		}
+		if _, err := tasks.UpdateStatus(ctx, "running", "worker", model.TaskStatusSuccess, "{}"); err != nil {
+			t.Error(err)
+		}
+	}()
+	if _, err := m.Restore(ctx, snapshot.Name); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if paused, err := pauses.Paused(ctx, time.Now()); err != nil || paused {
+		t.Errorf("Paused after restore = %v, %v, want false", paused, err)
+	}
+
+	// 任务一直未结束时放弃恢复并解除暂停
+	task := &model.Tasks{Tid: "stuck", Status: model.TaskStatusInit, Params: "{}", Result: "{}", Extend: "{}"}
+	if err := tasks.Create(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+	if claimed, err := tasks.Claim(ctx, "stuck", "worker", time.Now().Add(time.Minute), time.Now()); err != nil || !claimed {
+		t.Fatalf("Claim = %v, %v, want true", claimed, err)
+	}
+	m.pauseTimeout = 0
+	if _, err := m.Restore(ctx, snapshot.Name); err == nil {
+		t.Error("Restore() with a running task should fail")
+	}
+	if paused, err := pauses.Paused(ctx, time.Now()); err != nil || paused {
+		t.Errorf("Paused after failed restore = %v, %v, want false", paused, err)
+	}
+}
+
+func TestBackupRetention(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t, 2)
+	for i := 0; i < 4; i++ {
+		if _, err := m.Create(ctx); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	snapshots, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("snapshots = %d, want 2", len(snapshots))
+	}
+	for _, snapshot := range snapshots {
+		if err := m.Verify(ctx, snapshot.Name); err != nil {
+			t.Errorf("Verify(%s) error = %v", snapshot.Name, err)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	TaskEventStatus    = "task_status" // 任务状态变更
+	TaskEventPlanStart = "plan_start"  // 节点开始
+	TaskEventPlanEnd   = "plan_end"    // 节点完成
+	TaskEventPlanError = "plan_error"  // 节点失败
+)
+
+// 每个订阅者的缓冲大小, 消费过慢时丢弃事件, 不阻塞任务执行
+const subscriberBuffer = 64
+
+// 等待发送到 Redis 的事件缓冲大小, Redis 过慢时同样丢弃
+const relayBuffer = 1024
+
+// TaskEvent 任务进度事件
+type TaskEvent struct {
+	Tid    string `json:"tid"`             // 任务唯一标识
+	Type   string `json:"type"`            // 事件类型
+	Status string `json:"status"`          // 任务或节点状态
+	Node   string `json:"node,omitempty"`  // 节点名称
+	Pid    string `json:"pid,omitempty"`   // 任务计划唯一标识
+	Step   int64  `json:"step"`            // 当前步骤
+	Error  string `json:"error,omitempty"` // 错误信息
+	Time   string `json:"time"`            // 事件时间
+}
+
+type subscriber struct {
+	tid string
+	ch  chan TaskEvent
+}
+
+// Bus 任务事件总线, 默认只在进程内分发
+// 经 Redis 转发时事件先发布到频道, 所有进程包括发布者都从频道接收后分发给本进程的订阅者
+type Bus struct {
+	mu   sync.RWMutex
+	seq  int64
+	subs map[int64]*subscriber
+
+	client    *redis.Client
+	pubsub    *redis.PubSub
+	channel   string
+	outbox    chan TaskEvent
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subs: make(map[int64]*subscriber),
+	}
+}
+
+// NewRedisBus 经 Redis 发布订阅在进程间转发事件, 独立 worker 中执行的任务进度同样推送给 API 进程的订阅者
+func NewRedisBus(client *redis.Client, channel string) (*Bus, error) {
+	pubsub := client.Subscribe(context.Background(), channel)
+	// 等待订阅确认, 之后发布的事件不会丢失
+	if _, err := pubsub.Receive(context.Background()); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+	b := NewBus()
+	b.client = client
+	b.pubsub = pubsub
+	b.channel = channel
+	b.outbox = make(chan TaskEvent, relayBuffer)
+	b.done = make(chan struct{})
+	go b.send()
+	go b.receive(pubsub.Channel())
+	return b, nil
+}
+
+// Publish 发布事件, 不会阻塞
+func (b *Bus) Publish(e TaskEvent) {
+	if b == nil {
+		return
+	}
+	if e.Time == "" {
+		e.Time = time.Now().Format(time.DateTime)
+	}
+	if b.outbox != nil {
+		select {
+		case b.outbox <- e:
+		default:
+			logx.Debugf("task event dropped, tid: %s, type: %s", e.Tid, e.Type)
+		}
+		return
+	}
+	b.dispatch(e)
+}
+
+// send 按发布顺序将事件发送到 Redis, 发送失败时只分发给本进程的订阅者
+func (b *Bus) send() {
+	for {
+		select {
+		case e := <-b.outbox:
+			data, err := json.Marshal(e)
+			if err == nil {
+				err = b.client.Publish(context.Background(), b.channel, data).Err()
+			}
+			if err != nil {
+				logx.Errorf("relay task event tid: %s, error: %v", e.Tid, err)
+				b.dispatch(e)
+			}
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// receive 接收所有进程发布的事件
+func (b *Bus) receive(messages <-chan *redis.Message) {
+	for msg := range messages {
+		var e TaskEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &e); err != nil {
+			logx.Errorf("decode task event error: %v", err)
+			continue
+		}
+		b.dispatch(e)
+	}
+}
+
+// Close 停止经 Redis 转发, 只在进程内分发时无需关闭
+func (b *Bus) Close() error {
+	if b == nil || b.client == nil {
+		return nil
+	}
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.done)
+		_ = b.pubsub.Close()
+		err = b.client.Close()
+	})
+	return err
+}
+
+// dispatch 分发给本进程的订阅者
+func (b *Bus) dispatch(e TaskEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subs {
+		if sub.tid != "" && sub.tid != e.Tid {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			logx.Debugf("task event dropped, tid: %s, type: %s", e.Tid, e.Type)
+		}
+	}
+}
+
+// Subscribe 订阅事件, tid 为空时订阅全部任务, 返回取消订阅函数
+func (b *Bus) Subscribe(tid string) (<-chan TaskEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	id := b.seq
+	sub := &subscriber{
+		tid: tid,
+		ch:  make(chan TaskEvent, subscriberBuffer),
+	}
+	b.subs[id] = sub
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestBus(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		publish []string
+		want    int
+	}{
+		{name: "all", filter: "", publish: []string{"a", "b"}, want: 2},
+		{name: "by-tid", filter: "a", publish: []string{"a", "b", "a"}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewBus()
+			events, unsubscribe := bus.Subscribe(tt.filter)
+			for _, tid := range tt.publish {
+				bus.Publish(TaskEvent{Tid: tid, Type: TaskEventStatus})
+			}
+			unsubscribe()
+			got := 0
+			for range events {
+				got++
+			}
+			if got != tt.want {
+				t.Errorf("received %d events, want %d", got, tt.want)
+			}
+			// 取消订阅后发布不应阻塞或 panic
+			bus.Publish(TaskEvent{Tid: "a"})
+		})
+	}
+}
+
+func TestRedisBus(t *testing.T) {
+	mr := miniredis.RunT(t)
+	newBus := func() *Bus {
+		bus, err := NewRedisBus(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "wise:task-events")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = bus.Close() })
+		return bus
+	}
+	// worker 进程发布, API 进程和 worker 进程的订阅者各收到一次
+	worker, api := newBus(), newBus()
+	apiEvents, apiUnsubscribe := api.Subscribe("a")
+	defer apiUnsubscribe()
+	workerEvents, workerUnsubscribe := worker.Subscribe("")
+	defer workerUnsubscribe()
+	for _, tid := range []string{"a", "b", "a"} {
+		worker.Publish(TaskEvent{Tid: tid, Type: TaskEventStatus, Step: 1})
+	}
+
+	tests := []struct {
+		name   string
+		events <-chan TaskEvent
+		want   []string
+	}{
+		{name: "api", events: apiEvents, want: []string{"a", "a"}},
+		{name: "worker", events: workerEvents, want: []string{"a", "b", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range tt.want {
+				select {
+				case e := <-tt.events:
+					if e.Tid != want || e.Step != 1 || e.Time == "" {
+						t.Errorf("received %+v, want tid %s", e, want)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("timeout waiting for event %s", want)
+				}
+			}
+			select {
+			case e := <-tt.events:
+				t.Errorf("unexpected event %+v", e)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+
+	// 关闭后发布不应阻塞或 panic
+	_ = worker.Close()
+	worker.Publish(TaskEvent{Tid: "a"})
+}
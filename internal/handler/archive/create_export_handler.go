@@ -0,0 +1,27 @@
+package archive
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/XXueTu/wise/internal/logic/archive"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+	"github.com/XXueTu/wise/response"
+)
+
+func CreateExportHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CreateExportRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		l := archive.NewCreateExportLogic(r.Context(), svcCtx)
+		resp, err := l.CreateExport(&req)
+		response.Response(w, resp, err)
+
+	}
+}
@@ -0,0 +1,32 @@
+package archive
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/XXueTu/wise/internal/logic/archive"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+	"github.com/XXueTu/wise/response"
+)
+
+func GetExportHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GetExportRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		l := archive.NewGetExportLogic(r.Context(), svcCtx)
+		path, err := l.GetExport(&req)
+		if err != nil {
+			response.Response(w, nil, err)
+			return
+		}
+		w.Header().Set("Content-Disposition", "attachment; filename="+filepath.Base(path))
+		http.ServeFile(w, r, path)
+	}
+}
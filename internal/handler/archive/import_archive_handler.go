@@ -0,0 +1,34 @@
+package archive
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/XXueTu/wise/internal/logic/archive"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+	"github.com/XXueTu/wise/response"
+)
+
+func ImportArchiveHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ImportArchiveRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			httpx.Error(w, err)
+			return
+		}
+		defer file.Close()
+
+		l := archive.NewImportArchiveLogic(r.Context(), svcCtx)
+		resp, err := l.ImportArchive(&req, header.Filename, file)
+		response.Response(w, resp, err)
+
+	}
+}
@@ -0,0 +1,27 @@
+package backup
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/XXueTu/wise/internal/logic/backup"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+	"github.com/XXueTu/wise/response"
+)
+
+func CreateBackupHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CreateBackupRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		l := backup.NewCreateBackupLogic(r.Context(), svcCtx)
+		resp, err := l.CreateBackup(&req)
+		response.Response(w, resp, err)
+
+	}
+}
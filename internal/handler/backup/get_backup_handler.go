@@ -0,0 +1,32 @@
+package backup
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/XXueTu/wise/internal/logic/backup"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+	"github.com/XXueTu/wise/response"
+)
+
+func GetBackupHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GetBackupRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		l := backup.NewGetBackupLogic(r.Context(), svcCtx)
+		snapshot, path, err := l.GetBackup(&req)
+		if err != nil {
+			response.Response(w, nil, err)
+			return
+		}
+		w.Header().Set("Content-Disposition", "attachment; filename="+snapshot.Name)
+		w.Header().Set("X-Checksum-Sha256", snapshot.Checksum)
+		http.ServeFile(w, r, path)
+	}
+}
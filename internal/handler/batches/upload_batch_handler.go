@@ -0,0 +1,34 @@
+package batches
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/XXueTu/wise/internal/logic/batches"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+	"github.com/XXueTu/wise/response"
+)
+
+func UploadBatchHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.UploadBatchRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			httpx.Error(w, err)
+			return
+		}
+		defer file.Close()
+
+		l := batches.NewUploadBatchLogic(r.Context(), svcCtx)
+		resp, err := l.UploadBatch(&req, header.Filename, file)
+		response.Response(w, resp, err)
+
+	}
+}
@@ -5,10 +5,15 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	api "github.com/XXueTu/wise/internal/handler/api"
+	archive "github.com/XXueTu/wise/internal/handler/archive"
+	backup "github.com/XXueTu/wise/internal/handler/backup"
+	batches "github.com/XXueTu/wise/internal/handler/batches"
 	models "github.com/XXueTu/wise/internal/handler/models"
 	resources "github.com/XXueTu/wise/internal/handler/resources"
+	rules "github.com/XXueTu/wise/internal/handler/rules"
 	tags "github.com/XXueTu/wise/internal/handler/tags"
 	tasks "github.com/XXueTu/wise/internal/handler/tasks"
 	"github.com/XXueTu/wise/internal/svc"
@@ -17,6 +22,99 @@ import (
 )
 
 func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				// 创建知识库导出任务
+				Method:  http.MethodPost,
+				Path:    "/api/exports",
+				Handler: archive.CreateExportHandler(serverCtx),
+			},
+			{
+				// 下载导出文件
+				Method:  http.MethodGet,
+				Path:    "/api/exports/:tid",
+				Handler: archive.GetExportHandler(serverCtx),
+			},
+			{
+				// 导入知识库归档, 文件通过 file 字段上传, 支持 json 归档和 markdown 导出的压缩包
+				Method:  http.MethodPost,
+				Path:    "/api/imports",
+				Handler: archive.ImportArchiveHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/wise"),
+		rest.WithMaxBytes(134217728),
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				// 获取备份列表
+				Method:  http.MethodGet,
+				Path:    "/api/admin/backups",
+				Handler: backup.ListBackupHandler(serverCtx),
+			},
+			{
+				// 立即备份数据库
+				Method:  http.MethodPost,
+				Path:    "/api/admin/backups",
+				Handler: backup.CreateBackupHandler(serverCtx),
+			},
+			{
+				// 下载备份
+				Method:  http.MethodGet,
+				Path:    "/api/admin/backups/:name",
+				Handler: backup.GetBackupHandler(serverCtx),
+			},
+			{
+				// 恢复备份, 恢复期间暂停任务调度
+				Method:  http.MethodPost,
+				Path:    "/api/admin/backups/:name/restore",
+				Handler: backup.RestoreBackupHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/wise"),
+		rest.WithTimeout(300000*time.Millisecond),
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				// 获取批量导入列表
+				Method:  http.MethodGet,
+				Path:    "/api/batches",
+				Handler: batches.ListBatchHandler(serverCtx),
+			},
+			{
+				// 创建批量导入
+				Method:  http.MethodPost,
+				Path:    "/api/batches",
+				Handler: batches.CreateBatchHandler(serverCtx),
+			},
+			{
+				// 获取批量导入进度
+				Method:  http.MethodGet,
+				Path:    "/api/batches/:id",
+				Handler: batches.GetBatchHandler(serverCtx),
+			},
+			{
+				// 重试批次中失败的任务
+				Method:  http.MethodPost,
+				Path:    "/api/batches/:id/retry",
+				Handler: batches.RetryBatchHandler(serverCtx),
+			},
+			{
+				// 上传文件批量导入
+				Method:  http.MethodPost,
+				Path:    "/api/batches/upload",
+				Handler: batches.UploadBatchHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/wise"),
+		rest.WithMaxBytes(33554432),
+	)
+
 	server.AddRoutes(
 		[]rest.Route{
 			{
@@ -119,6 +217,48 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 		rest.WithPrefix("/wise"),
 	)
 
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				// 创建标签规则
+				Method:  http.MethodPost,
+				Path:    "/api/tag/rule",
+				Handler: rules.CreateTagRuleHandler(serverCtx),
+			},
+			{
+				// 更新标签规则
+				Method:  http.MethodPut,
+				Path:    "/api/tag/rule",
+				Handler: rules.UpdateTagRuleHandler(serverCtx),
+			},
+			{
+				// 删除标签规则
+				Method:  http.MethodDelete,
+				Path:    "/api/tag/rule",
+				Handler: rules.DeleteTagRuleHandler(serverCtx),
+			},
+			{
+				// 对全部资源执行标签规则
+				Method:  http.MethodPost,
+				Path:    "/api/tag/rule/apply",
+				Handler: rules.ApplyTagRuleHandler(serverCtx),
+			},
+			{
+				// 试运行标签规则
+				Method:  http.MethodPost,
+				Path:    "/api/tag/rule/dryrun",
+				Handler: rules.DryRunTagRuleHandler(serverCtx),
+			},
+			{
+				// 获取标签规则列表
+				Method:  http.MethodGet,
+				Path:    "/api/tag/rules",
+				Handler: rules.ListTagRuleHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/wise"),
+	)
+
 	server.AddRoutes(
 		[]rest.Route{
 			{
@@ -134,7 +274,7 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Handler: tags.UpdateTagHandler(serverCtx),
 			},
 			{
-				// 删除标签
+				// 删除标签, 默认标签和被规则引用的标签不能删除
 				Method:  http.MethodDelete,
 				Path:    "/api/tag",
 				Handler: tags.DeleteTagHandler(serverCtx),
@@ -157,6 +297,54 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/tags",
 				Handler: tags.ListTagHandler(serverCtx),
 			},
+			{
+				// 合并标签
+				Method:  http.MethodPost,
+				Path:    "/api/tag/merge",
+				Handler: tags.MergeTagHandler(serverCtx),
+			},
+			{
+				// 拆分标签, rules 模式立即执行, llm 模式创建任务
+				Method:  http.MethodPost,
+				Path:    "/api/tag/split",
+				Handler: tags.SplitTagHandler(serverCtx),
+			},
+			{
+				// 标签使用统计
+				Method:  http.MethodGet,
+				Path:    "/api/tags/stats",
+				Handler: tags.TagStatsHandler(serverCtx),
+			},
+			{
+				// 标签云
+				Method:  http.MethodGet,
+				Path:    "/api/tags/cloud",
+				Handler: tags.TagCloudHandler(serverCtx),
+			},
+			{
+				// 获取标签别名
+				Method:  http.MethodGet,
+				Path:    "/api/tag/aliases",
+				Handler: tags.ListTagAliasHandler(serverCtx),
+			},
+			{
+				// 添加标签别名
+				Method:  http.MethodPost,
+				Path:    "/api/tag/alias",
+				Handler: tags.CreateTagAliasHandler(serverCtx),
+			},
+			{
+				// 删除标签别名
+				Method:  http.MethodDelete,
+				Path:    "/api/tag/alias",
+				Handler: tags.DeleteTagAliasHandler(serverCtx),
+			},
+			{
+				// 按名称聚类建议合并为别名的标签, 确认后调用合并标签
+				Method:  http.MethodGet,
+				Path:    "/api/admin/tags/alias/proposals",
+				Handler: tags.ProposeTagAliasHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/wise"),
 	)
@@ -199,6 +387,12 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/task/pause",
 				Handler: tasks.PauseTaskHandler(serverCtx),
 			},
+			{
+				// 死信任务重新入队
+				Method:  http.MethodPost,
+				Path:    "/api/task/requeue",
+				Handler: tasks.RequeueTaskHandler(serverCtx),
+			},
 			{
 				// 恢复任务
 				Method:  http.MethodPost,
@@ -223,7 +417,27 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/tasks",
 				Handler: tasks.ListTaskHandler(serverCtx),
 			},
+			{
+				// 任务耗时与成本统计
+				Method:  http.MethodGet,
+				Path:    "/api/tasks/stats",
+				Handler: tasks.TaskStatsHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/wise"),
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				// 任务进度推送
+				Method:  http.MethodGet,
+				Path:    "/api/tasks/events",
+				Handler: tasks.TaskEventsHandler(serverCtx),
+			},
 		},
+		rest.WithSSE(),
 		rest.WithPrefix("/wise"),
+		rest.WithTimeout(3600000*time.Millisecond),
 	)
 }
@@ -0,0 +1,27 @@
+package rules
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/XXueTu/wise/internal/logic/rules"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+	"github.com/XXueTu/wise/response"
+)
+
+func DryRunTagRuleHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.DryRunTagRuleRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		l := rules.NewDryRunTagRuleLogic(r.Context(), svcCtx)
+		resp, err := l.DryRunTagRule(&req)
+		response.Response(w, resp, err)
+
+	}
+}
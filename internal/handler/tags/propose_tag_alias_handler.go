@@ -0,0 +1,27 @@
+package tags
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/XXueTu/wise/internal/logic/tags"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+	"github.com/XXueTu/wise/response"
+)
+
+func ProposeTagAliasHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ProposeTagAliasRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		l := tags.NewProposeTagAliasLogic(r.Context(), svcCtx)
+		resp, err := l.ProposeTagAlias(&req)
+		response.Response(w, resp, err)
+
+	}
+}
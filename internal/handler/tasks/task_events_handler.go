@@ -0,0 +1,60 @@
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/threading"
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/XXueTu/wise/internal/logic/tasks"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+func TaskEventsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.TaskEventsRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		client := make(chan *types.TaskEvent, 16)
+		l := tasks.NewTaskEventsLogic(r.Context(), svcCtx)
+		threading.GoSafeCtx(r.Context(), func() {
+			defer close(client)
+			if err := l.TaskEvents(&req, client); err != nil {
+				logx.WithContext(r.Context()).Errorf("TaskEventsHandler tid: %s, error: %v", req.Tid, err)
+			}
+		})
+
+		for {
+			select {
+			case data, ok := <-client:
+				if !ok {
+					return
+				}
+				output, err := json.Marshal(data)
+				if err != nil {
+					logx.WithContext(r.Context()).Errorf("TaskEventsHandler marshal error: %v", err)
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "data: %s\n\n", output); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
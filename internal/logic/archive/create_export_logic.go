@@ -0,0 +1,41 @@
+package archive
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/task"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type CreateExportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 创建知识库导出任务
+func NewCreateExportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateExportLogic {
+	return &CreateExportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateExportLogic) CreateExport(req *types.CreateExportRequest) (resp *types.ExportResponse, err error) {
+	t, err := task.CreateTask(l.ctx, l.svcCtx, req.Format, "导出知识库", task.TypeExport, model.TaskPriorityNormal)
+	if err != nil {
+		l.Errorf("CreateExport format: %s, error: %v", req.Format, err)
+		return nil, errors.New("创建导出任务失败")
+	}
+	return &types.ExportResponse{
+		Tid:    t.Tid,
+		Format: req.Format,
+		Status: t.Status,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/task"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type GetExportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 下载导出文件
+func NewGetExportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetExportLogic {
+	return &GetExportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetExport 返回导出文件路径, 任务未完成时返回错误
+func (l *GetExportLogic) GetExport(req *types.GetExportRequest) (path string, err error) {
+	t, err := l.svcCtx.TasksModel.GetByTid(l.ctx, req.Tid)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && t.Types != task.TypeExport) {
+		return "", errors.New("导出任务不存在")
+	}
+	if err != nil {
+		l.Errorf("GetExport tid: %s, error: %v", req.Tid, err)
+		return "", errors.New("获取导出任务失败")
+	}
+	if t.Status != model.TaskStatusSuccess {
+		return "", errors.New("导出未完成, 当前状态: " + t.Status)
+	}
+	path = task.ExportPath(l.svcCtx.Config.Archive.Dir, t.Tid, t.Params)
+	if _, err := os.Stat(path); err != nil {
+		l.Errorf("GetExport tid: %s, path: %s, error: %v", req.Tid, path, err)
+		return "", errors.New("导出文件不存在")
+	}
+	return path, nil
+}
@@ -0,0 +1,70 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/archive"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type ImportArchiveLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 导入知识库归档
+func NewImportArchiveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ImportArchiveLogic {
+	return &ImportArchiveLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ImportArchiveLogic) ImportArchive(req *types.ImportArchiveRequest, filename string, file io.Reader) (resp *types.ImportArchiveResponse, err error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		l.Errorf("ImportArchive read filename: %s, error: %v", filename, err)
+		return nil, errors.New("读取文件失败")
+	}
+	var a *archive.Archive
+	// markdown 导出的压缩包中包含完整的 json 归档
+	if bytes.HasPrefix(data, []byte("PK")) {
+		a, err = archive.ReadZip(bytes.NewReader(data), int64(len(data)))
+	} else {
+		a, err = archive.ReadJson(bytes.NewReader(data))
+	}
+	if errors.Is(err, archive.ErrUnsupportedVersion) {
+		return nil, errors.New("归档版本过高, 请升级后导入")
+	}
+	if err != nil {
+		l.Errorf("ImportArchive parse filename: %s, error: %v", filename, err)
+		return nil, errors.New("解析归档失败")
+	}
+	result, err := archive.Import(l.ctx, l.svcCtx, a)
+	if err != nil {
+		l.Errorf("ImportArchive filename: %s, error: %v", filename, err)
+		return nil, errors.New("导入归档失败, 未导入任何数据")
+	}
+	return &types.ImportArchiveResponse{
+		TagsCreated:         result.TagsCreated,
+		TagsUpdated:         result.TagsUpdated,
+		ParentsSkipped:      result.ParentsSkipped,
+		AliasesCreated:      result.AliasesCreated,
+		ResourcesCreated:    result.ResourcesCreated,
+		ResourcesUpdated:    result.ResourcesUpdated,
+		ResourceTagsSkipped: result.ResourceTagsSkipped,
+		RulesCreated:        result.RulesCreated,
+		RulesUpdated:        result.RulesUpdated,
+		RulesSkipped:        result.RulesSkipped,
+		ModelsCreated:       result.ModelsCreated,
+		ModelsSkipped:       result.ModelsSkipped,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package backup
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/backup"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type CreateBackupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 立即备份数据库
+func NewCreateBackupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateBackupLogic {
+	return &CreateBackupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateBackupLogic) CreateBackup(req *types.CreateBackupRequest) (resp *types.Backup, err error) {
+	snapshot, err := l.svcCtx.Backup.Create(l.ctx)
+	if errors.Is(err, backup.ErrUnsupported) {
+		return nil, errors.New("当前数据库不支持在线备份, PostgreSQL 请使用 pg_dump")
+	}
+	if err != nil {
+		l.Errorf("CreateBackup error: %v", err)
+		return nil, errors.New("备份数据库失败")
+	}
+	backup := toBackup(*snapshot)
+	return &backup, nil
+}
@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/backup"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type GetBackupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 下载备份
+func NewGetBackupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetBackupLogic {
+	return &GetBackupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetBackup 返回快照信息和文件路径
+func (l *GetBackupLogic) GetBackup(req *types.GetBackupRequest) (*backup.Snapshot, string, error) {
+	snapshots, err := l.svcCtx.Backup.List()
+	if err != nil {
+		l.Errorf("GetBackup name: %s, error: %v", req.Name, err)
+		return nil, "", errors.New("获取备份失败")
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Name != req.Name {
+			continue
+		}
+		path, err := l.svcCtx.Backup.Path(snapshot.Name)
+		if err != nil {
+			break
+		}
+		return &snapshot, path, nil
+	}
+	return nil, "", errors.New("备份不存在")
+}
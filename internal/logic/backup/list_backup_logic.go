@@ -0,0 +1,52 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/backup"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type ListBackupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取备份列表
+func NewListBackupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListBackupLogic {
+	return &ListBackupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListBackupLogic) ListBackup(req *types.ListBackupRequest) (resp *types.ListBackupResponse, err error) {
+	snapshots, err := l.svcCtx.Backup.List()
+	if err != nil {
+		l.Errorf("ListBackup error: %v", err)
+		return nil, errors.New("获取备份列表失败")
+	}
+	resp = &types.ListBackupResponse{
+		List: make([]types.Backup, 0, len(snapshots)),
+	}
+	for _, snapshot := range snapshots {
+		resp.List = append(resp.List, toBackup(snapshot))
+	}
+	return resp, nil
+}
+
+func toBackup(snapshot backup.Snapshot) types.Backup {
+	return types.Backup{
+		Name:      snapshot.Name,
+		Size:      snapshot.Size,
+		Checksum:  snapshot.Checksum,
+		CreatedAt: snapshot.CreatedAt.Format(time.DateTime),
+	}
+}
@@ -0,0 +1,49 @@
+package backup
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/backup"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type RestoreBackupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 恢复备份, 恢复期间暂停任务调度
+func NewRestoreBackupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RestoreBackupLogic {
+	return &RestoreBackupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RestoreBackupLogic) RestoreBackup(req *types.RestoreBackupRequest) (resp *types.RestoreBackupResponse, err error) {
+	// 恢复可能较慢, 不随请求取消
+	current, err := l.svcCtx.Backup.Restore(context.WithoutCancel(l.ctx), req.Name)
+	switch {
+	case errors.Is(err, backup.ErrUnsupported):
+		return nil, errors.New("当前数据库不支持恢复备份, PostgreSQL 请使用 pg_restore")
+	case errors.Is(err, backup.ErrSnapshotNotFound):
+		return nil, errors.New("备份不存在")
+	case errors.Is(err, backup.ErrChecksumMismatch):
+		return nil, errors.New("备份校验失败, 文件可能已损坏")
+	case errors.Is(err, backup.ErrSchemaMismatch):
+		return nil, errors.New("备份的数据库版本与当前版本不一致, 请使用相同版本的程序恢复")
+	case err != nil:
+		l.Errorf("RestoreBackup name: %s, error: %v", req.Name, err)
+		return nil, errors.New("恢复备份失败: " + err.Error())
+	}
+	return &types.RestoreBackupResponse{
+		Restored:  req.Name,
+		PreBackup: current.Name,
+	}, nil
+}
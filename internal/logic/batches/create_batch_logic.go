@@ -0,0 +1,95 @@
+package batches
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/task"
+	"github.com/XXueTu/wise/internal/types"
+	"github.com/XXueTu/wise/pkg/importer"
+)
+
+type CreateBatchLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 创建批量导入
+func NewCreateBatchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateBatchLogic {
+	return &CreateBatchLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateBatchLogic) CreateBatch(req *types.CreateBatchRequest) (resp *types.CreateBatchResponse, err error) {
+	links := importer.ParseText(req.Urls)
+	if len(links) == 0 {
+		return nil, errors.New("未找到有效的链接")
+	}
+	if req.DryRun {
+		return previewBatch(l.ctx, l.svcCtx, l.Logger, links)
+	}
+	batch, items, err := task.CreateBatch(l.ctx, l.svcCtx, batchName(req.Name), model.BatchSourceText, links)
+	if err != nil {
+		l.Errorf("CreateBatch name: %s, total: %d, error: %v", req.Name, len(links), err)
+		return nil, errors.New("创建批量导入失败")
+	}
+	return toCreateBatchResponse(batch, items), nil
+}
+
+// batchName 未指定名称时按创建时间命名
+func batchName(name string) string {
+	if name != "" {
+		return name
+	}
+	return "批量导入 " + time.Now().Format(time.DateTime)
+}
+
+// previewBatch 预览导入结果, 不创建任务和标签
+func previewBatch(ctx context.Context, svcCtx *svc.ServiceContext, logger logx.Logger, links []importer.Link) (*types.CreateBatchResponse, error) {
+	items, newTags, err := task.PreviewBatch(ctx, svcCtx, links)
+	if err != nil {
+		logger.Errorf("PreviewBatch total: %d, error: %v", len(links), err)
+		return nil, errors.New("预览批量导入失败")
+	}
+	resp := toCreateBatchResponse(&model.Batches{Total: int64(len(links))}, items)
+	resp.DryRun = true
+	resp.NewTags = newTags
+	resp.Items = make([]types.BatchItem, 0, len(items))
+	for _, item := range items {
+		resp.Items = append(resp.Items, types.BatchItem{
+			Url:    item.URL,
+			Title:  item.Title,
+			Status: item.Status,
+			Error:  item.Error,
+		})
+	}
+	return resp, nil
+}
+
+func toCreateBatchResponse(batch *model.Batches, items []*model.BatchItems) *types.CreateBatchResponse {
+	resp := &types.CreateBatchResponse{
+		Bid:     batch.Bid,
+		Total:   batch.Total,
+		NewTags: make([]string, 0),
+	}
+	for _, item := range items {
+		switch item.Status {
+		case model.BatchItemStatusQueued:
+			resp.Queued++
+		case model.BatchItemStatusDuplicate:
+			resp.Duplicate++
+		case model.BatchItemStatusInvalid:
+			resp.Invalid++
+		}
+	}
+	return resp
+}
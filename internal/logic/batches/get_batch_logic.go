@@ -0,0 +1,136 @@
+package batches
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+// 批次明细对外展示的状态
+const (
+	itemStatusQueued    = "queued"
+	itemStatusRunning   = "running"
+	itemStatusSucceeded = "succeeded"
+	itemStatusFailed    = "failed"
+	itemStatusDuplicate = "duplicate"
+)
+
+type GetBatchLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取批量导入进度
+func NewGetBatchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetBatchLogic {
+	return &GetBatchLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetBatchLogic) GetBatch(req *types.GetBatchRequest) (resp *types.BatchResponse, err error) {
+	batch, err := l.svcCtx.BatchesModel.GetByBid(l.ctx, req.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("批次不存在")
+	}
+	if err != nil {
+		l.Errorf("GetBatch bid: %s, error: %v", req.Id, err)
+		return nil, errors.New("获取批次失败")
+	}
+	items, err := l.svcCtx.BatchItemsModel.GetByBid(l.ctx, req.Id)
+	if err != nil {
+		l.Errorf("GetBatch items bid: %s, error: %v", req.Id, err)
+		return nil, errors.New("获取批次明细失败")
+	}
+	tids := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Tid != "" {
+			tids = append(tids, item.Tid)
+		}
+	}
+	tasks, err := l.svcCtx.TasksModel.GetByTids(l.ctx, tids)
+	if err != nil {
+		l.Errorf("GetBatch tasks bid: %s, error: %v", req.Id, err)
+		return nil, errors.New("获取批次任务失败")
+	}
+	taskMap := make(map[string]*model.Tasks, len(tasks))
+	for _, t := range tasks {
+		taskMap[t.Tid] = t
+	}
+
+	resp = &types.BatchResponse{
+		Bid:       batch.Bid,
+		Name:      batch.Name,
+		Source:    batch.Source,
+		Total:     batch.Total,
+		Items:     make([]types.BatchItem, 0),
+		CreatedAt: batch.CreatedAt.Format(time.DateTime),
+	}
+	// 进度按全部明细聚合, 明细列表分页返回
+	offset := (req.Page - 1) * req.PageSize
+	for i, item := range items {
+		detail := toBatchItem(item, taskMap[item.Tid])
+		switch detail.Status {
+		case itemStatusQueued:
+			resp.Progress.Queued++
+		case itemStatusRunning:
+			resp.Progress.Running++
+		case itemStatusSucceeded:
+			resp.Progress.Succeeded++
+		case itemStatusFailed:
+			resp.Progress.Failed++
+		case itemStatusDuplicate:
+			resp.Progress.Duplicate++
+		}
+		if int64(i) >= offset && int64(i) < offset+req.PageSize {
+			resp.Items = append(resp.Items, detail)
+		}
+	}
+	return resp, nil
+}
+
+// toBatchItem 根据明细和任务状态计算展示状态
+func toBatchItem(item *model.BatchItems, task *model.Tasks) types.BatchItem {
+	detail := types.BatchItem{
+		Url:   item.URL,
+		Title: item.Title,
+		Tid:   item.Tid,
+		Error: item.Error,
+	}
+	switch item.Status {
+	case model.BatchItemStatusDuplicate:
+		detail.Status = itemStatusDuplicate
+		return detail
+	case model.BatchItemStatusInvalid:
+		detail.Status = itemStatusFailed
+		return detail
+	}
+	if task == nil {
+		detail.Status = itemStatusFailed
+		detail.Error = "任务不存在"
+		return detail
+	}
+	switch task.Status {
+	case model.TaskStatusInit, model.TaskStatusRetry:
+		detail.Status = itemStatusQueued
+	case model.TaskStatusRunning:
+		detail.Status = itemStatusRunning
+	case model.TaskStatusSuccess:
+		detail.Status = itemStatusSucceeded
+	default:
+		detail.Status = itemStatusFailed
+		if task.Error != "" && task.Error != "{}" {
+			detail.Error = task.Error
+		}
+	}
+	return detail
+}
@@ -0,0 +1,49 @@
+package batches
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type ListBatchLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取批量导入列表
+func NewListBatchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListBatchLogic {
+	return &ListBatchLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListBatchLogic) ListBatch(req *types.ListBatchRequest) (resp *types.ListBatchResponse, err error) {
+	batchList, err := l.svcCtx.BatchesModel.GetList(l.ctx, req.Page, req.PageSize)
+	if err != nil {
+		l.Errorf("ListBatch page: %d, page_size: %d, error: %v", req.Page, req.PageSize, err)
+		return nil, errors.New("获取批量导入列表失败")
+	}
+	resp = &types.ListBatchResponse{
+		Total: batchList.Total,
+		List:  make([]types.Batch, 0, len(batchList.List)),
+	}
+	for _, batch := range batchList.List {
+		resp.List = append(resp.List, types.Batch{
+			Bid:       batch.Bid,
+			Name:      batch.Name,
+			Source:    batch.Source,
+			Total:     batch.Total,
+			CreatedAt: batch.CreatedAt.Format(time.DateTime),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,61 @@
+package batches
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type RetryBatchLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 重试批次中失败的任务
+func NewRetryBatchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RetryBatchLogic {
+	return &RetryBatchLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RetryBatchLogic) RetryBatch(req *types.RetryBatchRequest) (resp *types.RetryBatchResponse, err error) {
+	if _, err := l.svcCtx.BatchesModel.GetByBid(l.ctx, req.Id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("批次不存在")
+		}
+		l.Errorf("RetryBatch bid: %s, error: %v", req.Id, err)
+		return nil, errors.New("获取批次失败")
+	}
+	items, err := l.svcCtx.BatchItemsModel.GetByBid(l.ctx, req.Id)
+	if err != nil {
+		l.Errorf("RetryBatch items bid: %s, error: %v", req.Id, err)
+		return nil, errors.New("获取批次明细失败")
+	}
+	tids := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Tid != "" {
+			tids = append(tids, item.Tid)
+		}
+	}
+	// 只重新入队已失败的任务, 成功和执行中的任务不受影响
+	requeued, err := l.svcCtx.TasksModel.RequeueFailed(l.ctx, tids, time.Now())
+	if err != nil {
+		l.Errorf("RetryBatch requeue bid: %s, error: %v", req.Id, err)
+		return nil, errors.New("重试失败任务失败")
+	}
+	for _, tid := range requeued {
+		_ = l.svcCtx.TaskQueue.Push(l.ctx, &model.Tasks{Tid: tid})
+	}
+	l.Infof("RetryBatch bid: %s, retried: %d", req.Id, len(requeued))
+	return &types.RetryBatchResponse{Retried: int64(len(requeued))}, nil
+}
@@ -0,0 +1,61 @@
+package batches
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/task"
+	"github.com/XXueTu/wise/internal/types"
+	"github.com/XXueTu/wise/pkg/importer"
+)
+
+type UploadBatchLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 上传文件批量导入
+func NewUploadBatchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UploadBatchLogic {
+	return &UploadBatchLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UploadBatchLogic) UploadBatch(req *types.UploadBatchRequest, filename string, file io.Reader) (resp *types.CreateBatchResponse, err error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		l.Errorf("UploadBatch read filename: %s, error: %v", filename, err)
+		return nil, errors.New("读取文件失败")
+	}
+	format := importer.Detect(filename, data)
+	links, err := importer.Parse(format, filename, bytes.NewReader(data))
+	if err != nil {
+		l.Errorf("UploadBatch parse filename: %s, format: %s, error: %v", filename, format, err)
+		return nil, errors.New("解析文件失败")
+	}
+	if len(links) == 0 {
+		return nil, errors.New("文件中未找到有效的链接")
+	}
+	if req.DryRun {
+		return previewBatch(l.ctx, l.svcCtx, l.Logger, links)
+	}
+	name := req.Name
+	if name == "" {
+		name = filename
+	}
+	// 文件格式与批次来源取值一致
+	batch, items, err := task.CreateBatch(l.ctx, l.svcCtx, batchName(name), format, links)
+	if err != nil {
+		l.Errorf("UploadBatch filename: %s, total: %d, error: %v", filename, len(links), err)
+		return nil, errors.New("创建批量导入失败")
+	}
+	return toCreateBatchResponse(batch, items), nil
+}
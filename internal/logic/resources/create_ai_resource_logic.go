@@ -39,12 +39,15 @@ func (l *CreateAiResourceLogic) CreateAiResource(req *types.CreateAiResourceRequ
 		Title:   title,
 		Content: content,
 		Type:    "微信公众号",
-		Tags:    "default",
 	}
 	err = l.svcCtx.ResourceModel.Create(l.ctx, &resource)
 	if err != nil {
 		return resp, err
 	}
+	err = l.svcCtx.ResourceTagsModel.SetTags(l.ctx, resource.ID, []string{model.DefaultTagUid})
+	if err != nil {
+		return resp, err
+	}
 	resp = &types.Resource{
 		Id:        resource.ID,
 		URL:       req.URL,
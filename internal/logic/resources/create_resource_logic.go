@@ -3,7 +3,6 @@ package resources
 import (
 	"context"
 	"errors"
-	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
@@ -28,18 +27,20 @@ func NewCreateResourceLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Cr
 }
 
 func (l *CreateResourceLogic) CreateResource(req *types.CreateResourceRequest) (resp *types.Resource, err error) {
-	tagUids := strings.Join(req.TagUids, ",")
 	resourceModel := &model.Resource{
 		URL:     req.URL,
 		Title:   req.Title,
 		Content: req.Content,
 		Type:    req.Type,
-		Tags:    tagUids,
 	}
 	err = l.svcCtx.ResourceModel.Create(l.ctx, resourceModel)
 	if err != nil {
 		return nil, errors.New("创建资源失败")
 	}
+	err = l.svcCtx.ResourceTagsModel.SetTags(l.ctx, resourceModel.ID, req.TagUids)
+	if err != nil {
+		return nil, errors.New("设置标签失败")
+	}
 	// 获取标签
 	tagList, err := l.svcCtx.TagsModel.GetUids(l.ctx, req.TagUids)
 	if err != nil {
@@ -3,7 +3,6 @@ package resources
 import (
 	"context"
 	"errors"
-	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
@@ -32,21 +31,28 @@ func (l *GetResourceLogic) GetResource(req *types.GetResourceRequest) (resp *typ
 		return nil, errors.New("获取资源失败")
 	}
 	// 获取标签
-	tagList, err := l.svcCtx.TagsModel.GetUids(l.ctx, strings.Split(resource.Tags, ","))
+	resourceTags, err := l.svcCtx.ResourceTagsModel.GetTags(l.ctx, []int64{resource.ID})
 	if err != nil {
 		return nil, errors.New("获取标签失败")
 	}
-	var tags []string
-	for _, tag := range tagList {
+	keywords, err := l.svcCtx.ResourceKeywordsModel.GetByResources(l.ctx, []int64{resource.ID})
+	if err != nil {
+		return nil, errors.New("获取关键词失败")
+	}
+	var tags, tagUids []string
+	for _, tag := range resourceTags[resource.ID] {
 		tags = append(tags, tag.Name)
+		tagUids = append(tagUids, tag.Uid)
 	}
 	resp = &types.Resource{
-		Id:      resource.ID,
-		URL:     resource.URL,
-		Title:   resource.Title,
-		Content: resource.Content,
-		Type:    resource.Type,
-		Tags:    tags,
+		Id:       resource.ID,
+		URL:      resource.URL,
+		Title:    resource.Title,
+		Content:  resource.Content,
+		Type:     resource.Type,
+		Tags:     tags,
+		TagUids:  tagUids,
+		Keywords: toResourceKeywords(keywords[resource.ID]),
 	}
 	return resp, nil
 }
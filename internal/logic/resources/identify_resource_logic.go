@@ -2,14 +2,17 @@ package resources
 
 import (
 	"context"
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/XXueTu/wise/internal/model"
 	"github.com/XXueTu/wise/internal/svc"
 	"github.com/XXueTu/wise/internal/task"
 	"github.com/XXueTu/wise/internal/types"
-	"github.com/XXueTu/wise/pkg/agent/url_analyse.go"
+	"github.com/XXueTu/wise/pkg/importer"
 )
 
 type IdentifyResourceLogic struct {
@@ -33,12 +36,35 @@ func (l *IdentifyResourceLogic) IdentifyResource(req *types.IdentifyResourceRequ
 	resp = &types.IdentifyResourceResponse{
 		Urls: make([]string, 0),
 	}
+	links := make([]importer.Link, 0, len(urls))
 	for _, url := range urls {
+		url = strings.TrimSpace(url)
 		if url == "" {
 			continue
 		}
-		_ = task.CreateTask(l.ctx, l.svcCtx, url, "解析URL", "URL_ANALYSE", int64(len(url_analyse.UrlAnalyseSteps)))
-		resp.Urls = append(resp.Urls, url)
+		links = append(links, importer.Link{URL: url})
+	}
+	// 单个链接是交互式提交, 优先执行
+	if len(links) == 1 {
+		if _, err := task.CreateTask(l.ctx, l.svcCtx, links[0].URL, "解析URL", task.TypeUrlAnalyse, model.TaskPriorityHigh); err != nil {
+			l.Errorf("IdentifyResource url: %s, error: %v", links[0].URL, err)
+			return resp, nil
+		}
+		resp.Urls = append(resp.Urls, links[0].URL)
+	}
+	// 多个链接按批量导入处理, 降低优先级, 避免阻塞交互式提交
+	if len(links) > 1 {
+		batch, items, err := task.CreateBatch(l.ctx, l.svcCtx, "识别资源 "+time.Now().Format(time.DateTime), model.BatchSourceIdentify, links)
+		if err != nil {
+			l.Errorf("IdentifyResource batch urls: %s, error: %v", req.URL, err)
+			return nil, errors.New("创建批量导入失败")
+		}
+		resp.BatchId = batch.Bid
+		for _, item := range items {
+			if item.Status == model.BatchItemStatusQueued {
+				resp.Urls = append(resp.Urls, item.URL)
+			}
+		}
 	}
 	logx.Info("identify resource urls:", strings.Join(resp.Urls, ","))
 	return resp, nil
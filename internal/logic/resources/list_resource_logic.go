@@ -3,11 +3,11 @@ package resources
 import (
 	"context"
 	"errors"
-	"strings"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/XXueTu/wise/internal/model"
 	"github.com/XXueTu/wise/internal/svc"
 	"github.com/XXueTu/wise/internal/types"
 )
@@ -29,25 +29,34 @@ func NewListResourceLogic(ctx context.Context, svcCtx *svc.ServiceContext) *List
 
 func (l *ListResourceLogic) ListResource(req *types.ListResourceRequest) (resp *types.ListResourceResponse, err error) {
 	logx.Infof("ListResourceLogic: %+v", req)
-	resources, err := l.svcCtx.ResourceModel.GetList(l.ctx, int(req.Page), int(req.PageSize), req.Type, req.Keyword, req.TagUids)
+	filter := model.TagFilter{All: req.TagUids, Any: req.AnyTagUids, None: req.ExcludeTagUids}
+	keywords := model.KeywordFilter{All: req.Keywords, Type: req.KeywordType}
+	resources, err := l.svcCtx.ResourceModel.GetList(l.ctx, int(req.Page), int(req.PageSize), req.Type, req.Keyword, filter, keywords)
 	if err != nil {
 		return nil, errors.New("获取资源列表失败")
 	}
+	// 一次查询当前页全部资源的标签
+	ids := make([]int64, len(resources.List))
+	for i, resource := range resources.List {
+		ids[i] = resource.ID
+	}
+	resourceTags, err := l.svcCtx.ResourceTagsModel.GetTags(l.ctx, ids)
+	if err != nil {
+		return nil, errors.New("获取标签失败")
+	}
+	resourceKeywords, err := l.svcCtx.ResourceKeywordsModel.GetByResources(l.ctx, ids)
+	if err != nil {
+		return nil, errors.New("获取关键词失败")
+	}
 	resp = &types.ListResourceResponse{
 		Total:     resources.Total,
 		Resources: make([]types.Resource, len(resources.List)),
 	}
 	for i, resource := range resources.List {
-		var tags []string
-		if resource.Tags != "" {
-			// 获取标签
-			tagList, err := l.svcCtx.TagsModel.GetUids(l.ctx, strings.Split(resource.Tags, ","))
-			if err != nil {
-				return nil, errors.New("获取标签失败")
-			}
-			for _, tag := range tagList {
-				tags = append(tags, tag.Name)
-			}
+		var tags, tagUids []string
+		for _, tag := range resourceTags[resource.ID] {
+			tags = append(tags, tag.Name)
+			tagUids = append(tagUids, tag.Uid)
 		}
 		resp.Resources[i] = types.Resource{
 			Id:        resource.ID,
@@ -57,10 +66,20 @@ func (l *ListResourceLogic) ListResource(req *types.ListResourceRequest) (resp *
 			Content:   resource.Content,
 			Type:      resource.Type,
 			Tags:      tags,
-			TagUids:   strings.Split(resource.Tags, ","),
+			TagUids:   tagUids,
+			Keywords:  toResourceKeywords(resourceKeywords[resource.ID]),
 			CreatedAt: resource.CreatedAt.Format(time.DateTime),
 			UpdatedAt: resource.UpdatedAt.Format(time.DateTime),
 		}
 	}
 	return resp, nil
 }
+
+// toResourceKeywords 转换为接口返回的关键词
+func toResourceKeywords(keywords []*model.ResourceKeywords) []types.ResourceKeyword {
+	result := make([]types.ResourceKeyword, len(keywords))
+	for i, keyword := range keywords {
+		result[i] = types.ResourceKeyword{Keyword: keyword.Keyword, Type: keyword.Type, Weight: keyword.Weight}
+	}
+	return result
+}
@@ -3,7 +3,6 @@ package resources
 import (
 	"context"
 	"errors"
-	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
@@ -34,12 +33,14 @@ func (l *UpdateResourceLogic) UpdateResource(req *types.UpdateResourceRequest) (
 	resource.Title = req.Title
 	resource.Content = req.Content
 	resource.Type = req.Type
-	tagUids := strings.Join(req.TagUids, ",")
-	resource.Tags = tagUids
 	err = l.svcCtx.ResourceModel.Update(l.ctx, resource)
 	if err != nil {
 		return nil, errors.New("更新资源失败")
 	}
+	err = l.svcCtx.ResourceTagsModel.SetTags(l.ctx, resource.ID, req.TagUids)
+	if err != nil {
+		return nil, errors.New("设置标签失败")
+	}
 	// 获取标签
 	tagList, err := l.svcCtx.TagsModel.GetUids(l.ctx, req.TagUids)
 	if err != nil {
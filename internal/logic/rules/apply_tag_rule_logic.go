@@ -0,0 +1,48 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/task"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type ApplyTagRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 对全部资源执行标签规则
+func NewApplyTagRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ApplyTagRuleLogic {
+	return &ApplyTagRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ApplyTagRuleLogic) ApplyTagRule(req *types.ApplyTagRuleRequest) (resp *types.ApplyTagRuleResponse, err error) {
+	if len(req.Uids) > 0 {
+		rules, err := l.svcCtx.TagRulesModel.GetUids(l.ctx, req.Uids)
+		if err != nil {
+			return nil, errors.New("查询标签规则失败")
+		}
+		if len(rules) != len(req.Uids) {
+			return nil, errors.New("标签规则不存在")
+		}
+	}
+	params, _ := json.Marshal(task.TagRuleApplyParams{RuleUids: req.Uids})
+	t, err := task.CreateTask(l.ctx, l.svcCtx, string(params), "执行标签规则", task.TypeTagRuleApply, model.TaskPriorityNormal)
+	if err != nil {
+		l.Errorf("ApplyTagRule CreateTask uids: %v, error: %v", req.Uids, err)
+		return nil, errors.New("创建规则任务失败")
+	}
+	return &types.ApplyTagRuleResponse{Tid: t.Tid}, nil
+}
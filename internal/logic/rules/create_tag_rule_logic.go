@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/tagging"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type CreateTagRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 创建标签规则
+func NewCreateTagRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateTagRuleLogic {
+	return &CreateTagRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateTagRuleLogic) CreateTagRule(req *types.CreateTagRuleRequest) (resp *types.TagRule, err error) {
+	rule := &model.TagRules{
+		Uid:      model.GenUid(),
+		Name:     req.Name,
+		Type:     req.Type,
+		Field:    req.Field,
+		Pattern:  req.Pattern,
+		Action:   req.Action,
+		TagUid:   req.TagUid,
+		Priority: req.Priority,
+		Enabled:  req.Enabled,
+	}
+	tag, err := checkRule(l.ctx, l.svcCtx, rule)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.svcCtx.TagRulesModel.Create(l.ctx, rule); err != nil {
+		return nil, errors.New("创建标签规则失败")
+	}
+	return ruleResponse(rule, tag.Name), nil
+}
+
+// checkRule 校验规则格式和标签, 返回规则引用的标签
+func checkRule(ctx context.Context, svcCtx *svc.ServiceContext, rule *model.TagRules) (*model.Tags, error) {
+	if _, err := tagging.CompileRule(rule); err != nil {
+		return nil, errors.New("规则格式错误: " + err.Error())
+	}
+	tag, err := svcCtx.TagsModel.GetUid(ctx, rule.TagUid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("标签不存在")
+	}
+	if err != nil {
+		return nil, errors.New("查询标签失败")
+	}
+	return tag, nil
+}
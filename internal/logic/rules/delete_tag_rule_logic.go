@@ -0,0 +1,33 @@
+package rules
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type DeleteTagRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 删除标签规则
+func NewDeleteTagRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteTagRuleLogic {
+	return &DeleteTagRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteTagRuleLogic) DeleteTagRule(req *types.DeleteTagRuleRequest) (resp *types.DeleteTagRuleResponse, err error) {
+	if err := l.svcCtx.TagRulesModel.Delete(l.ctx, req.Uid); err != nil {
+		return nil, errors.New("删除标签规则失败")
+	}
+	return &types.DeleteTagRuleResponse{Result: "删除标签规则成功"}, nil
+}
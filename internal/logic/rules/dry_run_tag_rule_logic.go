@@ -0,0 +1,103 @@
+package rules
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/tagging"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type DryRunTagRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 试运行标签规则
+func NewDryRunTagRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DryRunTagRuleLogic {
+	return &DryRunTagRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DryRunTagRule 同一个标签以最后匹配的规则为准, 在启用的规则中加入或替换试运行的规则, 报告与当前结果不同的资源
+// 只传入已保存规则的唯一标识时, 与去掉该规则的结果比较
+func (l *DryRunTagRuleLogic) DryRunTagRule(req *types.DryRunTagRuleRequest) (resp *types.DryRunTagRuleResponse, err error) {
+	var rule *model.TagRules
+	if req.Uid != "" {
+		rule, err = l.svcCtx.TagRulesModel.GetUid(l.ctx, req.Uid)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("标签规则不存在")
+		}
+		if err != nil {
+			return nil, errors.New("查询标签规则失败")
+		}
+	}
+	edited := req.Uid == "" || req.Rule.Pattern != ""
+	if edited {
+		candidate := &model.TagRules{
+			Type:     req.Rule.Type,
+			Field:    req.Rule.Field,
+			Pattern:  req.Rule.Pattern,
+			Action:   req.Rule.Action,
+			TagUid:   req.Rule.TagUid,
+			Priority: req.Rule.Priority,
+		}
+		if rule != nil {
+			candidate.ID = rule.ID
+			candidate.Uid = rule.Uid
+		}
+		if _, err := checkRule(l.ctx, l.svcCtx, candidate); err != nil {
+			return nil, err
+		}
+		rule = candidate
+	}
+	compiled, err := tagging.CompileRule(rule)
+	if err != nil {
+		return nil, errors.New("规则格式错误: " + err.Error())
+	}
+	enabled, err := l.svcCtx.TagRules.Enabled(l.ctx)
+	if err != nil {
+		l.Errorf("DryRunTagRule Enabled uid: %s, error: %v", req.Uid, err)
+		return nil, errors.New("查询标签规则失败")
+	}
+	before := enabled
+	if !edited {
+		before = tagging.WithoutRule(enabled, rule.Uid)
+	}
+	after := tagging.WithRule(enabled, compiled)
+
+	resp = &types.DryRunTagRuleResponse{List: make([]types.TagRuleEffect, 0)}
+	err = l.svcCtx.TagRules.Diff(l.ctx, before, after, func(resource *model.Resource, diff tagging.Effect) error {
+		resp.Total++
+		if len(diff.Add) > 0 {
+			resp.Added++
+		}
+		if len(diff.Remove) > 0 {
+			resp.Removed++
+		}
+		if int64(len(resp.List)) < req.Limit {
+			resp.List = append(resp.List, types.TagRuleEffect{
+				ResourceId: resource.ID,
+				Title:      resource.Title,
+				URL:        resource.URL,
+				Add:        diff.Add,
+				Remove:     diff.Remove,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		l.Errorf("DryRunTagRule Diff uid: %s, error: %v", req.Uid, err)
+		return nil, errors.New("试运行标签规则失败")
+	}
+	return resp, nil
+}
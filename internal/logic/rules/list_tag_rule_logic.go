@@ -0,0 +1,72 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type ListTagRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取标签规则列表
+func NewListTagRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListTagRuleLogic {
+	return &ListTagRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListTagRuleLogic) ListTagRule(req *types.ListTagRuleRequest) (resp *types.ListTagRuleResponse, err error) {
+	rules, err := l.svcCtx.TagRulesModel.GetAll(l.ctx)
+	if err != nil {
+		return nil, errors.New("查询标签规则失败")
+	}
+	uids := make([]string, len(rules))
+	for i, rule := range rules {
+		uids[i] = rule.TagUid
+	}
+	names := make(map[string]string)
+	if len(uids) > 0 {
+		tags, err := l.svcCtx.TagsModel.GetUids(l.ctx, uids)
+		if err != nil {
+			l.Errorf("ListTagRule GetUids uids: %v, error: %v", uids, err)
+			return nil, errors.New("查询标签失败")
+		}
+		for _, tag := range tags {
+			names[tag.Uid] = tag.Name
+		}
+	}
+	resp = &types.ListTagRuleResponse{List: make([]types.TagRule, 0, len(rules))}
+	for _, rule := range rules {
+		resp.List = append(resp.List, *ruleResponse(rule, names[rule.TagUid]))
+	}
+	return resp, nil
+}
+
+func ruleResponse(rule *model.TagRules, tagName string) *types.TagRule {
+	return &types.TagRule{
+		Uid:       rule.Uid,
+		Name:      rule.Name,
+		Type:      rule.Type,
+		Field:     rule.Field,
+		Pattern:   rule.Pattern,
+		Action:    rule.Action,
+		TagUid:    rule.TagUid,
+		TagName:   tagName,
+		Priority:  rule.Priority,
+		Enabled:   rule.Enabled,
+		CreatedAt: rule.CreatedAt.Format(time.DateTime),
+		UpdatedAt: rule.UpdatedAt.Format(time.DateTime),
+	}
+}
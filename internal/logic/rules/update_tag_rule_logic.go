@@ -0,0 +1,53 @@
+package rules
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type UpdateTagRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 更新标签规则
+func NewUpdateTagRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateTagRuleLogic {
+	return &UpdateTagRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateTagRuleLogic) UpdateTagRule(req *types.UpdateTagRuleRequest) (resp *types.TagRule, err error) {
+	rule, err := l.svcCtx.TagRulesModel.GetUid(l.ctx, req.Uid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("标签规则不存在")
+	}
+	if err != nil {
+		return nil, errors.New("查询标签规则失败")
+	}
+	rule.Name = req.Name
+	rule.Type = req.Type
+	rule.Field = req.Field
+	rule.Pattern = req.Pattern
+	rule.Action = req.Action
+	rule.TagUid = req.TagUid
+	rule.Priority = req.Priority
+	rule.Enabled = req.Enabled
+	tag, err := checkRule(l.ctx, l.svcCtx, rule)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.svcCtx.TagRulesModel.Update(l.ctx, rule); err != nil {
+		return nil, errors.New("更新标签规则失败")
+	}
+	return ruleResponse(rule, tag.Name), nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/XXueTu/wise/internal/model"
 	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/tagname"
 	"github.com/XXueTu/wise/internal/types"
 )
 
@@ -31,31 +32,59 @@ func (l *CreateBatchTagLogic) CreateBatchTag(req *types.CreateBatchTagRequest) (
 	if len(req.Tags) == 0 {
 		return nil, errors.New("tags is empty")
 	}
-	// 批量查询标签是否存在
+	// 批量查询标签是否存在, 名称按规范化后匹配已有标签的名称和别名
 	tagNames := make([]string, 0)
 	for _, tag := range req.Tags {
 		tagNames = append(tagNames, tag.Name)
 	}
-	existTags, err := l.svcCtx.TagsModel.FindBatchByNames(l.ctx, tagNames)
+	existTags, err := l.svcCtx.TagsModel.MatchNames(l.ctx, tagNames)
 	if err != nil {
 		return nil, err
 	}
-	// 判断哪些标签不存在
-	notExistTags := make([]model.Tags, 0)
-	existTagMap := make(map[string]struct{})
-	for _, existTag := range existTags {
-		existTagMap[existTag.Name] = struct{}{}
+	// 校验父标签
+	parentUids := make([]string, 0)
+	for _, tag := range req.Tags {
+		if tag.ParentUid != "" {
+			parentUids = append(parentUids, tag.ParentUid)
+		}
+	}
+	if len(parentUids) > 0 {
+		parents, err := l.svcCtx.TagsModel.GetUids(l.ctx, parentUids)
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[string]bool, len(parents))
+		for _, parent := range parents {
+			found[parent.Uid] = true
+		}
+		for _, uid := range parentUids {
+			if !found[uid] {
+				return nil, errors.New("父标签不存在")
+			}
+		}
 	}
+	// 判断哪些标签不存在, 规范化后相同的名称只创建一次
+	notExistTags := make([]model.Tags, 0)
+	existTotal := make(map[string]bool)
+	created := make(map[string]bool)
 	for _, tag := range req.Tags {
-		if _, exist := existTagMap[tag.Name]; !exist {
-			notExistTags = append(notExistTags, model.Tags{
-				Name:        tag.Name,
-				Uid:         model.GenUid(),
-				Description: tag.Description,
-				Color:       tag.Color,
-				Icon:        tag.Icon,
-			})
+		if existTag, exist := existTags[tag.Name]; exist {
+			existTotal[existTag.Uid] = true
+			continue
+		}
+		key := tagname.Key(tag.Name)
+		if key == "" || created[key] {
+			continue
 		}
+		created[key] = true
+		notExistTags = append(notExistTags, model.Tags{
+			Name:        tag.Name,
+			Uid:         model.GenUid(),
+			Description: tag.Description,
+			Color:       tag.Color,
+			Icon:        tag.Icon,
+			ParentUid:   tag.ParentUid,
+		})
 	}
 	// 批量创建标签
 	if len(notExistTags) > 0 {
@@ -64,16 +93,12 @@ func (l *CreateBatchTagLogic) CreateBatchTag(req *types.CreateBatchTagRequest) (
 			return nil, err
 		}
 	}
-	// 查询添加的标签
+	// 查询添加的标签, 按请求顺序返回, 指向同一个标签的名称只返回一次
 	addedTags, err := l.svcCtx.TagsModel.FindBatchByNames(l.ctx, tagNames)
 	if err != nil {
 		return nil, err
 	}
-
-	for _, tag := range addedTags {
-		tagNames = append(tagNames, tag.Name)
-	}
-	createTagResponses := make([]types.CreateTagResponse, len(req.Tags))
+	createTagResponses := make([]types.CreateTagResponse, len(addedTags))
 	for i, tag := range addedTags {
 		createTagResponses[i] = types.CreateTagResponse{
 			Id:          tag.ID,
@@ -82,6 +107,7 @@ func (l *CreateBatchTagLogic) CreateBatchTag(req *types.CreateBatchTagRequest) (
 			Description: tag.Description,
 			Color:       tag.Color,
 			Icon:        tag.Icon,
+			ParentUid:   tag.ParentUid,
 			CreatedAt:   tag.CreatedAt.Format(time.DateTime),
 			UpdatedAt:   tag.UpdatedAt.Format(time.DateTime),
 		}
@@ -89,7 +115,7 @@ func (l *CreateBatchTagLogic) CreateBatchTag(req *types.CreateBatchTagRequest) (
 	// 返回结果
 	resp = &types.CreateBatchTagResponse{
 		CreatedTotal:       int64(len(notExistTags)),
-		ExistedTotal:       int64(len(existTags)),
+		ExistedTotal:       int64(len(existTotal)),
 		CreateTagResponses: createTagResponses,
 	}
 	return resp, nil
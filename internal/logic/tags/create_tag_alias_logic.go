@@ -0,0 +1,80 @@
+package tags
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/tagname"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type CreateTagAliasLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 添加标签别名
+func NewCreateTagAliasLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateTagAliasLogic {
+	return &CreateTagAliasLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateTagAliasLogic) CreateTagAlias(req *types.CreateTagAliasRequest) (resp *types.CreateTagAliasResponse, err error) {
+	tag, err := l.svcCtx.TagsModel.GetUid(l.ctx, req.TagUid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("标签不存在")
+	}
+	if err != nil {
+		return nil, errors.New("查询标签失败")
+	}
+	// 规范化后相同的别名只保留第一个
+	var names []string
+	keys := make(map[string]bool)
+	for _, name := range req.Aliases {
+		name = strings.Join(strings.Fields(name), " ")
+		if key := tagname.Key(name); key != "" && !keys[key] {
+			keys[key] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.New("缺少别名")
+	}
+	// 与已有名称或别名重复的别名不会生效, 同时添加的别名由唯一索引兜底
+	exists, err := l.svcCtx.TagsModel.MatchNames(l.ctx, names)
+	if err != nil {
+		return nil, errors.New("查询标签失败")
+	}
+	for _, name := range names {
+		if existing, ok := exists[name]; ok {
+			return nil, fmt.Errorf("别名 %s 与标签 %s 的名称或别名重复", name, existing.Name)
+		}
+	}
+	aliases := make([]*model.TagAliases, len(names))
+	for i, name := range names {
+		aliases[i] = &model.TagAliases{Alias: name, TagUid: tag.Uid}
+	}
+	err = l.svcCtx.TagAliasesModel.CreateBatch(l.ctx, aliases)
+	if errors.Is(err, model.ErrTagAliasExists) {
+		return nil, errors.New("别名与已有别名重复")
+	}
+	if err != nil {
+		return nil, errors.New("添加标签别名失败")
+	}
+	list, err := tagAliasResponses(l.ctx, l.svcCtx, aliases)
+	if err != nil {
+		return nil, errors.New("查询标签失败")
+	}
+	return &types.CreateTagAliasResponse{List: list}, nil
+}
@@ -2,6 +2,7 @@ package tags
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"time"
 
@@ -29,20 +30,26 @@ func NewCreateTagLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateT
 
 func (l *CreateTagLogic) CreateTag(req *types.CreateTagRequest) (resp *types.CreateTagResponse, err error) {
 	// 查找是否存在
-	tag, err := l.svcCtx.TagsModel.GetName(l.ctx, req.Name)
-	if err != nil {
+	_, err = l.svcCtx.TagsModel.GetName(l.ctx, req.Name)
+	if err == nil {
+		return nil, errors.New("标签已经存在")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
 		return nil, errors.New("查询标签失败")
 	}
-	if tag != nil {
-		return nil, errors.New("标签已经存在")
+	if req.ParentUid != "" {
+		if _, err := l.svcCtx.TagsModel.GetUid(l.ctx, req.ParentUid); err != nil {
+			return nil, errors.New("父标签不存在")
+		}
 	}
 	uid := model.GenUid()
-	tag = &model.Tags{
+	tag := &model.Tags{
 		Uid:         uid,
 		Name:        req.Name,
 		Description: req.Description,
 		Color:       req.Color,
 		Icon:        req.Icon,
+		ParentUid:   req.ParentUid,
 	}
 	err = l.svcCtx.TagsModel.Create(l.ctx, tag)
 	if err != nil {
@@ -56,6 +63,7 @@ func (l *CreateTagLogic) CreateTag(req *types.CreateTagRequest) (resp *types.Cre
 		Description: tag.Description,
 		Color:       tag.Color,
 		Icon:        tag.Icon,
+		ParentUid:   tag.ParentUid,
 		CreatedAt:   tag.CreatedAt.Format(time.DateTime),
 		UpdatedAt:   tag.UpdatedAt.Format(time.DateTime),
 	}
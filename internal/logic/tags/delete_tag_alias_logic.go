@@ -0,0 +1,41 @@
+package tags
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type DeleteTagAliasLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 删除标签别名
+func NewDeleteTagAliasLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteTagAliasLogic {
+	return &DeleteTagAliasLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteTagAliasLogic) DeleteTagAlias(req *types.DeleteTagAliasRequest) (resp *types.DeleteTagAliasResponse, err error) {
+	alias, err := l.svcCtx.TagAliasesModel.Get(l.ctx, req.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("别名不存在")
+	}
+	if err != nil {
+		return nil, errors.New("查询标签别名失败")
+	}
+	if err := l.svcCtx.TagAliasesModel.Delete(l.ctx, alias.ID); err != nil {
+		return nil, errors.New("删除标签别名失败")
+	}
+	return &types.DeleteTagAliasResponse{Result: "删除标签别名成功"}, nil
+}
@@ -3,9 +3,11 @@ package tags
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/XXueTu/wise/internal/model"
 	"github.com/XXueTu/wise/internal/svc"
 	"github.com/XXueTu/wise/internal/types"
 )
@@ -26,20 +28,38 @@ func NewDeleteTagLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteT
 }
 
 func (l *DeleteTagLogic) DeleteTag(req *types.DeleteTagRequest) (resp *types.DeleteTagResponse, err error) {
-	// 查找是否存在
-	tag, err := l.svcCtx.TagsModel.GetUid(l.ctx, req.Uid)
-	if err != nil {
-		return nil, errors.New("查询标签失败")
+	if req.Strategy == model.TagDeleteReassign && req.TargetUid == "" {
+		return nil, errors.New("缺少目标标签")
 	}
-	if tag == nil {
-		return nil, errors.New("标签不存在")
+	var refs *model.TagReferences
+	if req.DryRun {
+		refs, err = l.svcCtx.TagsModel.References(l.ctx, req.Uid)
+	} else {
+		refs, err = l.svcCtx.TagsModel.Remove(l.ctx, req.Uid, req.Strategy, req.TargetUid)
 	}
-	err = l.svcCtx.TagsModel.Delete(l.ctx, tag.ID)
-	if err != nil {
+	switch {
+	case errors.Is(err, model.ErrTagNotFound):
+		return nil, errors.New("标签不存在")
+	case errors.Is(err, model.ErrTagProtected):
+		return nil, errors.New("默认标签不能删除")
+	case errors.Is(err, model.ErrTagInUse):
+		return nil, fmt.Errorf("标签被 %d 个规则引用, 请先修改或删除规则", refs.Rules)
+	case errors.Is(err, model.ErrTagReferenced):
+		return nil, fmt.Errorf("标签被 %d 个资源引用, 请选择 detach 或 reassign_to 策略", refs.Resources)
+	case errors.Is(err, model.ErrTagReassignTarget):
+		return nil, errors.New("目标标签不存在或与删除的标签相同")
+	case err != nil:
 		return nil, errors.New("删除标签失败")
 	}
 	resp = &types.DeleteTagResponse{
-		Result: "删除标签成功",
+		Result:    "删除标签成功",
+		Resources: refs.Resources,
+		Children:  refs.Children,
+		Rules:     refs.Rules,
+		Deleted:   !req.DryRun,
+	}
+	if req.DryRun {
+		resp.Result = "查询标签引用成功"
 	}
 	return
 }
@@ -3,7 +3,6 @@ package tags
 import (
 	"context"
 	"errors"
-	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
@@ -35,14 +34,14 @@ func (l *GetTagLogic) GetTag(req *types.GetTagRequest) (resp *types.TagResponse,
 	if tag == nil {
 		return nil, errors.New("标签不存在")
 	}
-	resp = &types.TagResponse{
-		Uid:         tag.Uid,
-		Name:        tag.Name,
-		Description: tag.Description,
-		Color:       tag.Color,
-		Icon:        tag.Icon,
-		CreatedAt:   tag.CreatedAt.Format(time.DateTime),
-		UpdatedAt:   tag.UpdatedAt.Format(time.DateTime),
+	ancestors, err := l.svcCtx.TagsModel.GetAncestors(l.ctx, tag.Uid)
+	if err != nil {
+		return nil, errors.New("查询父标签失败")
+	}
+	tagResp := tagResponse(tag)
+	resp = &tagResp
+	for _, ancestor := range ancestors {
+		resp.Ancestors = append(resp.Ancestors, tagResponse(ancestor))
 	}
 	return
 }
@@ -0,0 +1,80 @@
+package tags
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type ListTagAliasLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取标签别名
+func NewListTagAliasLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListTagAliasLogic {
+	return &ListTagAliasLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListTagAliasLogic) ListTagAlias(req *types.ListTagAliasRequest) (resp *types.ListTagAliasResponse, err error) {
+	var aliases []*model.TagAliases
+	if req.TagUid != "" {
+		aliases, err = l.svcCtx.TagAliasesModel.GetByTag(l.ctx, req.TagUid)
+	} else {
+		aliases, err = l.svcCtx.TagAliasesModel.GetAll(l.ctx)
+	}
+	if err != nil {
+		l.Errorf("ListTagAlias tagUid: %s, error: %v", req.TagUid, err)
+		return nil, errors.New("查询标签别名失败")
+	}
+	list, err := tagAliasResponses(l.ctx, l.svcCtx, aliases)
+	if err != nil {
+		return nil, errors.New("查询标签失败")
+	}
+	return &types.ListTagAliasResponse{List: list}, nil
+}
+
+// tagAliasResponses 转换别名并补充标签名称, 标签已删除的别名不返回
+func tagAliasResponses(ctx context.Context, svcCtx *svc.ServiceContext, aliases []*model.TagAliases) ([]types.TagAlias, error) {
+	uids := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		uids = append(uids, alias.TagUid)
+	}
+	list := make([]types.TagAlias, 0, len(aliases))
+	if len(uids) == 0 {
+		return list, nil
+	}
+	tags, err := svcCtx.TagsModel.GetUids(ctx, uids)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		names[tag.Uid] = tag.Name
+	}
+	for _, alias := range aliases {
+		name, ok := names[alias.TagUid]
+		if !ok {
+			continue
+		}
+		list = append(list, types.TagAlias{
+			Id:        alias.ID,
+			Alias:     alias.Alias,
+			TagUid:    alias.TagUid,
+			TagName:   name,
+			CreatedAt: alias.CreatedAt.Format(time.DateTime),
+		})
+	}
+	return list, nil
+}
@@ -3,9 +3,12 @@ package tags
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/XXueTu/wise/internal/model"
 	"github.com/XXueTu/wise/internal/svc"
 	"github.com/XXueTu/wise/internal/types"
 )
@@ -26,19 +29,16 @@ func NewListTagLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListTagLo
 }
 
 func (l *ListTagLogic) ListTag(req *types.ListTagRequest) (resp *types.ListTagResponse, err error) {
+	if req.Tree {
+		return l.listTree(req.Name)
+	}
 	tagList, err := l.svcCtx.TagsModel.GetList(l.ctx, req.Page, req.PageSize, req.Name)
 	if err != nil {
 		return nil, errors.New("查询标签失败")
 	}
 	var list []types.TagResponse
 	for _, tag := range tagList.List {
-		list = append(list, types.TagResponse{
-			Uid:         tag.Uid,
-			Name:        tag.Name,
-			Description: tag.Description,
-			Color:       tag.Color,
-			Icon:        tag.Icon,
-		})
+		list = append(list, tagResponse(tag))
 	}
 	resp = &types.ListTagResponse{
 		Total: tagList.Total,
@@ -46,3 +46,82 @@ func (l *ListTagLogic) ListTag(req *types.ListTagRequest) (resp *types.ListTagRe
 	}
 	return
 }
+
+// listTree 返回全部标签的树形结构, 按名称筛选时保留命中的标签及其祖先
+// 父标签不存在的标签作为根标签
+func (l *ListTagLogic) listTree(name string) (*types.ListTagResponse, error) {
+	tags, err := l.svcCtx.TagsModel.GetAll(l.ctx)
+	if err != nil {
+		return nil, errors.New("查询标签失败")
+	}
+	byUid := make(map[string]*model.Tags, len(tags))
+	for _, tag := range tags {
+		byUid[tag.Uid] = tag
+	}
+	children := make(map[string][]*model.Tags)
+	var roots []*model.Tags
+	for _, tag := range tags {
+		if _, ok := byUid[tag.ParentUid]; ok && tag.ParentUid != tag.Uid {
+			children[tag.ParentUid] = append(children[tag.ParentUid], tag)
+		} else {
+			roots = append(roots, tag)
+		}
+	}
+
+	keyword := strings.ToLower(name)
+	visited := make(map[string]bool, len(tags))
+	var build func(tag *model.Tags) (types.TagResponse, bool)
+	build = func(tag *model.Tags) (types.TagResponse, bool) {
+		visited[tag.Uid] = true
+		node := tagResponse(tag)
+		matched := strings.Contains(strings.ToLower(tag.Name), keyword)
+		for _, child := range children[tag.Uid] {
+			if visited[child.Uid] {
+				continue
+			}
+			if childNode, ok := build(child); ok {
+				node.Children = append(node.Children, childNode)
+				matched = true
+			}
+		}
+		return node, matched
+	}
+	resp := &types.ListTagResponse{Tree: make([]types.TagResponse, 0)}
+	for _, root := range roots {
+		if node, ok := build(root); ok {
+			resp.Tree = append(resp.Tree, node)
+		}
+	}
+	// 历史数据中成环的标签没有根标签, 同样作为根标签返回
+	for _, tag := range tags {
+		if visited[tag.Uid] {
+			continue
+		}
+		if node, ok := build(tag); ok {
+			resp.Tree = append(resp.Tree, node)
+		}
+	}
+	resp.Total = int64(countNodes(resp.Tree))
+	return resp, nil
+}
+
+func countNodes(nodes []types.TagResponse) int {
+	count := len(nodes)
+	for _, node := range nodes {
+		count += countNodes(node.Children)
+	}
+	return count
+}
+
+func tagResponse(tag *model.Tags) types.TagResponse {
+	return types.TagResponse{
+		Uid:         tag.Uid,
+		Name:        tag.Name,
+		Description: tag.Description,
+		Color:       tag.Color,
+		Icon:        tag.Icon,
+		ParentUid:   tag.ParentUid,
+		CreatedAt:   tag.CreatedAt.Format(time.DateTime),
+		UpdatedAt:   tag.UpdatedAt.Format(time.DateTime),
+	}
+}
@@ -0,0 +1,62 @@
+package tags
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type MergeTagLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 合并标签
+func NewMergeTagLogic(ctx context.Context, svcCtx *svc.ServiceContext) *MergeTagLogic {
+	return &MergeTagLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *MergeTagLogic) MergeTag(req *types.MergeTagRequest) (resp *types.MergeTagResponse, err error) {
+	if req.TargetUid == "" || len(req.SourceUids) == 0 {
+		return nil, errors.New("缺少目标标签或被合并的标签")
+	}
+	result, err := l.svcCtx.TagsModel.Merge(l.ctx, req.TargetUid, req.SourceUids)
+	switch {
+	case errors.Is(err, model.ErrTagNotFound):
+		return nil, errors.New("标签不存在")
+	case errors.Is(err, model.ErrTagMergeTarget):
+		return nil, errors.New("目标标签不能是被合并的标签或其子标签")
+	case errors.Is(err, model.ErrTagProtected):
+		return nil, errors.New("默认标签不能被合并")
+	case err != nil:
+		return nil, errors.New("合并标签失败")
+	}
+	target, err := l.svcCtx.TagsModel.GetUid(l.ctx, req.TargetUid)
+	if err != nil {
+		return nil, errors.New("查询标签失败")
+	}
+	aliases, err := l.svcCtx.TagAliasesModel.GetByTag(l.ctx, req.TargetUid)
+	if err != nil {
+		return nil, errors.New("查询标签别名失败")
+	}
+	resp = &types.MergeTagResponse{
+		Target:    tagResponse(target),
+		Merged:    result.Merged,
+		Resources: result.Resources,
+		Aliases:   make([]string, 0, len(aliases)),
+	}
+	for _, alias := range aliases {
+		resp.Aliases = append(resp.Aliases, alias.Alias)
+	}
+	return resp, nil
+}
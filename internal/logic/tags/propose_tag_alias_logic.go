@@ -0,0 +1,62 @@
+package tags
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+// 返回的分组数上限
+const maxAliasProposals = 500
+
+type ProposeTagAliasLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 按名称聚类建议合并为别名的标签, 确认后调用合并标签
+func NewProposeTagAliasLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ProposeTagAliasLogic {
+	return &ProposeTagAliasLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ProposeTagAliasLogic) ProposeTagAlias(req *types.ProposeTagAliasRequest) (resp *types.ProposeTagAliasResponse, err error) {
+	limit := int(min(max(req.Limit, 1), maxAliasProposals))
+	usage, err := l.svcCtx.TagStats.Usage(l.ctx)
+	if err != nil {
+		l.Errorf("ProposeTagAlias Usage error: %v", err)
+		return nil, errors.New("查询标签失败")
+	}
+	groups, err := l.svcCtx.TagResolver.ProposeAliases(l.ctx, usage)
+	if err != nil {
+		l.Errorf("ProposeTagAlias ProposeAliases tags: %d, error: %v", len(usage), err)
+		return nil, errors.New("生成别名建议失败")
+	}
+	resp = &types.ProposeTagAliasResponse{
+		Total: int64(len(groups)),
+		List:  make([]types.TagAliasGroup, 0, min(limit, len(groups))),
+	}
+	for _, group := range groups[:min(limit, len(groups))] {
+		item := types.TagAliasGroup{Target: tagUsage(group.Target.Tag, group.Target.Count)}
+		for _, member := range group.Members {
+			item.Members = append(item.Members, types.TagAliasMember{
+				Uid:        member.Tag.Uid,
+				Name:       member.Tag.Name,
+				Color:      member.Tag.Color,
+				Count:      member.Count,
+				Method:     member.Method,
+				Similarity: member.Similarity,
+			})
+		}
+		resp.List = append(resp.List, item)
+	}
+	return resp, nil
+}
@@ -0,0 +1,158 @@
+package tags
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/tagging"
+	"github.com/XXueTu/wise/internal/tagname"
+	"github.com/XXueTu/wise/internal/task"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+const (
+	splitModeRules = "rules"
+	splitModeLLM   = "llm"
+)
+
+type SplitTagLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 拆分标签
+func NewSplitTagLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SplitTagLogic {
+	return &SplitTagLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SplitTagLogic) SplitTag(req *types.SplitTagRequest) (resp *types.SplitTagResponse, err error) {
+	if len(req.Targets) == 0 {
+		return nil, errors.New("缺少拆分后的标签")
+	}
+	source, err := l.svcCtx.TagsModel.GetUid(l.ctx, req.Uid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("标签不存在")
+	}
+	if err != nil {
+		return nil, errors.New("查询标签失败")
+	}
+	// 保留原标签时新标签作为其子标签, 否则与原标签同级
+	parentUid := source.ParentUid
+	if req.KeepSource {
+		parentUid = source.Uid
+	}
+	targets, err := l.ensureTargets(req.Targets, parentUid)
+	if err != nil {
+		return nil, err
+	}
+	resp = &types.SplitTagResponse{
+		Mode:    req.Mode,
+		Targets: make([]types.TagResponse, 0, len(targets)),
+	}
+	targetUids := make([]string, len(targets))
+	for i, target := range targets {
+		if target.Uid == source.Uid {
+			return nil, errors.New("拆分后的标签不能是原标签")
+		}
+		targetUids[i] = target.Uid
+		resp.Targets = append(resp.Targets, tagResponse(target))
+	}
+
+	if req.Mode == splitModeLLM {
+		params, _ := json.Marshal(task.TagSplitParams{Uid: source.Uid, TargetUids: targetUids, RemoveSource: !req.KeepSource})
+		t, err := task.CreateTask(l.ctx, l.svcCtx, string(params), "拆分标签", task.TypeTagSplit, model.TaskPriorityNormal)
+		if err != nil {
+			l.Errorf("SplitTag CreateTask uid: %s, error: %v", source.Uid, err)
+			return nil, errors.New("创建拆分任务失败")
+		}
+		resp.Tid = t.Tid
+		return resp, nil
+	}
+
+	resources, err := l.svcCtx.ResourceModel.GetByTag(l.ctx, source.Uid)
+	if err != nil {
+		return nil, errors.New("查询资源失败")
+	}
+	rules := make([]tagging.SplitTarget, len(req.Targets))
+	for i, target := range req.Targets {
+		rules[i] = tagging.SplitTarget{Uid: targetUids[i], ResourceIDs: target.ResourceIds, Keywords: target.Keywords}
+	}
+	result, err := l.svcCtx.TagsModel.Split(l.ctx, source.Uid, tagging.PlanSplit(resources, rules), !req.KeepSource)
+	if err != nil {
+		return nil, errors.New("拆分标签失败")
+	}
+	resp.Assigned = result.Assigned
+	resp.Remaining = result.Remaining
+	resp.SourceDeleted = result.SourceDeleted
+	return resp, nil
+}
+
+// ensureTargets 按顺序返回拆分后的标签, 指定唯一标识的标签需要存在, 按名称指定的标签不存在时创建
+func (l *SplitTagLogic) ensureTargets(targets []types.SplitTagTarget, parentUid string) ([]*model.Tags, error) {
+	var names []string
+	for _, target := range targets {
+		if target.Uid == "" {
+			if target.Name == "" {
+				return nil, errors.New("拆分后的标签缺少唯一标识或名称")
+			}
+			names = append(names, target.Name)
+		}
+	}
+	byName := make(map[string]*model.Tags)
+	if len(names) > 0 {
+		exists, err := l.svcCtx.TagsModel.MatchNames(l.ctx, names)
+		if err != nil {
+			return nil, errors.New("查询标签失败")
+		}
+		// 规范化后相同的名称只创建一个标签
+		created := make(map[string]int)
+		var newTags []model.Tags
+		for _, name := range names {
+			if tag, ok := exists[name]; ok {
+				byName[name] = tag
+				continue
+			}
+			if _, ok := created[tagname.Key(name)]; ok {
+				continue
+			}
+			created[tagname.Key(name)] = len(newTags)
+			newTags = append(newTags, model.Tags{Uid: model.GenUid(), Name: name, Description: "拆分", ParentUid: parentUid})
+		}
+		if len(newTags) > 0 {
+			if err := l.svcCtx.TagsModel.CreateBatch(l.ctx, newTags); err != nil {
+				l.Errorf("SplitTag CreateBatch tags: %d, error: %v", len(newTags), err)
+				return nil, errors.New("创建标签失败")
+			}
+		}
+		for _, name := range names {
+			if i, ok := created[tagname.Key(name)]; ok {
+				byName[name] = &newTags[i]
+			}
+		}
+	}
+
+	result := make([]*model.Tags, len(targets))
+	for i, target := range targets {
+		if target.Uid == "" {
+			result[i] = byName[target.Name]
+			continue
+		}
+		tag, err := l.svcCtx.TagsModel.GetUid(l.ctx, target.Uid)
+		if err != nil {
+			return nil, errors.New("拆分后的标签不存在")
+		}
+		result[i] = tag
+	}
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package tags
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+// 标签云最多返回的标签数
+const maxCloudLimit = 500
+
+type TagCloudLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 标签云
+func NewTagCloudLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TagCloudLogic {
+	return &TagCloudLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *TagCloudLogic) TagCloud(req *types.TagCloudRequest) (resp *types.TagCloudResponse, err error) {
+	limit := int(min(max(req.Limit, 1), maxCloudLimit))
+	usage, err := l.svcCtx.TagStats.Usage(l.ctx)
+	if err != nil {
+		l.Errorf("TagCloud Usage limit: %d, error: %v", limit, err)
+		return nil, errors.New("获取标签云失败")
+	}
+	resp = &types.TagCloudResponse{List: make([]types.TagCloudItem, 0, limit)}
+	// 按使用次数降序, 第一个为最大值, 最后一个为最小值
+	for _, u := range usage {
+		if u.Count == 0 || len(resp.List) >= limit {
+			break
+		}
+		resp.List = append(resp.List, types.TagCloudItem{
+			Uid:   u.Tag.Uid,
+			Name:  u.Tag.Name,
+			Color: u.Tag.Color,
+			Icon:  u.Tag.Icon,
+			Count: u.Count,
+		})
+	}
+	if len(resp.List) == 0 {
+		return resp, nil
+	}
+	maxCount, minCount := resp.List[0].Count, resp.List[len(resp.List)-1].Count
+	for i := range resp.List {
+		resp.List[i].Weight = cloudWeight(resp.List[i].Count, minCount, maxCount)
+	}
+	return resp, nil
+}
+
+// cloudWeight 按使用次数的对数归一化到 0 到 1, 次数都相同时为 1
+func cloudWeight(count, minCount, maxCount int64) float64 {
+	if maxCount == minCount {
+		return 1
+	}
+	weight := (math.Log(float64(count)) - math.Log(float64(minCount))) / (math.Log(float64(maxCount)) - math.Log(float64(minCount)))
+	return math.Round(weight*10000) / 10000
+}
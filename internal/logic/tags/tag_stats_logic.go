@@ -0,0 +1,153 @@
+package tags
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+const (
+	// 默认统计最近 30 天的增长
+	defaultGrowthRange = 30 * 24 * time.Hour
+	// 返回的标签数和标签对数上限
+	maxStatsTop = 100
+)
+
+type TagStatsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 标签使用统计
+func NewTagStatsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TagStatsLogic {
+	return &TagStatsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *TagStatsLogic) TagStats(req *types.TagStatsRequest) (resp *types.TagStatsResponse, err error) {
+	start, end, err := parseGrowthRange(req.Start, req.End)
+	if err != nil {
+		return nil, err
+	}
+	top := int(min(max(req.Top, 1), maxStatsTop))
+
+	usage, err := l.svcCtx.TagStats.Usage(l.ctx)
+	if err != nil {
+		l.Errorf("TagStats Usage error: %v", err)
+		return nil, errors.New("获取标签统计失败")
+	}
+	resp = &types.TagStatsResponse{
+		Start:     start.Format(time.DateTime),
+		End:       end.Format(time.DateTime),
+		Period:    req.Period,
+		TotalTags: int64(len(usage)),
+		Top:       make([]types.TagUsage, 0, top),
+		Growth:    make([]types.TagGrowth, 0),
+		Pairs:     make([]types.TagPair, 0, top),
+		Orphans:   make([]types.TagResponse, 0),
+	}
+	byUid := make(map[string]*model.Tags, len(usage))
+	for _, u := range usage {
+		byUid[u.Tag.Uid] = u.Tag
+		if u.Count == 0 {
+			continue
+		}
+		resp.UsedTags++
+		resp.TotalLinks += u.Count
+		if len(resp.Top) < top {
+			resp.Top = append(resp.Top, tagUsage(u.Tag, u.Count))
+		}
+	}
+
+	counts, err := l.svcCtx.TagStats.Growth(l.ctx, start, end, req.Period)
+	if err != nil {
+		l.Errorf("TagStats Growth start: %s, end: %s, error: %v", req.Start, req.End, err)
+		return nil, errors.New("获取标签统计失败")
+	}
+	// 按日期升序, 同一日期内按数量降序
+	for _, count := range counts {
+		tag, ok := byUid[count.TagUid]
+		if !ok {
+			continue
+		}
+		if n := len(resp.Growth); n == 0 || resp.Growth[n-1].Period != count.Period {
+			resp.Growth = append(resp.Growth, types.TagGrowth{Period: count.Period, Tags: make([]types.TagUsage, 0)})
+		}
+		growth := &resp.Growth[len(resp.Growth)-1]
+		growth.Count += count.Count
+		if len(growth.Tags) < top {
+			growth.Tags = append(growth.Tags, tagUsage(tag, count.Count))
+		}
+	}
+
+	pairs, err := l.svcCtx.TagStats.Pairs(l.ctx, top)
+	if err != nil {
+		l.Errorf("TagStats Pairs top: %d, error: %v", top, err)
+		return nil, errors.New("获取标签统计失败")
+	}
+	for _, pair := range pairs {
+		tag, other := byUid[pair.TagUid], byUid[pair.OtherUid]
+		if tag == nil || other == nil {
+			continue
+		}
+		resp.Pairs = append(resp.Pairs, types.TagPair{
+			Uid:       tag.Uid,
+			Name:      tag.Name,
+			OtherUid:  other.Uid,
+			OtherName: other.Name,
+			Count:     pair.Count,
+		})
+	}
+
+	orphans, err := l.svcCtx.TagStats.Orphans(l.ctx)
+	if err != nil {
+		l.Errorf("TagStats Orphans error: %v", err)
+		return nil, errors.New("获取标签统计失败")
+	}
+	for _, tag := range orphans {
+		resp.Orphans = append(resp.Orphans, tagResponse(tag))
+	}
+	return resp, nil
+}
+
+func tagUsage(tag *model.Tags, count int64) types.TagUsage {
+	return types.TagUsage{
+		Uid:   tag.Uid,
+		Name:  tag.Name,
+		Color: tag.Color,
+		Count: count,
+	}
+}
+
+// parseGrowthRange 解析增长统计的时间范围, 默认最近 30 天
+// 默认结束时间取下一分钟, 同一分钟内的请求可以使用缓存
+func parseGrowthRange(startStr, endStr string) (start time.Time, end time.Time, err error) {
+	end = time.Now().Truncate(time.Minute).Add(time.Minute)
+	if endStr != "" {
+		end, err = time.ParseInLocation(time.DateTime, endStr, time.Local)
+		if err != nil {
+			return start, end, errors.New("结束时间格式错误")
+		}
+	}
+	start = end.Add(-defaultGrowthRange)
+	if startStr != "" {
+		start, err = time.ParseInLocation(time.DateTime, startStr, time.Local)
+		if err != nil {
+			return start, end, errors.New("开始时间格式错误")
+		}
+	}
+	if !start.Before(end) {
+		return start, end, errors.New("开始时间需早于结束时间")
+	}
+	return start, end, nil
+}
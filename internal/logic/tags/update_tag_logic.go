@@ -6,6 +6,7 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/XXueTu/wise/internal/model"
 	"github.com/XXueTu/wise/internal/svc"
 	"github.com/XXueTu/wise/internal/types"
 )
@@ -34,6 +35,22 @@ func (l *UpdateTagLogic) UpdateTag(req *types.UpdateTagRequest) (resp *types.Upd
 	if tag == nil {
 		return nil, errors.New("标签不存在")
 	}
+	if req.ParentUid != tag.ParentUid {
+		err = l.svcCtx.TagsModel.SetParent(l.ctx, tag.Uid, req.ParentUid)
+		switch {
+		case errors.Is(err, model.ErrTagParentNotFound):
+			return nil, errors.New("父标签不存在")
+		case errors.Is(err, model.ErrTagCycle):
+			return nil, errors.New("不能移动到自身或子标签下")
+		case err != nil:
+			return nil, errors.New("更新标签失败")
+		}
+		tag.ParentUid = req.ParentUid
+	}
+	if req.Name != tag.Name {
+		// 名称向量在下次自动标注时重新生成
+		tag.Embedding = nil
+	}
 	tag.Name = req.Name
 	tag.Description = req.Description
 	tag.Color = req.Color
@@ -48,6 +65,7 @@ func (l *UpdateTagLogic) UpdateTag(req *types.UpdateTagRequest) (resp *types.Upd
 		Description: tag.Description,
 		Color:       tag.Color,
 		Icon:        tag.Icon,
+		ParentUid:   tag.ParentUid,
 	}
 	return
 }
@@ -2,10 +2,13 @@ package tasks
 
 import (
 	"context"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/XXueTu/wise/internal/model"
 	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/task"
 	"github.com/XXueTu/wise/internal/types"
 )
 
@@ -25,6 +28,29 @@ func NewCreateTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Create
 }
 
 func (l *CreateTaskLogic) CreateTask(req *types.CreateTaskRequest) (resp *types.CreateTaskResponse, err error) {
-
-	return
+	// 优先级限制在低到高的范围内, 超出范围的按最近的边界处理
+	priority := min(max(req.Priority, model.TaskPriorityLow), model.TaskPriorityHigh)
+	t, err := task.CreateTask(l.ctx, l.svcCtx, req.Params, req.Name, req.Types, priority)
+	if err != nil {
+		return nil, err
+	}
+	resp = &types.CreateTaskResponse{
+		Id:           t.ID,
+		Tid:          t.Tid,
+		Name:         t.Name,
+		Types:        t.Types,
+		Status:       t.Status,
+		CurrentState: t.CurrentState,
+		TotalSteps:   t.TotalSteps,
+		CurrentStep:  t.CurrentStep,
+		RetryCount:   t.RetryCount,
+		Params:       t.Params,
+		Result:       t.Result,
+		Duration:     t.Duration,
+		Error:        t.Error,
+		Extend:       t.Extend,
+		CreatedAt:    t.CreatedAt.Format(time.DateTime),
+		UpdatedAt:    t.UpdatedAt.Format(time.DateTime),
+	}
+	return resp, nil
 }
@@ -31,6 +31,10 @@ func (l *GetTaskLogic) GetTask(req *types.GetTaskRequest) (resp *types.TaskRespo
 	if err != nil {
 		return nil, errors.New("任务不存在")
 	}
+	nextRunAt := ""
+	if !task.NextRunAt.IsZero() {
+		nextRunAt = task.NextRunAt.Format(time.DateTime)
+	}
 	resp = &types.TaskResponse{
 		Tid:          task.Tid,
 		Name:         task.Name,
@@ -42,8 +46,11 @@ func (l *GetTaskLogic) GetTask(req *types.GetTaskRequest) (resp *types.TaskRespo
 		CurrentStep:  task.CurrentStep,
 		RetryCount:   task.RetryCount,
 		Result:       task.Result,
+		Duration:     task.Duration,
 		Error:        task.Error,
 		Extend:       task.Extend,
+		NextRunAt:    nextRunAt,
+		Priority:     task.Priority,
 		CreatedAt:    task.CreatedAt.Format(time.DateTime),
 		UpdatedAt:    task.UpdatedAt.Format(time.DateTime),
 	}
@@ -3,6 +3,7 @@ package tasks
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -36,18 +37,30 @@ func (l *GetTaskVisualizationLogic) GetTaskVisualization(req *types.GetTaskVisua
 		return nil, errors.New("获取任务计划失败")
 	}
 	taskPlanDetails := make([]types.TaskPlanDetail, 0)
+	edges := make([]types.TaskPlanEdge, 0)
 	for _, plan := range plans {
+		for _, beforePid := range strings.Split(plan.BeforePid, ",") {
+			if beforePid != "" {
+				edges = append(edges, types.TaskPlanEdge{From: beforePid, To: plan.Pid})
+			}
+		}
 		taskPlanDetails = append(taskPlanDetails, types.TaskPlanDetail{
-			Pid:       plan.Pid,
-			Name:      plan.Name,
-			Index:     plan.Index,
-			Status:    plan.Status,
-			Params:    plan.Params,
-			Result:    plan.Result,
-			Duration:  plan.Duration,
-			Error:     plan.Error,
-			CreatedAt: plan.CreatedAt.Format(time.DateTime),
-			UpdatedAt: plan.UpdatedAt.Format(time.DateTime),
+			Pid:              plan.Pid,
+			Name:             plan.Name,
+			BeforePid:        plan.BeforePid,
+			Next:             plan.Next,
+			Index:            plan.Index,
+			Status:           plan.Status,
+			Params:           plan.Params,
+			Result:           plan.Result,
+			Duration:         plan.Duration,
+			Error:            plan.Error,
+			StartedAt:        formatTime(plan.StartedAt),
+			EndedAt:          formatTime(plan.EndedAt),
+			PromptTokens:     plan.PromptTokens,
+			CompletionTokens: plan.CompletionTokens,
+			CreatedAt:        plan.CreatedAt.Format(time.DateTime),
+			UpdatedAt:        plan.UpdatedAt.Format(time.DateTime),
 		})
 	}
 	resp = &types.TaskVisualizationResponse{
@@ -59,8 +72,17 @@ func (l *GetTaskVisualizationLogic) GetTaskVisualization(req *types.GetTaskVisua
 		TotalSteps:   task.TotalSteps,
 		CurrentStep:  task.CurrentStep,
 		Plans:        taskPlanDetails,
+		Edges:        edges,
 		CreatedAt:    task.CreatedAt.Format(time.DateTime),
 		UpdatedAt:    task.UpdatedAt.Format(time.DateTime),
 	}
 	return resp, nil
 }
+
+// formatTime 格式化时间, 零值返回空字符串
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateTime)
+}
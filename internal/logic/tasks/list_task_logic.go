@@ -33,6 +33,10 @@ func (l *ListTaskLogic) ListTask(req *types.ListTaskRequest) (resp *types.ListTa
 	}
 	response := make([]types.TaskResponse, 0)
 	for _, task := range taskList.List {
+		nextRunAt := ""
+		if !task.NextRunAt.IsZero() {
+			nextRunAt = task.NextRunAt.Format(time.DateTime)
+		}
 		response = append(response, types.TaskResponse{
 			Tid:          task.Tid,
 			Name:         task.Name,
@@ -44,8 +48,11 @@ func (l *ListTaskLogic) ListTask(req *types.ListTaskRequest) (resp *types.ListTa
 			RetryCount:   task.RetryCount,
 			Params:       task.Params,
 			Result:       task.Result,
+			Duration:     task.Duration,
 			Error:        task.Error,
 			Extend:       task.Extend,
+			NextRunAt:    nextRunAt,
+			Priority:     task.Priority,
 			CreatedAt:    task.CreatedAt.Format(time.DateTime),
 			UpdatedAt:    task.UpdatedAt.Format(time.DateTime),
 		})
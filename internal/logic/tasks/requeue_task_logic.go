@@ -0,0 +1,52 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type RequeueTaskLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 死信任务重新入队
+func NewRequeueTaskLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RequeueTaskLogic {
+	return &RequeueTaskLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RequeueTaskLogic) RequeueTask(req *types.RequeueTaskRequest) (resp *types.TaskOperationResponse, err error) {
+	task, err := l.svcCtx.TasksModel.GetByTid(l.ctx, req.Tid)
+	if err != nil {
+		return nil, errors.New("任务不存在")
+	}
+	if task.Status != model.TaskStatusDead && task.Status != model.TaskStatusFailed {
+		return nil, errors.New("只有死信或失败的任务可以重新入队")
+	}
+	// 重置重试次数, 按新任务重新执行
+	task.Status = model.TaskStatusInit
+	task.RetryCount = 0
+	task.CurrentStep = 0
+	task.Error = "{}"
+	task.NextRunAt = time.Now()
+	if err := l.svcCtx.TasksModel.Update(l.ctx, task); err != nil {
+		l.Errorf("RequeueTask tid: %s, error: %v", req.Tid, err)
+		return nil, errors.New("重新入队失败")
+	}
+	_ = l.svcCtx.TaskQueue.Push(l.ctx, task)
+	return &types.TaskOperationResponse{
+		Result: "重新入队成功",
+	}, nil
+}
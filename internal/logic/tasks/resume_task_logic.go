@@ -35,5 +35,6 @@ func (l *ResumeTaskLogic) ResumeTask(req *types.ResumeTaskRequest) (resp *types.
 	if err := l.svcCtx.TasksModel.Update(l.ctx, task); err != nil {
 		return nil, errors.New("恢复任务失败")
 	}
+	_ = l.svcCtx.TaskQueue.Push(l.ctx, task)
 	return resp, nil
 }
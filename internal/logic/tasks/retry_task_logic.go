@@ -3,6 +3,7 @@ package tasks
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
@@ -33,8 +34,10 @@ func (l *RetryTaskLogic) RetryTask(req *types.RetryTaskRequest) (resp *types.Tas
 	}
 	task.Status = model.TaskStatusRetry
 	task.CurrentStep = 0
+	task.NextRunAt = time.Now()
 	if err := l.svcCtx.TasksModel.Update(l.ctx, task); err != nil {
 		return nil, errors.New("重试任务失败")
 	}
+	_ = l.svcCtx.TaskQueue.Push(l.ctx, task)
 	return
 }
@@ -0,0 +1,78 @@
+package tasks
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/event"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+)
+
+type TaskEventsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 任务进度推送
+func NewTaskEventsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TaskEventsLogic {
+	return &TaskEventsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *TaskEventsLogic) TaskEvents(req *types.TaskEventsRequest, client chan<- *types.TaskEvent) error {
+	events, unsubscribe := l.svcCtx.TaskEvents.Subscribe(req.Tid)
+	defer unsubscribe()
+
+	// 先推送一次当前状态, 客户端连接后无需等待下一次变更
+	if req.Tid != "" {
+		task, err := l.svcCtx.TasksModel.GetByTid(l.ctx, req.Tid)
+		if err != nil {
+			l.Errorf("TaskEvents tid: %s, error: %v", req.Tid, err)
+			return err
+		}
+		select {
+		case client <- &types.TaskEvent{
+			Tid:    task.Tid,
+			Type:   event.TaskEventStatus,
+			Status: task.Status,
+			Node:   task.CurrentState,
+			Step:   task.CurrentStep,
+			Time:   task.UpdatedAt.Format(time.DateTime),
+		}:
+		case <-l.ctx.Done():
+			return nil
+		}
+	}
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			select {
+			case client <- &types.TaskEvent{
+				Tid:    e.Tid,
+				Type:   e.Type,
+				Status: e.Status,
+				Node:   e.Node,
+				Pid:    e.Pid,
+				Step:   e.Step,
+				Error:  e.Error,
+				Time:   e.Time,
+			}:
+			case <-l.ctx.Done():
+				return nil
+			}
+		case <-l.ctx.Done():
+			return nil
+		}
+	}
+}
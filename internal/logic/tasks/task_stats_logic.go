@@ -0,0 +1,182 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/types"
+	"github.com/XXueTu/wise/pkg/agent/url_analyse.go"
+)
+
+// 默认统计最近 7 天
+const defaultStatsRange = 7 * 24 * time.Hour
+
+type TaskStatsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 任务耗时与成本统计
+func NewTaskStatsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TaskStatsLogic {
+	return &TaskStatsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *TaskStatsLogic) TaskStats(req *types.TaskStatsRequest) (resp *types.TaskStatsResponse, err error) {
+	start, end, err := parseStatsRange(req.Start, req.End)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := l.svcCtx.TasksModel.GetRange(l.ctx, start, end)
+	if err != nil {
+		l.Errorf("TaskStats GetRange start: %s, end: %s, error: %v", req.Start, req.End, err)
+		return nil, errors.New("获取任务统计失败")
+	}
+	plans, err := l.svcCtx.TaskPlansModel.GetRange(l.ctx, start, end)
+	if err != nil {
+		l.Errorf("TaskStats TaskPlans GetRange start: %s, end: %s, error: %v", req.Start, req.End, err)
+		return nil, errors.New("获取任务统计失败")
+	}
+
+	resp = &types.TaskStatsResponse{
+		Start: start.Format(time.DateTime),
+		End:   end.Format(time.DateTime),
+		Types: make([]types.TaskTypeStats, 0),
+		Nodes: make([]types.TaskNodeStats, 0),
+	}
+
+	typeStats := make(map[string]*types.TaskTypeStats)
+	typeDurations := make(map[string][]int64)
+	for _, task := range tasks {
+		stats, ok := typeStats[task.Types]
+		if !ok {
+			stats = &types.TaskTypeStats{Types: task.Types}
+			typeStats[task.Types] = stats
+		}
+		stats.Total++
+		resp.Total++
+		switch task.Status {
+		case model.TaskStatusSuccess:
+			stats.Success++
+			resp.Success++
+		case model.TaskStatusFailed, model.TaskStatusDead:
+			stats.Failed++
+			resp.Failed++
+		}
+		// 只统计执行过的任务耗时
+		if task.Duration > 0 {
+			typeDurations[task.Types] = append(typeDurations[task.Types], task.Duration)
+		}
+	}
+	resp.SuccessRate = successRate(resp.Success, resp.Failed)
+	for name, stats := range typeStats {
+		stats.SuccessRate = successRate(stats.Success, stats.Failed)
+		stats.P50, stats.P95 = percentiles(typeDurations[name])
+		resp.Types = append(resp.Types, *stats)
+	}
+	sort.Slice(resp.Types, func(i, j int) bool { return resp.Types[i].Types < resp.Types[j].Types })
+
+	llm := l.svcCtx.Config.LLM
+	nodeStats := make(map[string]*types.TaskNodeStats)
+	nodeDurations := make(map[string][]int64)
+	for _, plan := range plans {
+		// 只统计工作流节点, 忽略未命名的历史记录
+		if _, ok := url_analyse.UrlAnalyseSteps[plan.Name]; !ok {
+			continue
+		}
+		stats, ok := nodeStats[plan.Name]
+		if !ok {
+			stats = &types.TaskNodeStats{Name: plan.Name}
+			nodeStats[plan.Name] = stats
+		}
+		stats.Total++
+		switch plan.Status {
+		case model.TaskPlanStatusSuccess:
+			stats.Success++
+		case model.TaskPlanStatusFailed:
+			stats.Failed++
+		}
+		stats.PromptTokens += plan.PromptTokens
+		stats.CompletionTokens += plan.CompletionTokens
+		if plan.Duration > 0 {
+			nodeDurations[plan.Name] = append(nodeDurations[plan.Name], plan.Duration)
+		}
+	}
+	for name, stats := range nodeStats {
+		stats.P50, stats.P95 = percentiles(nodeDurations[name])
+		stats.Cost = cost(llm.PromptPrice, llm.CompletionPrice, stats.PromptTokens, stats.CompletionTokens)
+		// 整图的消耗已包含各节点, 总数只累加节点
+		if name != url_analyse.GraphPlanName {
+			resp.PromptTokens += stats.PromptTokens
+			resp.CompletionTokens += stats.CompletionTokens
+		}
+		resp.Nodes = append(resp.Nodes, *stats)
+	}
+	sort.Slice(resp.Nodes, func(i, j int) bool { return resp.Nodes[i].Name < resp.Nodes[j].Name })
+	resp.Cost = cost(llm.PromptPrice, llm.CompletionPrice, resp.PromptTokens, resp.CompletionTokens)
+	return resp, nil
+}
+
+// parseStatsRange 解析统计时间范围, 默认最近 7 天
+func parseStatsRange(startStr, endStr string) (start time.Time, end time.Time, err error) {
+	end = time.Now()
+	if endStr != "" {
+		end, err = time.ParseInLocation(time.DateTime, endStr, time.Local)
+		if err != nil {
+			return start, end, errors.New("结束时间格式错误")
+		}
+	}
+	start = end.Add(-defaultStatsRange)
+	if startStr != "" {
+		start, err = time.ParseInLocation(time.DateTime, startStr, time.Local)
+		if err != nil {
+			return start, end, errors.New("开始时间格式错误")
+		}
+	}
+	if !start.Before(end) {
+		return start, end, errors.New("开始时间需早于结束时间")
+	}
+	return start, end, nil
+}
+
+// successRate 按已结束的任务计算成功率
+func successRate(success, failed int64) float64 {
+	if success+failed == 0 {
+		return 0
+	}
+	return math.Round(float64(success)/float64(success+failed)*10000) / 10000
+}
+
+// percentiles 计算耗时的 p50 和 p95, 使用最近秩法
+func percentiles(durations []int64) (p50 int64, p95 int64) {
+	if len(durations) == 0 {
+		return 0, 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return percentile(durations, 0.5), percentile(durations, 0.95)
+}
+
+func percentile(sorted []int64, p float64) int64 {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// cost 按每千 token 价格计算大模型成本
+func cost(promptPrice, completionPrice float64, promptTokens, completionTokens int64) float64 {
+	total := float64(promptTokens)/1000*promptPrice + float64(completionTokens)/1000*completionPrice
+	return math.Round(total*10000) / 10000
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var _ BatchItemsGen = (*BatchItemsModel)(nil)
+
+type BatchItemsModel struct {
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
+}
+
+func NewBatchItemsModel(db *DB) *BatchItemsModel {
+	return &BatchItemsModel{
+		db:  db.Writer,
+		rdb: db.Reader,
+	}
+}
+
+// TableName 返回表名
+func (m *BatchItemsModel) TableName() string {
+	return "batch_items"
+}
+
+func (m *BatchItemsModel) InitData() {
+
+}
+
+// CreateBatch 批量创建明细
+func (m *BatchItemsModel) CreateBatch(ctx context.Context, items []*BatchItems) error {
+	if len(items) == 0 {
+		return nil
+	}
+	_, err := m.db.NewInsert().Model(&items).Exec(ctx)
+	if err != nil {
+		logx.Errorf("CreateBatch batch items: %d, error: %v", len(items), err)
+	}
+	return err
+}
+
+func (m *BatchItemsModel) GetByBid(ctx context.Context, bid string) ([]*BatchItems, error) {
+	var items []*BatchItems
+	err := m.rdb.NewSelect().Model(&items).Where("bid = ?", bid).Order("id ASC").Scan(ctx)
+	return items, err
+}
+
+// MarkInvalid 任务创建失败时将明细标记为无效, 明细按预先分配的任务唯一标识查找
+func (m *BatchItemsModel) MarkInvalid(ctx context.Context, tid string, reason string) error {
+	_, err := m.db.NewUpdate().Model((*BatchItems)(nil)).
+		Set("tid = ?", "").
+		Set("status = ?", BatchItemStatusInvalid).
+		Set("error = ?", reason).
+		Set("updated_at = ?", time.Now()).
+		Where("tid = ?", tid).
+		Exec(ctx)
+	if err != nil {
+		logx.Errorf("MarkInvalid tid: %s, error: %v", tid, err)
+	}
+	return err
+}
+
+// MarkOrphaned 将创建时间早于 before、任务不存在的排队明细标记为无效, 返回处理的条数
+// 明细先于任务写入, 两者之间进程退出时任务不会再创建
+func (m *BatchItemsModel) MarkOrphaned(ctx context.Context, before time.Time, reason string) (int64, error) {
+	res, err := m.db.NewUpdate().Model((*BatchItems)(nil)).
+		Set("tid = ?", "").
+		Set("status = ?", BatchItemStatusInvalid).
+		Set("error = ?", reason).
+		Set("updated_at = ?", time.Now()).
+		Where("status = ?", BatchItemStatusQueued).
+		Where("created_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM tasks AS t WHERE t.tid = ?TableAlias.tid)").
+		Exec(ctx)
+	if err != nil {
+		logx.Errorf("MarkOrphaned before: %v, error: %v", before, err)
+		return 0, err
+	}
+	rows, _ := res.RowsAffected()
+	return rows, nil
+}
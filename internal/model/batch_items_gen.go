@@ -0,0 +1,49 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// BatchItems 批量导入明细, 每个链接一条
+type BatchItems struct {
+	bun.BaseModel `bun:"table:batch_items,alias:bi"`
+
+	ID        int64     `bun:"id,pk,autoincrement" json:"id"`
+	Bid       string    `bun:"bid,notnull" json:"bid"`       // 批次唯一标识
+	URL       string    `bun:"url,notnull" json:"url"`       // 链接
+	Title     string    `bun:"title,notnull" json:"title"`   // 导入时的标题
+	Tid       string    `bun:"tid,notnull" json:"tid"`       // 任务唯一标识, 未创建任务时为空
+	Status    string    `bun:"status,notnull" json:"status"` // 明细状态 queued,duplicate,invalid
+	Error     string    `bun:"error,notnull" json:"error"`   // 未创建任务的原因
+	CreatedAt time.Time `bun:"created_at,notnull" json:"created_at"`
+	UpdatedAt time.Time `bun:"updated_at,notnull" json:"updated_at"`
+}
+
+type BatchItemsGen interface {
+	TableName() string
+	InitData()
+	CreateBatch(ctx context.Context, items []*BatchItems) error
+	GetByBid(ctx context.Context, bid string) ([]*BatchItems, error)
+	MarkInvalid(ctx context.Context, tid string, reason string) error
+	MarkOrphaned(ctx context.Context, before time.Time, reason string) (int64, error)
+}
+
+const (
+	BatchItemStatusQueued    = "queued"    // 已创建任务, 进度以任务状态为准
+	BatchItemStatusDuplicate = "duplicate" // 资源已存在或重复提交
+	BatchItemStatusInvalid   = "invalid"   // 链接无效
+)
+
+func (m *BatchItems) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		m.CreatedAt = time.Now()
+		m.UpdatedAt = m.CreatedAt
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
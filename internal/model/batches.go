@@ -0,0 +1,87 @@
+package model
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var _ BatchesGen = (*BatchesModel)(nil)
+
+type BatchesModel struct {
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
+}
+
+func NewBatchesModel(db *DB) *BatchesModel {
+	return &BatchesModel{
+		db:  db.Writer,
+		rdb: db.Reader,
+	}
+}
+
+// TableName 返回表名
+func (m *BatchesModel) TableName() string {
+	return "batches"
+}
+
+func (m *BatchesModel) InitData() {
+
+}
+
+// Create 创建批次
+func (m *BatchesModel) Create(ctx context.Context, batch *Batches) error {
+	_, err := m.db.NewInsert().Model(batch).Exec(ctx)
+	if err != nil {
+		logx.Errorf("Create batch bid: %s, error: %v", batch.Bid, err)
+	}
+	return err
+}
+
+// CreateWithItems 在一个事务中创建批次和明细
+func (m *BatchesModel) CreateWithItems(ctx context.Context, batch *Batches, items []*BatchItems) error {
+	err := m.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(batch).Exec(ctx); err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		_, err := tx.NewInsert().Model(&items).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		logx.Errorf("CreateWithItems bid: %s, items: %d, error: %v", batch.Bid, len(items), err)
+	}
+	return err
+}
+
+func (m *BatchesModel) GetByBid(ctx context.Context, bid string) (*Batches, error) {
+	var batch Batches
+	err := m.rdb.NewSelect().Model(&batch).Where("bid = ?", bid).Scan(ctx)
+	return &batch, err
+}
+
+// BatchesList 批次列表返回结构
+type BatchesList struct {
+	Total int64      `json:"total"` // 总记录数
+	List  []*Batches `json:"list"`  // 批次列表
+}
+
+func (m *BatchesModel) GetList(ctx context.Context, page, size int64) (*BatchesList, error) {
+	var batches []*Batches
+	total, err := m.rdb.NewSelect().Model(&batches).
+		Order("id DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		ScanAndCount(ctx)
+	if err != nil {
+		logx.Errorf("GetList batches page: %d, size: %d, error: %v", page, size, err)
+		return nil, err
+	}
+	return &BatchesList{
+		Total: int64(total),
+		List:  batches,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Batches 批量导入批次
+type Batches struct {
+	bun.BaseModel `bun:"table:batches,alias:b"`
+
+	ID        int64     `bun:"id,pk,autoincrement" json:"id"`
+	Bid       string    `bun:"bid,notnull" json:"bid"`       // 批次唯一标识
+	Name      string    `bun:"name,notnull" json:"name"`     // 批次名称
+	Source    string    `bun:"source,notnull" json:"source"` // 导入来源 text,txt,csv,html
+	Total     int64     `bun:"total,notnull" json:"total"`   // 链接总数
+	CreatedAt time.Time `bun:"created_at,notnull" json:"created_at"`
+	UpdatedAt time.Time `bun:"updated_at,notnull" json:"updated_at"`
+}
+
+type BatchesGen interface {
+	TableName() string
+	InitData()
+	Create(ctx context.Context, batch *Batches) error
+	CreateWithItems(ctx context.Context, batch *Batches, items []*BatchItems) error
+	GetByBid(ctx context.Context, bid string) (*Batches, error)
+	GetList(ctx context.Context, page, size int64) (*BatchesList, error)
+}
+
+const (
+	BatchSourceText     = "text"
+	BatchSourceTxt      = "txt"
+	BatchSourceCsv      = "csv"
+	BatchSourceHtml     = "html"
+	BatchSourceIdentify = "identify"
+)
+
+func (m *Batches) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		m.CreatedAt = time.Now()
+		m.UpdatedAt = m.CreatedAt
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/pgdriver"
+	"github.com/uptrace/bun/driver/sqliteshim"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/internal/model/migrations"
+)
+
+// 支持的数据库
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// 日志中 SQL 的最大长度, 避免记录完整的文章内容
+const maxLogQueryLen = 1024
+
+// DB 数据库连接, SQLite 同一时刻只允许一个写入, 写入使用单个连接, 查询使用只读连接池
+// PostgreSQL 读写使用同一个连接池
+type DB struct {
+	Writer *bun.DB // 写连接, 迁移、写入和事务使用
+	Reader *bun.DB // 只读连接池, 未开启时与写连接相同
+}
+
+// 数据版本表, 由触发器在写入时递增, 多个进程共享
+const (
+	DataVersionsTable = "data_versions"
+	DataTagStats      = "tag_stats" // 标签及资源关联
+)
+
+// Version 返回数据的当前版本, 用于缓存失效, 其他进程的写入同样会递增
+func (d *DB) Version(ctx context.Context, name string) (int64, error) {
+	var version int64
+	err := d.Reader.NewSelect().Table(DataVersionsTable).Column("version").Where("name = ?", name).Scan(ctx, &version)
+	if err != nil {
+		logx.Errorf("Version name: %s, error: %v", name, err)
+	}
+	return version, err
+}
+
+// Close 关闭读写连接
+func (d *DB) Close() error {
+	if d.Reader != d.Writer {
+		if err := d.Reader.Close(); err != nil {
+			logx.Errorf("close reader db error: %v", err)
+		}
+	}
+	return d.Writer.Close()
+}
+
+// queryHook 记录慢查询, 开启调试时记录全部 SQL
+type queryHook struct {
+	slow  time.Duration
+	debug bool
+}
+
+func (h *queryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *queryHook) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	duration := time.Since(event.StartTime)
+	switch {
+	case h.slow > 0 && duration >= h.slow:
+		logx.Slowf("[SQL] %s | %s", duration, truncateQuery(event.Query))
+	case h.debug:
+		logx.Debugf("[SQL] %s | %s", duration, truncateQuery(event.Query))
+	}
+}
+
+// truncateQuery 压缩空白并截断过长的 SQL
+func truncateQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) <= maxLogQueryLen {
+		return query
+	}
+	cut := maxLogQueryLen
+	for cut > 0 && !utf8.RuneStart(query[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...(%d bytes)", query[:cut], len(query))
+}
+
+// pragmaConnector 新建连接后执行 PRAGMA, busy_timeout 等设置只对当前连接生效, 需要在每个连接上设置
+type pragmaConnector struct {
+	dsn     string
+	pragmas []string
+}
+
+func (c *pragmaConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		_ = conn.Close()
+		return nil, errors.New("sqlite driver does not support ExecerContext")
+	}
+	for _, pragma := range c.pragmas {
+		if _, err := execer.ExecContext(ctx, pragma, nil); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%s: %w", pragma, err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *pragmaConnector) Driver() driver.Driver {
+	return sqliteshim.Driver()
+}
+
+// OpenDB 打开数据库连接, 不执行迁移
+func OpenDB(c config.DatabaseConfig) *DB {
+	hook := &queryHook{slow: c.SlowThreshold, debug: c.Debug}
+	var db *DB
+	if c.Driver == DriverPostgres {
+		db = openPostgres(c, hook)
+	} else {
+		db = openSQLite(c, hook)
+	}
+	return db
+}
+
+// openPostgres PostgreSQL 支持并发写入, 读写共用一个连接池
+func openPostgres(c config.DatabaseConfig, hook *queryHook) *DB {
+	sqldb := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(c.DSN)))
+	db := bun.NewDB(sqldb, pgdialect.New())
+	db.AddQueryHook(hook)
+	if err := db.Ping(); err != nil {
+		panic(fmt.Sprintf("连接 PostgreSQL 失败: %v", err))
+	}
+	return &DB{Writer: db, Reader: db}
+}
+
+func openSQLite(c config.DatabaseConfig, hook *queryHook) *DB {
+	// 确保数据库目录存在
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+		panic(err)
+	}
+
+	pragmas := []string{
+		// 多个连接或进程共享数据库文件时, 等待其他连接释放锁而不是直接返回 SQLITE_BUSY
+		fmt.Sprintf("PRAGMA busy_timeout = %d", c.BusyTimeout.Milliseconds()),
+		fmt.Sprintf("PRAGMA synchronous = %s", c.Synchronous),
+	}
+
+	// SQLite 只支持一个写连接
+	writer := bun.NewDB(sql.OpenDB(&pragmaConnector{dsn: c.Path, pragmas: pragmas}), sqlitedialect.New())
+	writer.SetMaxOpenConns(1)
+	writer.SetMaxIdleConns(1)
+	writer.AddQueryHook(hook)
+
+	// 日志模式记录在数据库文件中, 在写连接上设置一次即可
+	journalMode := "DELETE"
+	if c.WAL {
+		journalMode = "WAL"
+	}
+	if _, err := writer.Exec("PRAGMA journal_mode = " + journalMode); err != nil {
+		panic(fmt.Sprintf("设置 journal_mode 失败: %v", err))
+	}
+
+	db := &DB{Writer: writer, Reader: writer}
+	// 内存数据库每个连接相互独立, 只能共用写连接
+	if c.ReadPoolSize <= 0 || c.Path == ":memory:" {
+		return db
+	}
+	readPragmas := append(pragmas, "PRAGMA query_only = ON")
+	reader := bun.NewDB(sql.OpenDB(&pragmaConnector{dsn: c.Path, pragmas: readPragmas}), sqlitedialect.New())
+	reader.SetMaxOpenConns(c.ReadPoolSize)
+	reader.SetMaxIdleConns(c.ReadPoolSize)
+	reader.AddQueryHook(hook)
+	db.Reader = reader
+	return db
+}
+
+// InitDB 打开数据库, 执行未执行的迁移并初始化表数据
+func InitDB(c config.DatabaseConfig) *DB {
+	db := OpenDB(c)
+
+	group, err := migrations.Up(context.Background(), db.Writer)
+	if err != nil {
+		panic(fmt.Sprintf("数据库迁移失败: %v", err))
+	}
+	if !group.IsZero() {
+		logx.Infof("数据库迁移完成: %s", group)
+	}
+
+	// 初始化表数据
+	NewModelsModel(db).InitData()
+	NewTagsModel(db).InitData()
+	NewTasksModel(db).InitData()
+	NewTaskPlansModel(db).InitData()
+	NewBatchesModel(db).InitData()
+	NewBatchItemsModel(db).InitData()
+	NewSegmentsModel(db).InitData()
+	NewResourceTagsModel(db).InitData()
+	NewTagAliasesModel(db).InitData()
+	NewTagRulesModel(db).InitData()
+	NewResourceKeywordsModel(db).InitData()
+	return db
+}
+
+// isUniqueViolation 判断错误是否为违反唯一约束, SQLite 驱动随编译方式不同, 按错误信息判断
+func isUniqueViolation(err error) bool {
+	var pgErr pgdriver.Error
+	if errors.As(err, &pgErr) {
+		return pgErr.Field('C') == "23505"
+	}
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
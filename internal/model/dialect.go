@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// SQLite 和 PostgreSQL 语法不同的查询条件
+
+// isPostgres 是否为 PostgreSQL
+func isPostgres(db bun.IDB) bool {
+	return db.Dialect().Name() == dialect.PG
+}
+
+// whereCreatedBetween 创建时间在 [start, end) 内
+// SQLite 中时间以文本保存且格式不统一, 需转换为 julianday 比较
+func whereCreatedBetween(db bun.IDB, q *bun.SelectQuery, start time.Time, end time.Time) *bun.SelectQuery {
+	if isPostgres(db) {
+		return q.Where("created_at >= ?", start).Where("created_at < ?", end)
+	}
+	return q.Where("julianday(created_at) >= julianday(?)", start).
+		Where("julianday(created_at) < julianday(?)", end)
+}
+
+// whereContains 字段包含关键字, 与 SQLite 的 LIKE 一致不区分大小写
+func whereContains(db bun.IDB, q *bun.SelectQuery, column string, keyword string) *bun.SelectQuery {
+	op := "LIKE"
+	if isPostgres(db) {
+		op = "ILIKE"
+	}
+	return q.Where("? "+op+" ?", bun.Ident(column), "%"+keyword+"%")
+}
+
+// waitedSecondsExpr 任务自创建以来等待的秒数, 参数为当前时间
+func waitedSecondsExpr(db bun.IDB) string {
+	if isPostgres(db) {
+		return "CAST(EXTRACT(EPOCH FROM (CAST(? AS TIMESTAMPTZ) - created_at)) AS BIGINT)"
+	}
+	return "(CAST(strftime('%s', ?) AS INTEGER) - CAST(strftime('%s', created_at) AS INTEGER))"
+}
+
+// periodExpr 将 created_at 转换为 UTC 日期, period 为 day 或 month
+func periodExpr(db bun.IDB, period string) string {
+	if isPostgres(db) {
+		if period == PeriodMonth {
+			return "to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM')"
+		}
+		return "to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')"
+	}
+	if period == PeriodMonth {
+		return "strftime('%Y-%m', created_at)"
+	}
+	return "strftime('%Y-%m-%d', created_at)"
+}
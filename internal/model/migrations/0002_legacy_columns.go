@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+// legacyColumns 引入迁移之前通过启动时补齐的字段, 旧库可能缺少部分字段
+var legacyColumns = []struct {
+	Table  string
+	Column string
+	Define string
+}{
+	{Table: "tasks", Column: "next_run_at", Define: "TIMESTAMP"},
+	{Table: "tasks", Column: "priority", Define: "INTEGER NOT NULL DEFAULT 0"},
+	{Table: "tasks", Column: "lease_owner", Define: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "tasks", Column: "lease_until", Define: "TIMESTAMP"},
+	{Table: "tasks", Column: "started_at", Define: "TIMESTAMP"},
+	{Table: "tasks", Column: "ended_at", Define: "TIMESTAMP"},
+	{Table: "task_plans", Column: "started_at", Define: "TIMESTAMP"},
+	{Table: "task_plans", Column: "ended_at", Define: "TIMESTAMP"},
+	{Table: "task_plans", Column: "prompt_tokens", Define: "INTEGER NOT NULL DEFAULT 0"},
+	{Table: "task_plans", Column: "completion_tokens", Define: "INTEGER NOT NULL DEFAULT 0"},
+}
+
+func init() {
+	// 新库由 0001 直接创建完整的表, 只有旧库需要补齐字段; 回滚时保留字段
+	// PostgreSQL 没有引入迁移之前的旧库, 只在 SQLite 中执行
+	SQLiteMigrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		for _, c := range legacyColumns {
+			var count int
+			err := db.NewRaw("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", c.Table, c.Column).Scan(ctx, &count)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			if _, err := db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.Table, c.Column, c.Define)); err != nil {
+				return fmt.Errorf("add column %s.%s: %w", c.Table, c.Column, err)
+			}
+		}
+		return nil
+	}, nil)
+}
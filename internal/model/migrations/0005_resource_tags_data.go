@@ -0,0 +1,113 @@
+package migrations
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+func init() {
+	for _, migrations := range []*migrate.Migrations{SQLiteMigrations, PostgresMigrations} {
+		migrations.MustRegister(moveResourceTags, restoreResourceTags)
+	}
+}
+
+// moveResourceTags 将 resources.tags 迁移到 resource_tags 后删除该字段
+// 解析节点曾写入标签名称, 名称按已有标签匹配, 没有同名标签时创建
+func moveResourceTags(ctx context.Context, db *bun.DB) error {
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var tags []struct {
+			Uid  string `bun:"uid"`
+			Name string `bun:"name"`
+		}
+		if err := tx.NewRaw("SELECT uid, name FROM tags").Scan(ctx, &tags); err != nil {
+			return err
+		}
+		uids := make(map[string]bool, len(tags))
+		names := make(map[string]string, len(tags))
+		for _, tag := range tags {
+			uids[tag.Uid] = true
+			names[tag.Name] = tag.Uid
+		}
+
+		var resources []struct {
+			ID   int64  `bun:"id"`
+			Tags string `bun:"tags"`
+		}
+		if err := tx.NewRaw("SELECT id, tags FROM resources WHERE tags <> '' ORDER BY id").Scan(ctx, &resources); err != nil {
+			return err
+		}
+		for _, resource := range resources {
+			seen := make(map[string]bool)
+			for _, value := range strings.Split(resource.Tags, ",") {
+				value = strings.TrimSpace(value)
+				if value == "" {
+					continue
+				}
+				uid := value
+				if !uids[value] {
+					var ok bool
+					if uid, ok = names[value]; !ok {
+						uid = genUid()
+						_, err := tx.ExecContext(ctx,
+							"INSERT INTO tags (uid, name, description, color, icon) VALUES (?, ?, ?, '', '')",
+							uid, value, "迁移")
+						if err != nil {
+							return fmt.Errorf("create tag %s: %w", value, err)
+						}
+						uids[uid] = true
+						names[value] = uid
+					}
+				}
+				if seen[uid] {
+					continue
+				}
+				seen[uid] = true
+				_, err := tx.ExecContext(ctx, "INSERT INTO resource_tags (resource_id, tag_uid) VALUES (?, ?)", resource.ID, uid)
+				if err != nil {
+					return fmt.Errorf("link resource %d tag %s: %w", resource.ID, uid, err)
+				}
+			}
+		}
+		_, err := tx.ExecContext(ctx, "ALTER TABLE resources DROP COLUMN tags")
+		return err
+	})
+}
+
+// restoreResourceTags 回滚时恢复 resources.tags, 迁移时创建的标签保留
+func restoreResourceTags(ctx context.Context, db *bun.DB) error {
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.ExecContext(ctx, "ALTER TABLE resources ADD COLUMN tags TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+		var links []struct {
+			ResourceID int64  `bun:"resource_id"`
+			TagUid     string `bun:"tag_uid"`
+		}
+		if err := tx.NewRaw("SELECT resource_id, tag_uid FROM resource_tags ORDER BY id").Scan(ctx, &links); err != nil {
+			return err
+		}
+		tags := make(map[int64][]string)
+		for _, link := range links {
+			tags[link.ResourceID] = append(tags[link.ResourceID], link.TagUid)
+		}
+		for id, uids := range tags {
+			if _, err := tx.ExecContext(ctx, "UPDATE resources SET tags = ? WHERE id = ?", strings.Join(uids, ","), id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// genUid 与 model.GenUid 一致, 迁移不能引用 model 包
+func genUid() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return strings.ReplaceAll(base64.URLEncoding.EncodeToString(b), "=", "")[:8]
+}
@@ -0,0 +1,107 @@
+package migrations
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"golang.org/x/text/unicode/norm"
+)
+
+func init() {
+	for _, migrations := range []*migrate.Migrations{SQLiteMigrations, PostgresMigrations} {
+		migrations.MustRegister(addTagNameKeys, dropTagNameKeys)
+	}
+}
+
+// addTagNameKeys 为标签名称和别名增加规范化的名称, 按名称查找标签时忽略大小写、全角半角和语言后缀
+// 规范化规则在 Go 中实现, 已有数据在迁移时按 tagNameKey0010 逐行计算
+func addTagNameKeys(ctx context.Context, db *bun.DB) error {
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, query := range []string{
+			"ALTER TABLE tags ADD COLUMN name_key TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE tag_aliases ADD COLUMN alias_key TEXT NOT NULL DEFAULT ''",
+		} {
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return err
+			}
+		}
+		for _, c := range []struct{ table, column, key string }{
+			{"tags", "name", "name_key"},
+			{"tag_aliases", "alias", "alias_key"},
+		} {
+			var rows []struct {
+				ID   int64  `bun:"id"`
+				Name string `bun:"name"`
+			}
+			if err := tx.NewRaw("SELECT id, ? AS name FROM ?", bun.Ident(c.column), bun.Ident(c.table)).Scan(ctx, &rows); err != nil {
+				return err
+			}
+			for _, row := range rows {
+				_, err := tx.ExecContext(ctx, "UPDATE ? SET ? = ? WHERE id = ?", bun.Ident(c.table), bun.Ident(c.key), tagNameKey0010(row.Name), row.ID)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		// 规范化后相同的别名只保留最早添加的一个, 按名称查找标签时结果唯一
+		for _, query := range []string{
+			"DELETE FROM tag_aliases WHERE id NOT IN (SELECT MIN(id) FROM tag_aliases GROUP BY alias_key)",
+			"CREATE INDEX IF NOT EXISTS idx_tags_name_key ON tags (name_key)",
+			"CREATE UNIQUE INDEX IF NOT EXISTS idx_tag_aliases_alias_key ON tag_aliases (alias_key)",
+		} {
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func dropTagNameKeys(ctx context.Context, db *bun.DB) error {
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, query := range []string{
+			"DROP INDEX IF EXISTS idx_tag_aliases_alias_key",
+			"DROP INDEX IF EXISTS idx_tags_name_key",
+			"ALTER TABLE tag_aliases DROP COLUMN alias_key",
+			"ALTER TABLE tags DROP COLUMN name_key",
+		} {
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// tagNameKey0010 迁移编写时 tagname.Key 的副本, 之后修改规范化规则不改变本迁移的结果
+func tagNameKey0010(name string) string {
+	languageSuffixes := []string{"programminglanguage", "编程语言", "language", "语言"}
+	languageNames := map[string]bool{
+		"ada": true, "assembly": true, "c": true, "c#": true, "c++": true, "clojure": true, "cobol": true,
+		"crystal": true, "d": true, "dart": true, "elixir": true, "elm": true, "erlang": true, "f#": true,
+		"fortran": true, "go": true, "groovy": true, "haskell": true, "java": true, "javascript": true,
+		"julia": true, "kotlin": true, "lisp": true, "lua": true, "matlab": true, "nim": true,
+		"objectivec": true, "ocaml": true, "pascal": true, "perl": true, "php": true, "prolog": true,
+		"python": true, "r": true, "ruby": true, "rust": true, "scala": true, "scheme": true,
+		"smalltalk": true, "solidity": true, "sql": true, "swift": true, "typescript": true,
+		"v": true, "visualbasic": true, "zig": true,
+	}
+	name = strings.ToLower(norm.NFKC.String(name))
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsSpace(r) || strings.ContainsRune("-_·・", r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	key := b.String()
+	for _, suffix := range languageSuffixes {
+		if stem, ok := strings.CutSuffix(key, suffix); ok && languageNames[stem] {
+			return stem
+		}
+	}
+	return key
+}
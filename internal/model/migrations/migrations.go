@@ -0,0 +1,127 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/migrate"
+)
+
+// 迁移记录表, 与 bun 默认的表名区分
+const (
+	TableName      = "schema_migrations"
+	LocksTableName = "schema_migration_locks"
+)
+
+// 其他进程正在迁移时等待的时间
+const lockTimeout = 30 * time.Second
+
+//go:embed sqlite/*.sql postgres/*.sql
+var sqlMigrations embed.FS
+
+// 每种数据库一套迁移, 文件名以编号开头, 按编号顺序执行
+// SQL 迁移放在数据库对应的目录, 为 <编号>_<说明>.up.sql 和 .down.sql, 需要判断条件的迁移使用同样命名的 Go 文件
+// 编号在两套迁移间保持一致, 只适用于一种数据库的迁移在另一套中跳过该编号
+var (
+	SQLiteMigrations   = migrate.NewMigrations()
+	PostgresMigrations = migrate.NewMigrations()
+)
+
+func init() {
+	for dir, migrations := range map[string]*migrate.Migrations{
+		"sqlite":   SQLiteMigrations,
+		"postgres": PostgresMigrations,
+	} {
+		sub, err := fs.Sub(sqlMigrations, dir)
+		if err != nil {
+			panic(err)
+		}
+		if err := migrations.Discover(sub); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ForDialect 返回数据库对应的迁移
+func ForDialect(db *bun.DB) *migrate.Migrations {
+	if db.Dialect().Name() == dialect.PG {
+		return PostgresMigrations
+	}
+	return SQLiteMigrations
+}
+
+// NewMigrator 创建迁移器, 迁移成功后才记录为已执行
+func NewMigrator(db *bun.DB) *migrate.Migrator {
+	return migrate.NewMigrator(db, ForDialect(db),
+		migrate.WithTableName(TableName),
+		migrate.WithLocksTableName(LocksTableName),
+		migrate.WithMarkAppliedOnSuccess(true),
+	)
+}
+
+// Up 执行未执行的迁移, 多个进程同时启动时等待持有锁的进程完成
+func Up(ctx context.Context, db *bun.DB) (*migrate.MigrationGroup, error) {
+	migrator := NewMigrator(db)
+	if err := migrator.Init(ctx); err != nil {
+		return nil, err
+	}
+	if err := lock(ctx, migrator); err != nil {
+		return nil, err
+	}
+	defer migrator.Unlock(ctx)
+	return migrator.Migrate(ctx)
+}
+
+// Down 回滚最近一次执行的迁移组
+func Down(ctx context.Context, db *bun.DB) (*migrate.MigrationGroup, error) {
+	migrator := NewMigrator(db)
+	if err := migrator.Init(ctx); err != nil {
+		return nil, err
+	}
+	if err := lock(ctx, migrator); err != nil {
+		return nil, err
+	}
+	defer migrator.Unlock(ctx)
+	return migrator.Rollback(ctx)
+}
+
+// Status 返回全部迁移及执行状态
+func Status(ctx context.Context, db *bun.DB) (migrate.MigrationSlice, error) {
+	migrator := NewMigrator(db)
+	if err := migrator.Init(ctx); err != nil {
+		return nil, err
+	}
+	return migrator.MigrationsWithStatus(ctx)
+}
+
+// Unlock 释放迁移锁, 用于迁移过程中进程异常退出后遗留的锁
+func Unlock(ctx context.Context, db *bun.DB) error {
+	migrator := NewMigrator(db)
+	if err := migrator.Init(ctx); err != nil {
+		return err
+	}
+	return migrator.Unlock(ctx)
+}
+
+func lock(ctx context.Context, migrator *migrate.Migrator) error {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := migrator.Lock(ctx)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w, 如迁移进程已异常退出, 请执行 wise migrate unlock", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
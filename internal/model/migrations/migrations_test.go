@@ -0,0 +1,197 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+func newTestDB(t *testing.T) *bun.DB {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file::memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func hasColumn(t *testing.T, db *bun.DB, table, column string) bool {
+	var count int
+	err := db.NewRaw("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(context.Background(), &count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	group, err := Up(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(group.Migrations) != len(SQLiteMigrations.Sorted()) {
+		t.Errorf("applied %d migrations, want %d", len(group.Migrations), len(SQLiteMigrations.Sorted()))
+	}
+	// 重复执行不会再次迁移
+	group, err = Up(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !group.IsZero() {
+		t.Errorf("second Up applied %s", group)
+	}
+
+	if _, err := Down(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if hasColumn(t, db, "tasks", "tid") {
+		t.Error("tasks table should be dropped after Down")
+	}
+	if _, err := Up(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if !hasColumn(t, db, "tasks", "tid") {
+		t.Error("tasks table should be created after Up")
+	}
+}
+
+func TestUpLegacyDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	// 引入迁移之前的旧库, 任务表缺少后来增加的字段
+	_, err := db.ExecContext(ctx, `CREATE TABLE tasks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, tid TEXT NOT NULL, name TEXT NOT NULL, types TEXT NOT NULL,
+		status TEXT NOT NULL, current_state TEXT NOT NULL, total_steps INTEGER NOT NULL, current_step INTEGER NOT NULL,
+		retry_count INTEGER, params TEXT NOT NULL, result TEXT NOT NULL, duration INTEGER NOT NULL,
+		error TEXT NOT NULL, extend TEXT NOT NULL, created_at TIMESTAMP NOT NULL, updated_at TIMESTAMP NOT NULL)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Up(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range legacyColumns {
+		if !hasColumn(t, db, c.Table, c.Column) {
+			t.Errorf("column %s.%s missing after Up", c.Table, c.Column)
+		}
+	}
+}
+
+func TestUpResourceTags(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	// 旧库的资源标签以逗号拼接, 手动创建的资源保存唯一标识, 解析节点写入的是名称
+	for _, query := range []string{
+		`CREATE TABLE resources (
+			id INTEGER PRIMARY KEY AUTOINCREMENT, url TEXT NOT NULL, title TEXT NOT NULL, describe TEXT NOT NULL,
+			content TEXT NOT NULL, type TEXT NOT NULL, tags TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE TABLE tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT, uid TEXT NOT NULL, name TEXT NOT NULL, description TEXT NOT NULL,
+			color TEXT NOT NULL, icon TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)`,
+		`INSERT INTO tags (uid, name, description, color, icon) VALUES ('go', 'Golang', '', '', '')`,
+		`INSERT INTO resources (url, title, describe, content, type, tags) VALUES
+			('a', 'a', '', '', '', 'go'), ('b', 'b', '', '', '', 'Golang,AI, go'), ('c', 'c', '', '', '', '')`,
+	} {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Up(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if hasColumn(t, db, "resources", "tags") {
+		t.Error("resources.tags should be dropped after Up")
+	}
+	var links []string
+	err := db.NewRaw(`SELECT rt.resource_id || ':' || t.name FROM resource_tags rt
+		JOIN tags t ON t.uid = rt.tag_uid ORDER BY rt.resource_id, t.name`).Scan(ctx, &links)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(links, ","), "1:Golang,2:AI,2:Golang"; got != want {
+		t.Errorf("resource_tags = %s, want %s", got, want)
+	}
+
+	// 回滚后按关联表恢复逗号拼接的字段
+	if err := restoreResourceTags(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	var tags string
+	if err := db.NewRaw("SELECT tags FROM resources WHERE id = 1").Scan(ctx, &tags); err != nil {
+		t.Fatal(err)
+	}
+	if tags != "go" {
+		t.Errorf("resources.tags after Down = %q, want go", tags)
+	}
+}
+
+func TestUpTagAliasKeys(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	migrator := NewMigrator(db)
+	if err := migrator.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// 执行到 0009, 写入规范化后重复的别名
+	for _, m := range SQLiteMigrations.Sorted() {
+		if m.Name >= "0010" {
+			break
+		}
+		if err := m.Up(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+		if err := migrator.MarkApplied(ctx, &m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := db.ExecContext(ctx, `INSERT INTO tag_aliases (alias, tag_uid, created_at) VALUES
+		('Golang', 'go', CURRENT_TIMESTAMP), ('golang', 'go2', CURRENT_TIMESTAMP), ('Go语言', 'go', CURRENT_TIMESTAMP)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Up(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	var aliases []string
+	if err := db.NewRaw("SELECT alias FROM tag_aliases ORDER BY id").Scan(ctx, &aliases); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(aliases, ","), "Golang,Go语言"; got != want {
+		t.Errorf("tag_aliases = %s, want %s", got, want)
+	}
+	_, err = db.ExecContext(ctx, "INSERT INTO tag_aliases (alias, alias_key, tag_uid, created_at) VALUES ('GOLANG', 'golang', 'go', CURRENT_TIMESTAMP)")
+	if err == nil {
+		t.Error("duplicate alias_key should violate unique index")
+	}
+}
+
+// 两套迁移的编号需要一致, 只适用于 SQLite 的迁移在 PostgreSQL 中跳过
+func TestMigrationsParity(t *testing.T) {
+	sqliteOnly := map[string]bool{"0002": true}
+	var sqliteNames, postgresNames []string
+	for _, m := range SQLiteMigrations.Sorted() {
+		if !sqliteOnly[m.Name] {
+			sqliteNames = append(sqliteNames, m.Name+"_"+m.Comment)
+		}
+	}
+	for _, m := range PostgresMigrations.Sorted() {
+		postgresNames = append(postgresNames, m.Name+"_"+m.Comment)
+		if m.Up == nil || m.Down == nil {
+			t.Errorf("postgres migration %s missing up or down", m.Name)
+		}
+	}
+	if strings.Join(sqliteNames, ",") != strings.Join(postgresNames, ",") {
+		t.Errorf("sqlite migrations %v, postgres migrations %v", sqliteNames, postgresNames)
+	}
+}
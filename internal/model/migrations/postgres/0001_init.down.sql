@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS batch_items;
+DROP TABLE IF EXISTS batches;
+DROP TABLE IF EXISTS task_plans;
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS models;
+DROP TABLE IF EXISTS resources;
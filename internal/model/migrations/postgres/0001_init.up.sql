@@ -0,0 +1,113 @@
+-- 初始表结构, 与 SQLite 的 0001 对应
+-- 资源表
+CREATE TABLE IF NOT EXISTS resources (
+    id BIGSERIAL PRIMARY KEY,
+    url TEXT NOT NULL, -- 资源URL
+    title TEXT NOT NULL, -- 资源标题
+    describe TEXT NOT NULL, -- 资源描述
+    content TEXT NOT NULL, -- 资源内容
+    type TEXT NOT NULL, -- 资源类型（如：wechat, zhihu等）
+    tags TEXT NOT NULL, -- 资源标签
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 模型表
+CREATE TABLE IF NOT EXISTS models (
+    id BIGSERIAL PRIMARY KEY,
+    base_url TEXT NOT NULL, -- 资源链接
+    config TEXT NOT NULL, -- 资源配置
+    type TEXT NOT NULL, -- 资源类型
+    model_name TEXT NOT NULL, -- 模型名称
+    model_real_name TEXT NOT NULL, -- 模型真实名称
+    status TEXT NOT NULL, -- 状态
+    tag TEXT NOT NULL, -- 标签
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 标签表
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGSERIAL PRIMARY KEY,
+    uid TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    color TEXT NOT NULL,
+    icon TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 任务表
+CREATE TABLE IF NOT EXISTS tasks (
+    id BIGSERIAL PRIMARY KEY,
+    tid TEXT NOT NULL, -- 任务唯一标识
+    name TEXT NOT NULL, -- 任务名称
+    types TEXT NOT NULL, -- 任务类型
+    status TEXT NOT NULL, -- 任务状态
+    current_state TEXT NOT NULL, -- 当前状态机
+    total_steps BIGINT NOT NULL, -- 总步骤
+    current_step BIGINT NOT NULL, -- 当前步骤
+    retry_count BIGINT NOT NULL DEFAULT 0, -- 重试次数
+    params TEXT NOT NULL, -- 任务参数
+    result TEXT NOT NULL, -- 任务结果
+    duration BIGINT NOT NULL, -- 任务耗时 ms
+    error TEXT NOT NULL, -- 任务错误
+    extend TEXT NOT NULL, -- 扩展字段
+    next_run_at TIMESTAMPTZ, -- 下次执行时间
+    priority BIGINT NOT NULL DEFAULT 0, -- 优先级
+    lease_owner TEXT NOT NULL DEFAULT '', -- 租约持有者
+    lease_until TIMESTAMPTZ, -- 租约到期时间
+    started_at TIMESTAMPTZ, -- 最近一次开始执行时间
+    ended_at TIMESTAMPTZ, -- 最近一次结束执行时间
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 创建时间
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP -- 更新时间
+);
+
+-- 任务计划表
+CREATE TABLE IF NOT EXISTS task_plans (
+    id BIGSERIAL PRIMARY KEY,
+    tid TEXT NOT NULL, -- 任务唯一标识
+    pid TEXT NOT NULL, -- 任务计划唯一标识
+    before_pid TEXT NOT NULL, -- 上一个任务计划唯一标识
+    next TEXT NOT NULL, -- 下一个任务类型
+    types TEXT NOT NULL, -- 任务类型
+    name TEXT NOT NULL, -- 任务名称
+    "index" BIGINT NOT NULL, -- 任务计划索引
+    status TEXT NOT NULL, -- 任务状态
+    params TEXT NOT NULL, -- 任务参数
+    result TEXT NOT NULL, -- 任务结果
+    duration BIGINT NOT NULL, -- 任务耗时 ms
+    error TEXT NOT NULL, -- 任务错误
+    started_at TIMESTAMPTZ, -- 开始时间
+    ended_at TIMESTAMPTZ, -- 结束时间
+    prompt_tokens BIGINT NOT NULL DEFAULT 0, -- 输入 token 数
+    completion_tokens BIGINT NOT NULL DEFAULT 0, -- 输出 token 数
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 创建时间
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP -- 更新时间
+);
+
+-- 批量导入批次表
+CREATE TABLE IF NOT EXISTS batches (
+    id BIGSERIAL PRIMARY KEY,
+    bid TEXT NOT NULL, -- 批次唯一标识
+    name TEXT NOT NULL, -- 批次名称
+    source TEXT NOT NULL, -- 导入来源 text,txt,csv,html
+    total BIGINT NOT NULL, -- 链接总数
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 创建时间
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP -- 更新时间
+);
+
+-- 批量导入明细表
+CREATE TABLE IF NOT EXISTS batch_items (
+    id BIGSERIAL PRIMARY KEY,
+    bid TEXT NOT NULL, -- 批次唯一标识
+    url TEXT NOT NULL, -- 链接
+    title TEXT NOT NULL, -- 导入时的标题
+    tid TEXT NOT NULL, -- 任务唯一标识, 未创建任务时为空
+    status TEXT NOT NULL, -- 明细状态 queued,duplicate,invalid
+    error TEXT NOT NULL, -- 未创建任务的原因
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 创建时间
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP -- 更新时间
+);
+CREATE INDEX IF NOT EXISTS idx_batch_items_bid ON batch_items (bid);
@@ -0,0 +1,14 @@
+-- 资源分段表, 保存切分后的内容和向量, 向量使用 pgvector
+-- 向量维度由嵌入模型决定, 不限制维度, 按余弦距离精确检索
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE IF NOT EXISTS segments (
+    id BIGSERIAL PRIMARY KEY,
+    resource_id BIGINT NOT NULL, -- 资源 id
+    seq BIGINT NOT NULL, -- 分段序号
+    content TEXT NOT NULL, -- 分段内容
+    embedding vector, -- 向量, 未生成时为空
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 创建时间
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP -- 更新时间
+);
+CREATE INDEX IF NOT EXISTS idx_segments_resource_id ON segments (resource_id);
@@ -0,0 +1,9 @@
+-- 资源标签关联表, 替代 resources.tags 中逗号拼接的标签唯一标识
+CREATE TABLE IF NOT EXISTS resource_tags (
+    id BIGSERIAL PRIMARY KEY,
+    resource_id BIGINT NOT NULL, -- 资源 id
+    tag_uid TEXT NOT NULL, -- 标签唯一标识
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resource_tags_resource_tag ON resource_tags (resource_id, tag_uid);
+CREATE INDEX IF NOT EXISTS idx_resource_tags_tag_uid ON resource_tags (tag_uid);
@@ -0,0 +1,3 @@
+ALTER TABLE tags DROP COLUMN IF EXISTS embedding;
+ALTER TABLE resource_tags DROP COLUMN IF EXISTS label;
+ALTER TABLE resource_tags DROP COLUMN IF EXISTS confidence;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tag_aliases_tag_uid;
+DROP INDEX IF EXISTS idx_tag_aliases_alias;
+DROP TABLE IF EXISTS tag_aliases;
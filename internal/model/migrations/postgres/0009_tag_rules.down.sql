@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tag_rules_tag_uid;
+DROP INDEX IF EXISTS idx_tag_rules_uid;
+DROP TABLE IF EXISTS tag_rules;
@@ -0,0 +1,12 @@
+-- 资源关键词表, 分析任务从内容中提取的关键词和命名实体, 重新分析时整体替换
+CREATE TABLE IF NOT EXISTS resource_keywords (
+    id BIGSERIAL PRIMARY KEY,
+    resource_id BIGINT NOT NULL, -- 资源 id
+    keyword TEXT NOT NULL, -- 关键词
+    keyword_key TEXT NOT NULL, -- 规范化的关键词, 与标签名称的规范化规则相同
+    type TEXT NOT NULL, -- 类型 keyword,person,product,organization,technology
+    weight DOUBLE PRECISION NOT NULL DEFAULT 0, -- 权重, 0 到 1
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resource_keywords_resource_key ON resource_keywords (resource_id, keyword_key);
+CREATE INDEX IF NOT EXISTS idx_resource_keywords_key ON resource_keywords (keyword_key);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_resource_tags_version ON resource_tags;
+DROP TRIGGER IF EXISTS trg_tags_version ON tags;
+DROP FUNCTION IF EXISTS bump_tag_stats_version();
+DROP TABLE IF EXISTS data_versions;
@@ -0,0 +1,30 @@
+-- 数据版本表, 标签和资源关联写入时由触发器递增, 多个进程共享, 用于统计缓存失效
+CREATE TABLE IF NOT EXISTS data_versions (
+    name TEXT PRIMARY KEY, -- 数据名称
+    version BIGINT NOT NULL DEFAULT 0 -- 版本, 每次写入递增
+);
+
+INSERT INTO data_versions (name, version) VALUES ('tag_stats', 0) ON CONFLICT (name) DO NOTHING;
+
+-- 同一事务只递增一次, 事务结束后 set_config 设置的值失效
+CREATE OR REPLACE FUNCTION bump_tag_stats_version() RETURNS trigger AS $$
+BEGIN
+    IF current_setting('wise.tag_stats_bumped', true) IS DISTINCT FROM 'on' THEN
+        PERFORM set_config('wise.tag_stats_bumped', 'on', true);
+        UPDATE data_versions SET version = version + 1 WHERE name = 'tag_stats';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- 延迟到提交时触发, 版本行只在提交期间加锁, 并发写入标签的事务不会在整个事务内互相等待
+-- 版本与数据在同一事务中提交, 读到新版本时一定能读到对应的数据
+DROP TRIGGER IF EXISTS trg_tags_version ON tags;
+CREATE CONSTRAINT TRIGGER trg_tags_version AFTER INSERT OR UPDATE OR DELETE ON tags
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION bump_tag_stats_version();
+
+DROP TRIGGER IF EXISTS trg_resource_tags_version ON resource_tags;
+CREATE CONSTRAINT TRIGGER trg_resource_tags_version AFTER INSERT OR UPDATE OR DELETE ON resource_tags
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION bump_tag_stats_version();
@@ -0,0 +1,5 @@
+-- 任务调度暂停, 恢复备份等维护期间所有实例不领取任务, 到期自动解除, 避免发起的进程异常退出后一直暂停
+CREATE TABLE IF NOT EXISTS task_pauses (
+    name TEXT PRIMARY KEY, -- 暂停原因
+    paused_until TIMESTAMPTZ NOT NULL -- 暂停截止时间
+);
@@ -1,3 +1,4 @@
+-- 初始表结构, 使用 IF NOT EXISTS 兼容引入迁移之前创建的数据库
 -- 资源表
 CREATE TABLE IF NOT EXISTS resources (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -53,6 +54,12 @@ CREATE TABLE IF NOT EXISTS tasks (
     duration INTEGER NOT NULL, -- 任务耗时 ms
     error TEXT NOT NULL, -- 任务错误
     extend TEXT NOT NULL, -- 扩展字段
+    next_run_at TIMESTAMP, -- 下次执行时间
+    priority INTEGER NOT NULL DEFAULT 0, -- 优先级
+    lease_owner TEXT NOT NULL DEFAULT '', -- 租约持有者
+    lease_until TIMESTAMP, -- 租约到期时间
+    started_at TIMESTAMP, -- 最近一次开始执行时间
+    ended_at TIMESTAMP, -- 最近一次结束执行时间
     created_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')), -- 创建时间
     updated_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')) -- 更新时间
 );
@@ -72,6 +79,35 @@ CREATE TABLE IF NOT EXISTS task_plans (
     result TEXT NOT NULL, -- 任务结果
     duration INTEGER NOT NULL, -- 任务耗时 ms
     error TEXT NOT NULL, -- 任务错误
+    started_at TIMESTAMP, -- 开始时间
+    ended_at TIMESTAMP, -- 结束时间
+    prompt_tokens INTEGER NOT NULL DEFAULT 0, -- 输入 token 数
+    completion_tokens INTEGER NOT NULL DEFAULT 0, -- 输出 token 数
     created_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')), -- 创建时间
     updated_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')) -- 更新时间
-);
\ No newline at end of file
+);
+
+-- 批量导入批次表
+CREATE TABLE IF NOT EXISTS batches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    bid TEXT NOT NULL, -- 批次唯一标识
+    name TEXT NOT NULL, -- 批次名称
+    source TEXT NOT NULL, -- 导入来源 text,txt,csv,html
+    total INTEGER NOT NULL, -- 链接总数
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')), -- 创建时间
+    updated_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')) -- 更新时间
+);
+
+-- 批量导入明细表
+CREATE TABLE IF NOT EXISTS batch_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    bid TEXT NOT NULL, -- 批次唯一标识
+    url TEXT NOT NULL, -- 链接
+    title TEXT NOT NULL, -- 导入时的标题
+    tid TEXT NOT NULL, -- 任务唯一标识, 未创建任务时为空
+    status TEXT NOT NULL, -- 明细状态 queued,duplicate,invalid
+    error TEXT NOT NULL, -- 未创建任务的原因
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')), -- 创建时间
+    updated_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')) -- 更新时间
+);
+CREATE INDEX IF NOT EXISTS idx_batch_items_bid ON batch_items (bid);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_segments_resource_id;
+DROP TABLE IF EXISTS segments;
@@ -0,0 +1,11 @@
+-- 资源分段表, 保存切分后的内容和向量
+CREATE TABLE IF NOT EXISTS segments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    resource_id INTEGER NOT NULL, -- 资源 id
+    seq INTEGER NOT NULL, -- 分段序号
+    content TEXT NOT NULL, -- 分段内容
+    embedding TEXT, -- 向量, 格式与 pgvector 相同, 未生成时为空
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')), -- 创建时间
+    updated_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')) -- 更新时间
+);
+CREATE INDEX IF NOT EXISTS idx_segments_resource_id ON segments (resource_id);
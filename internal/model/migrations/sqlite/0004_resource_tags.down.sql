@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_resource_tags_tag_uid;
+DROP INDEX IF EXISTS idx_resource_tags_resource_tag;
+DROP TABLE IF EXISTS resource_tags;
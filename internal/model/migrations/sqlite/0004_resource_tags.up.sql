@@ -0,0 +1,9 @@
+-- 资源标签关联表, 替代 resources.tags 中逗号拼接的标签唯一标识
+CREATE TABLE IF NOT EXISTS resource_tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    resource_id INTEGER NOT NULL, -- 资源 id
+    tag_uid TEXT NOT NULL, -- 标签唯一标识
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')) -- 创建时间
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resource_tags_resource_tag ON resource_tags (resource_id, tag_uid);
+CREATE INDEX IF NOT EXISTS idx_resource_tags_tag_uid ON resource_tags (tag_uid);
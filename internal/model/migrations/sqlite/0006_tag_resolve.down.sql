@@ -0,0 +1,3 @@
+ALTER TABLE tags DROP COLUMN embedding;
+ALTER TABLE resource_tags DROP COLUMN label;
+ALTER TABLE resource_tags DROP COLUMN confidence;
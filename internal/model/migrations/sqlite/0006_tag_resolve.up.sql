@@ -0,0 +1,5 @@
+-- 大模型标签归并到已有标签, 记录每个资源标签的置信度和原始标签
+-- 手动添加的标签置信度为 1, label 为空
+ALTER TABLE resource_tags ADD COLUMN confidence REAL NOT NULL DEFAULT 1; -- 置信度
+ALTER TABLE resource_tags ADD COLUMN label TEXT NOT NULL DEFAULT ''; -- 大模型返回的原始标签
+ALTER TABLE tags ADD COLUMN embedding TEXT; -- 标签名称向量, 格式与 pgvector 相同, 未生成时为空
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tags_parent_uid;
+ALTER TABLE tags DROP COLUMN parent_uid;
@@ -0,0 +1,3 @@
+-- 标签树, 根标签的父标签为空
+ALTER TABLE tags ADD COLUMN parent_uid TEXT NOT NULL DEFAULT ''; -- 父标签唯一标识
+CREATE INDEX IF NOT EXISTS idx_tags_parent_uid ON tags (parent_uid);
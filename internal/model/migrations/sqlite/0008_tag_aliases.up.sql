@@ -0,0 +1,9 @@
+-- 标签别名表, 合并标签时被合并的标签名称作为目标标签的别名, 自动标注时按别名归入目标标签
+CREATE TABLE IF NOT EXISTS tag_aliases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    alias TEXT NOT NULL, -- 别名
+    tag_uid TEXT NOT NULL, -- 标签唯一标识
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')) -- 创建时间
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tag_aliases_alias ON tag_aliases (alias);
+CREATE INDEX IF NOT EXISTS idx_tag_aliases_tag_uid ON tag_aliases (tag_uid);
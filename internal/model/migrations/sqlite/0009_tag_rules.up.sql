@@ -0,0 +1,17 @@
+-- 自定义标签规则表, 资源标注后按优先级执行, 匹配的资源添加或移除标签
+CREATE TABLE IF NOT EXISTS tag_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uid TEXT NOT NULL, -- 规则唯一标识
+    name TEXT NOT NULL, -- 规则名称
+    type TEXT NOT NULL, -- 匹配方式 domain,url_regex,keyword,resource_type,label
+    field TEXT NOT NULL DEFAULT '', -- keyword 匹配的字段 title,content, 为空时都匹配
+    pattern TEXT NOT NULL, -- 匹配内容
+    action TEXT NOT NULL, -- add 添加标签, remove 移除标签
+    tag_uid TEXT NOT NULL, -- 标签唯一标识
+    priority INTEGER NOT NULL DEFAULT 0, -- 优先级, 数值小的先执行, 后执行的规则覆盖先执行的
+    enabled BOOLEAN NOT NULL DEFAULT 1, -- 是否启用
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')), -- 创建时间
+    updated_at TIMESTAMP NOT NULL DEFAULT (datetime(CURRENT_TIMESTAMP, 'localtime')) -- 更新时间
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tag_rules_uid ON tag_rules (uid);
+CREATE INDEX IF NOT EXISTS idx_tag_rules_tag_uid ON tag_rules (tag_uid);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_resource_keywords_key;
+DROP INDEX IF EXISTS idx_resource_keywords_resource_key;
+DROP TABLE IF EXISTS resource_keywords;
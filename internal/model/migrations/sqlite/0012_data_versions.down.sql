@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS trg_resource_tags_delete_version;
+DROP TRIGGER IF EXISTS trg_resource_tags_update_version;
+DROP TRIGGER IF EXISTS trg_resource_tags_insert_version;
+DROP TRIGGER IF EXISTS trg_tags_delete_version;
+DROP TRIGGER IF EXISTS trg_tags_update_version;
+DROP TRIGGER IF EXISTS trg_tags_insert_version;
+DROP TABLE IF EXISTS data_versions;
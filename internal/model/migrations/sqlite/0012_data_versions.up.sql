@@ -0,0 +1,39 @@
+-- 数据版本表, 标签和资源关联写入时由触发器递增, 多个进程共享, 用于统计缓存失效
+CREATE TABLE IF NOT EXISTS data_versions (
+    name TEXT PRIMARY KEY, -- 数据名称
+    version INTEGER NOT NULL DEFAULT 0 -- 版本, 每次写入递增
+);
+
+INSERT INTO data_versions (name, version) VALUES ('tag_stats', 0) ON CONFLICT (name) DO NOTHING;
+
+-- SQLite 同一时间只有一个写事务, 按行触发不会增加锁等待
+
+CREATE TRIGGER IF NOT EXISTS trg_tags_insert_version AFTER INSERT ON tags
+BEGIN
+    UPDATE data_versions SET version = version + 1 WHERE name = 'tag_stats';
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_tags_update_version AFTER UPDATE ON tags
+BEGIN
+    UPDATE data_versions SET version = version + 1 WHERE name = 'tag_stats';
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_tags_delete_version AFTER DELETE ON tags
+BEGIN
+    UPDATE data_versions SET version = version + 1 WHERE name = 'tag_stats';
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_resource_tags_insert_version AFTER INSERT ON resource_tags
+BEGIN
+    UPDATE data_versions SET version = version + 1 WHERE name = 'tag_stats';
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_resource_tags_update_version AFTER UPDATE ON resource_tags
+BEGIN
+    UPDATE data_versions SET version = version + 1 WHERE name = 'tag_stats';
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_resource_tags_delete_version AFTER DELETE ON resource_tags
+BEGIN
+    UPDATE data_versions SET version = version + 1 WHERE name = 'tag_stats';
+END;
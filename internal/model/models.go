@@ -11,14 +11,16 @@ import (
 
 var _ ModelsGen = (*ModelsModel)(nil)
 
-func NewModelsModel(db *bun.DB) *ModelsModel {
+func NewModelsModel(db *DB) *ModelsModel {
 	return &ModelsModel{
-		db: db,
+		db:  db.Writer,
+		rdb: db.Reader,
 	}
 }
 
 type ModelsModel struct {
-	db *bun.DB
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
 }
 
 // TableName 返回表名
@@ -41,7 +43,7 @@ func (m *ModelsModel) InitData() {
 	}
 	for _, model := range models {
 		// 判断是否存在
-		exist, err := m.db.NewSelect().Model((*Models)(nil)).
+		exist, err := m.rdb.NewSelect().Model((*Models)(nil)).
 			Where("base_url = ?", model.BaseUrl).
 			Where("model_name = ?", model.ModelName).
 			Where("model_real_name = ?", model.ModelRealName).
@@ -95,7 +97,7 @@ func (m *ModelsModel) Delete(ctx context.Context, id int64) error {
 
 func (m *ModelsModel) Get(ctx context.Context, id int64) (*Models, error) {
 	var model Models
-	err := m.db.NewSelect().Model(&model).Where("id = ?", id).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&model).Where("id = ?", id).Scan(ctx)
 	return &model, err
 }
 
@@ -108,7 +110,7 @@ type ModelsList struct {
 // GetList 分页查询模型列表
 func (m *ModelsModel) GetList(ctx context.Context, page, size int64, modelType string, tag []string, status, modelName string) (*ModelsList, error) {
 	// 构建查询
-	query := m.db.NewSelect().Model((*Models)(nil))
+	query := m.rdb.NewSelect().Model((*Models)(nil))
 
 	// 添加条件
 	if modelType != "" {
@@ -116,14 +118,14 @@ func (m *ModelsModel) GetList(ctx context.Context, page, size int64, modelType s
 	}
 	if len(tag) > 0 {
 		for _, t := range tag {
-			query = query.Where("tag LIKE ?", "%"+t+"%")
+			query = whereContains(m.rdb, query, "tag", t)
 		}
 	}
 	if status != "" {
 		query = query.Where("status = ?", status)
 	}
 	if modelName != "" {
-		query = query.Where("model_name LIKE ?", "%"+modelName+"%")
+		query = whereContains(m.rdb, query, "model_name", modelName)
 	}
 
 	// 获取总记录数
@@ -150,3 +152,22 @@ func (m *ModelsModel) GetList(ctx context.Context, page, size int64, modelType s
 		List:  models,
 	}, nil
 }
+
+// GetAll 获取全部模型, 用于导出
+func (m *ModelsModel) GetAll(ctx context.Context) ([]*Models, error) {
+	var models []*Models
+	err := m.rdb.NewSelect().Model(&models).Order("id ASC").Scan(ctx)
+	if err != nil {
+		logx.Error("GetAll error", err)
+	}
+	return models, err
+}
+
+// Exists 按地址和模型名称判断模型是否存在
+func (m *ModelsModel) Exists(ctx context.Context, baseUrl, modelName, modelRealName string) (bool, error) {
+	return m.rdb.NewSelect().Model((*Models)(nil)).
+		Where("base_url = ?", baseUrl).
+		Where("model_name = ?", modelName).
+		Where("model_real_name = ?", modelRealName).
+		Exists(ctx)
+}
@@ -31,6 +31,8 @@ type ModelsGen interface {
 	Delete(ctx context.Context, id int64) error
 	Get(ctx context.Context, id int64) (*Models, error)
 	GetList(ctx context.Context, page, size int64, modelType string, tag []string, status, modelName string) (*ModelsList, error)
+	GetAll(ctx context.Context) ([]*Models, error)
+	Exists(ctx context.Context, baseUrl, modelName, modelRealName string) (bool, error)
 }
 
 func (m *Models) BeforeInsert(ctx context.Context, query *bun.InsertQuery) error {
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/tagname"
+)
+
+var _ ResourceKeywordsGen = (*ResourceKeywordsModel)(nil)
+
+type ResourceKeywordsModel struct {
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
+}
+
+func NewResourceKeywordsModel(db *DB) *ResourceKeywordsModel {
+	return &ResourceKeywordsModel{
+		db:  db.Writer,
+		rdb: db.Reader,
+	}
+}
+
+// TableName 返回表名
+func (m *ResourceKeywordsModel) TableName() string {
+	return "resource_keywords"
+}
+
+func (m *ResourceKeywordsModel) InitData() {
+
+}
+
+// SetKeywords 替换资源的全部关键词, 规范化后相同的关键词保留权重最高的一个
+func (m *ResourceKeywordsModel) SetKeywords(ctx context.Context, resourceID int64, keywords []*ResourceKeywords) error {
+	err := m.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return ReplaceResourceKeywords(ctx, tx, resourceID, keywords)
+	})
+	if err != nil {
+		logx.Errorf("SetKeywords resourceID: %d, keywords: %d, error: %v", resourceID, len(keywords), err)
+	}
+	return err
+}
+
+// ReplaceResourceKeywords 在调用方的事务中替换资源的全部关键词, 规范化后相同的关键词保留权重最高的一个
+func ReplaceResourceKeywords(ctx context.Context, db bun.IDB, resourceID int64, keywords []*ResourceKeywords) error {
+	index := make(map[string]int)
+	unique := make([]*ResourceKeywords, 0, len(keywords))
+	for _, keyword := range keywords {
+		key := tagname.Key(keyword.Keyword)
+		if key == "" {
+			continue
+		}
+		if i, ok := index[key]; ok {
+			if keyword.Weight > unique[i].Weight {
+				unique[i].Weight = keyword.Weight
+			}
+			continue
+		}
+		keyword.ResourceID = resourceID
+		index[key] = len(unique)
+		unique = append(unique, keyword)
+	}
+	_, err := db.NewDelete().Model((*ResourceKeywords)(nil)).Where("resource_id = ?", resourceID).Exec(ctx)
+	if err != nil || len(unique) == 0 {
+		return err
+	}
+	_, err = db.NewInsert().Model(&unique).Exec(ctx)
+	return err
+}
+
+// GetByResources 批量获取资源的关键词, 按权重从高到低排序
+func (m *ResourceKeywordsModel) GetByResources(ctx context.Context, resourceIDs []int64) (map[int64][]*ResourceKeywords, error) {
+	result := make(map[int64][]*ResourceKeywords, len(resourceIDs))
+	if len(resourceIDs) == 0 {
+		return result, nil
+	}
+	var keywords []*ResourceKeywords
+	err := m.rdb.NewSelect().Model(&keywords).
+		Where("resource_id IN (?)", bun.In(resourceIDs)).
+		Order("weight DESC", "id ASC").
+		Scan(ctx)
+	if err != nil {
+		logx.Errorf("GetByResources resourceIDs: %v, error: %v", resourceIDs, err)
+		return nil, err
+	}
+	for _, keyword := range keywords {
+		result[keyword.ResourceID] = append(result[keyword.ResourceID], keyword)
+	}
+	return result, nil
+}
+
+// GetAll 获取全部资源的关键词, 按资源和权重排序
+func (m *ResourceKeywordsModel) GetAll(ctx context.Context) ([]*ResourceKeywords, error) {
+	var keywords []*ResourceKeywords
+	err := m.rdb.NewSelect().Model(&keywords).Order("resource_id ASC", "weight DESC", "id ASC").Scan(ctx)
+	if err != nil {
+		logx.Errorf("GetAll resource keywords error: %v", err)
+	}
+	return keywords, err
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/XXueTu/wise/internal/tagname"
+)
+
+// 关键词类型, 除普通关键词外为命名实体
+const (
+	KeywordTypeKeyword      = "keyword"      // 普通关键词
+	KeywordTypePerson       = "person"       // 人物
+	KeywordTypeProduct      = "product"      // 产品
+	KeywordTypeOrganization = "organization" // 组织
+	KeywordTypeTechnology   = "technology"   // 技术
+)
+
+// ResourceKeywords 从资源内容中提取的关键词
+type ResourceKeywords struct {
+	bun.BaseModel `bun:"table:resource_keywords,alias:rk"`
+
+	ID         int64     `bun:"id,pk,autoincrement" json:"id"`
+	ResourceID int64     `bun:"resource_id,notnull" json:"resource_id"` // 资源 id
+	Keyword    string    `bun:"keyword,notnull" json:"keyword"`         // 关键词
+	KeywordKey string    `bun:"keyword_key,notnull" json:"-"`           // 规范化的关键词
+	Type       string    `bun:"type,notnull" json:"type"`               // 类型
+	Weight     float64   `bun:"weight,notnull" json:"weight"`           // 权重, 0 到 1
+	CreatedAt  time.Time `bun:"created_at,notnull" json:"created_at"`
+}
+
+type ResourceKeywordsGen interface {
+	TableName() string
+	InitData()
+	SetKeywords(ctx context.Context, resourceID int64, keywords []*ResourceKeywords) error
+	GetByResources(ctx context.Context, resourceIDs []int64) (map[int64][]*ResourceKeywords, error)
+	GetAll(ctx context.Context) ([]*ResourceKeywords, error)
+}
+
+func (m *ResourceKeywords) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	if _, ok := query.(*bun.InsertQuery); ok {
+		m.KeywordKey = tagname.Key(m.Keyword)
+		m.CreatedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var _ ResourceTagsGen = (*ResourceTagsModel)(nil)
+
+type ResourceTagsModel struct {
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
+}
+
+func NewResourceTagsModel(db *DB) *ResourceTagsModel {
+	return &ResourceTagsModel{
+		db:  db.Writer,
+		rdb: db.Reader,
+	}
+}
+
+// TableName 返回表名
+func (m *ResourceTagsModel) TableName() string {
+	return "resource_tags"
+}
+
+func (m *ResourceTagsModel) InitData() {
+
+}
+
+// SetTags 替换资源的全部标签
+func (m *ResourceTagsModel) SetTags(ctx context.Context, resourceID int64, tagUids []string) error {
+	err := m.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return ReplaceResourceTags(ctx, tx, resourceID, tagUids)
+	})
+	if err != nil {
+		logx.Errorf("SetTags resourceID: %d, tagUids: %v, error: %v", resourceID, tagUids, err)
+	}
+	return err
+}
+
+// ReplaceResourceTags 在调用方的事务中替换资源的全部标签
+func ReplaceResourceTags(ctx context.Context, db bun.IDB, resourceID int64, tagUids []string) error {
+	_, err := db.NewDelete().Model((*ResourceTags)(nil)).Where("resource_id = ?", resourceID).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	return insertResourceTags(ctx, db, resourceID, tagUids)
+}
+
+// AddTags 为资源追加标签, 已有的标签忽略
+func (m *ResourceTagsModel) AddTags(ctx context.Context, resourceID int64, tagUids []string) error {
+	err := insertResourceTags(ctx, m.db, resourceID, tagUids)
+	if err != nil {
+		logx.Errorf("AddTags resourceID: %d, tagUids: %v, error: %v", resourceID, tagUids, err)
+	}
+	return err
+}
+
+// RemoveTags 移除资源的标签
+func (m *ResourceTagsModel) RemoveTags(ctx context.Context, resourceID int64, tagUids []string) error {
+	if len(tagUids) == 0 {
+		return nil
+	}
+	_, err := m.db.NewDelete().Model((*ResourceTags)(nil)).
+		Where("resource_id = ?", resourceID).
+		Where("tag_uid IN (?)", bun.In(tagUids)).
+		Exec(ctx)
+	if err != nil {
+		logx.Errorf("RemoveTags resourceID: %d, tagUids: %v, error: %v", resourceID, tagUids, err)
+	}
+	return err
+}
+
+// AddLabeled 追加大模型标注的标签, 重新标注时更新置信度, 手动添加的标签保持不变
+func (m *ResourceTagsModel) AddLabeled(ctx context.Context, resourceID int64, links []*ResourceTags) error {
+	if len(links) == 0 {
+		return nil
+	}
+	for _, link := range links {
+		link.ResourceID = resourceID
+	}
+	_, err := m.db.NewInsert().Model(&links).
+		On("CONFLICT (resource_id, tag_uid) DO UPDATE").
+		Set("confidence = EXCLUDED.confidence").
+		Set("label = EXCLUDED.label").
+		Where("rt.label <> ''").
+		Exec(ctx)
+	if err != nil {
+		logx.Errorf("AddLabeled resourceID: %d, links: %d, error: %v", resourceID, len(links), err)
+	}
+	return err
+}
+
+func insertResourceTags(ctx context.Context, db bun.IDB, resourceID int64, tagUids []string) error {
+	links := make([]*ResourceTags, 0, len(tagUids))
+	for _, uid := range uniqueStrings(tagUids) {
+		links = append(links, &ResourceTags{ResourceID: resourceID, TagUid: uid})
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	_, err := db.NewInsert().Model(&links).On("CONFLICT (resource_id, tag_uid) DO NOTHING").Exec(ctx)
+	return err
+}
+
+func (m *ResourceTagsModel) GetTagUids(ctx context.Context, resourceID int64) ([]string, error) {
+	var uids []string
+	err := m.rdb.NewSelect().Model((*ResourceTags)(nil)).
+		Column("tag_uid").
+		Where("resource_id = ?", resourceID).
+		Order("id ASC").
+		Scan(ctx, &uids)
+	return uids, err
+}
+
+// GetTags 一次查询多个资源的标签, 按添加顺序返回, 已删除的标签忽略
+func (m *ResourceTagsModel) GetTags(ctx context.Context, resourceIDs []int64) (map[int64][]*Tags, error) {
+	tags := make(map[int64][]*Tags, len(resourceIDs))
+	if len(resourceIDs) == 0 {
+		return tags, nil
+	}
+	var links []*ResourceTags
+	err := m.rdb.NewSelect().Model(&links).
+		Relation("Tag").
+		Where("rt.resource_id IN (?)", bun.In(resourceIDs)).
+		Where("tag.id IS NOT NULL").
+		Order("rt.id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		tags[link.ResourceID] = append(tags[link.ResourceID], link.Tag)
+	}
+	return tags, nil
+}
+
+// GetLinks 一次查询多个资源的标签关联, 包含大模型返回的原始标签
+func (m *ResourceTagsModel) GetLinks(ctx context.Context, resourceIDs []int64) (map[int64][]*ResourceTags, error) {
+	links := make(map[int64][]*ResourceTags, len(resourceIDs))
+	if len(resourceIDs) == 0 {
+		return links, nil
+	}
+	var list []*ResourceTags
+	err := m.rdb.NewSelect().Model(&list).
+		Where("resource_id IN (?)", bun.In(resourceIDs)).
+		Order("id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range list {
+		links[link.ResourceID] = append(links[link.ResourceID], link)
+	}
+	return links, nil
+}
+
+func (m *ResourceTagsModel) GetAll(ctx context.Context) ([]*ResourceTags, error) {
+	var links []*ResourceTags
+	err := m.rdb.NewSelect().Model(&links).Order("id ASC").Scan(ctx)
+	return links, err
+}
+
+// 统计的时间粒度
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// TagCount 标签关联的资源数
+type TagCount struct {
+	TagUid string `bun:"tag_uid"`
+	Period string `bun:"period"` // 按时间分组时的日期, 如 2006-01-02 或 2006-01
+	Count  int64  `bun:"count"`
+}
+
+// TagPair 同时关联同一资源的两个标签, TagUid 小于 OtherUid
+type TagPair struct {
+	TagUid   string `bun:"tag_uid"`
+	OtherUid string `bun:"other_uid"`
+	Count    int64  `bun:"count"`
+}
+
+// CountByTag 按标签统计关联的资源数, 已删除标签的关联忽略
+func (m *ResourceTagsModel) CountByTag(ctx context.Context) ([]*TagCount, error) {
+	var counts []*TagCount
+	err := m.rdb.NewSelect().Model((*ResourceTags)(nil)).
+		Column("tag_uid").
+		ColumnExpr("COUNT(*) AS count").
+		Where("tag_uid IN (SELECT uid FROM tags)").
+		Group("tag_uid").
+		Scan(ctx, &counts)
+	if err != nil {
+		logx.Errorf("CountByTag error: %v", err)
+	}
+	return counts, err
+}
+
+// CountByPeriod 按标签和时间统计 [start, end) 内新增的关联数, 按 UTC 日期分组
+func (m *ResourceTagsModel) CountByPeriod(ctx context.Context, start, end time.Time, period string) ([]*TagCount, error) {
+	var counts []*TagCount
+	q := m.rdb.NewSelect().Model((*ResourceTags)(nil)).
+		Column("tag_uid").
+		ColumnExpr(periodExpr(m.rdb, period) + " AS period").
+		ColumnExpr("COUNT(*) AS count").
+		Where("tag_uid IN (SELECT uid FROM tags)")
+	err := whereCreatedBetween(m.rdb, q, start, end).
+		GroupExpr("tag_uid, period").
+		OrderExpr("period ASC, count DESC").
+		Scan(ctx, &counts)
+	if err != nil {
+		logx.Errorf("CountByPeriod start: %s, end: %s, period: %s, error: %v", start, end, period, err)
+	}
+	return counts, err
+}
+
+// CoOccurrence 统计同时出现次数最多的标签对
+func (m *ResourceTagsModel) CoOccurrence(ctx context.Context, limit int) ([]*TagPair, error) {
+	var pairs []*TagPair
+	err := m.rdb.NewSelect().
+		TableExpr("resource_tags AS a").
+		Join("JOIN resource_tags AS b ON b.resource_id = a.resource_id AND a.tag_uid < b.tag_uid").
+		ColumnExpr("a.tag_uid AS tag_uid, b.tag_uid AS other_uid, COUNT(*) AS count").
+		Where("a.tag_uid IN (SELECT uid FROM tags)").
+		Where("b.tag_uid IN (SELECT uid FROM tags)").
+		GroupExpr("a.tag_uid, b.tag_uid").
+		OrderExpr("count DESC, a.tag_uid ASC, b.tag_uid ASC").
+		Limit(limit).
+		Scan(ctx, &pairs)
+	if err != nil {
+		logx.Errorf("CoOccurrence limit: %d, error: %v", limit, err)
+	}
+	return pairs, err
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ResourceTags 资源与标签的关联
+type ResourceTags struct {
+	bun.BaseModel `bun:"table:resource_tags,alias:rt"`
+
+	ID         int64     `bun:"id,pk,autoincrement" json:"id"`
+	ResourceID int64     `bun:"resource_id,notnull" json:"resource_id"`         // 资源 id
+	TagUid     string    `bun:"tag_uid,notnull" json:"tag_uid"`                 // 标签唯一标识
+	Confidence float64   `bun:"confidence,notnull,default:1" json:"confidence"` // 置信度, 手动添加的为 1
+	Label      string    `bun:"label,notnull" json:"label"`                     // 大模型返回的原始标签, 手动添加的为空
+	CreatedAt  time.Time `bun:"created_at,notnull" json:"created_at"`
+
+	Tag *Tags `bun:"rel:belongs-to,join:tag_uid=uid" json:"tag,omitempty"`
+}
+
+type ResourceTagsGen interface {
+	TableName() string
+	InitData()
+	SetTags(ctx context.Context, resourceID int64, tagUids []string) error
+	AddTags(ctx context.Context, resourceID int64, tagUids []string) error
+	RemoveTags(ctx context.Context, resourceID int64, tagUids []string) error
+	AddLabeled(ctx context.Context, resourceID int64, links []*ResourceTags) error
+	GetTagUids(ctx context.Context, resourceID int64) ([]string, error)
+	GetTags(ctx context.Context, resourceIDs []int64) (map[int64][]*Tags, error)
+	GetLinks(ctx context.Context, resourceIDs []int64) (map[int64][]*ResourceTags, error)
+	GetAll(ctx context.Context) ([]*ResourceTags, error)
+	CountByTag(ctx context.Context) ([]*TagCount, error)
+	CountByPeriod(ctx context.Context, start, end time.Time, period string) ([]*TagCount, error)
+	CoOccurrence(ctx context.Context, limit int) ([]*TagPair, error)
+}
+
+func (m *ResourceTags) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	if _, ok := query.(*bun.InsertQuery); ok {
+		m.CreatedAt = time.Now()
+	}
+	return nil
+}
@@ -2,20 +2,26 @@ package model
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/uptrace/bun"
 	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/tagname"
 )
 
 var _ ResourceGen = (*ResourceModel)(nil)
 
 type ResourceModel struct {
-	db *bun.DB
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
 }
 
-func NewResourceModel(db *bun.DB) *ResourceModel {
+func NewResourceModel(db *DB) *ResourceModel {
 	return &ResourceModel{
-		db: db,
+		db:  db.Writer,
+		rdb: db.Reader,
 	}
 }
 
@@ -40,17 +46,25 @@ func (r *ResourceModel) Create(ctx context.Context, resource *Resource) error {
 // GetByURL 根据URL获取资源
 func (r *ResourceModel) GetByURL(ctx context.Context, url string) (*Resource, error) {
 	resource := new(Resource)
-	err := r.db.NewSelect().
+	err := r.rdb.NewSelect().
 		Model(resource).
 		Where("url = ?", url).
 		Scan(ctx)
 	if err != nil {
-		logx.Error("GetByURL error", err)
+		// 未找到由调用方处理, 不记录错误
+		if !errors.Is(err, sql.ErrNoRows) {
+			logx.Error("GetByURL error", err)
+		}
 		return nil, err
 	}
 	return resource, nil
 }
 
+// ExistsURL 判断URL是否已有资源
+func (r *ResourceModel) ExistsURL(ctx context.Context, url string) (bool, error) {
+	return r.rdb.NewSelect().Model((*Resource)(nil)).Where("url = ?", url).Exists(ctx)
+}
+
 // Update 更新资源
 func (r *ResourceModel) Update(ctx context.Context, resource *Resource) error {
 	_, err := r.db.NewUpdate().
@@ -63,12 +77,20 @@ func (r *ResourceModel) Update(ctx context.Context, resource *Resource) error {
 	return err
 }
 
-// Delete 删除资源
+// Delete 删除资源及其标签关联
 func (r *ResourceModel) Delete(ctx context.Context, id int64) error {
-	_, err := r.db.NewDelete().
-		Model((*Resource)(nil)).
-		Where("id = ?", id).
-		Exec(ctx)
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Model((*ResourceTags)(nil)).Where("resource_id = ?", id).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewDelete().Model((*ResourceKeywords)(nil)).Where("resource_id = ?", id).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewDelete().Model((*Resource)(nil)).Where("id = ?", id).Exec(ctx)
+		return err
+	})
 	if err != nil {
 		logx.Error("Delete error", err)
 	}
@@ -77,35 +99,129 @@ func (r *ResourceModel) Delete(ctx context.Context, id int64) error {
 
 func (r *ResourceModel) Get(ctx context.Context, id int64) (*Resource, error) {
 	var resource Resource
-	err := r.db.NewSelect().Model(&resource).Where("id = ?", id).Scan(ctx)
+	err := r.rdb.NewSelect().Model(&resource).Where("id = ?", id).Scan(ctx)
 	if err != nil {
 		logx.Error("Get error", err)
 	}
 	return &resource, err
 }
 
+// GetByTag 获取直接关联标签的资源, 不包含子标签的资源
+func (r *ResourceModel) GetByTag(ctx context.Context, tagUid string) ([]*Resource, error) {
+	var resources []*Resource
+	err := r.rdb.NewSelect().Model(&resources).
+		Where("r.id IN (?)", r.rdb.NewSelect().Model((*ResourceTags)(nil)).Column("resource_id").Where("tag_uid = ?", tagUid)).
+		Order("r.id ASC").
+		Scan(ctx)
+	if err != nil {
+		logx.Errorf("GetByTag tagUid: %s, error: %v", tagUid, err)
+	}
+	return resources, err
+}
+
+// GetBatch 按 id 顺序获取 afterID 之后的资源, 用于分批遍历全部资源
+func (r *ResourceModel) GetBatch(ctx context.Context, afterID int64, limit int) ([]*Resource, error) {
+	var resources []*Resource
+	err := r.rdb.NewSelect().Model(&resources).
+		Where("r.id > ?", afterID).
+		Order("r.id ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		logx.Errorf("GetBatch afterID: %d, limit: %d, error: %v", afterID, limit, err)
+	}
+	return resources, err
+}
+
 // ResourceList 资源列表返回结构
 type ResourceList struct {
 	Total int64       `json:"total"` // 总记录数
 	List  []*Resource `json:"list"`  // 资源列表
 }
 
-// GetList 分页查询资源列表
-func (r *ResourceModel) GetList(ctx context.Context, page, size int, resourceType, title string, tagUids []string) (*ResourceList, error) {
+// TagFilter 按标签筛选资源, 多个条件同时生效, 为空的条件忽略
+// 标签包含其全部子孙标签, 按父标签筛选时带有子标签的资源同样命中
+type TagFilter struct {
+	All  []string // 包含全部标签
+	Any  []string // 包含任一标签
+	None []string // 不包含其中任何标签
+}
+
+// KeywordFilter 按提取的关键词筛选资源, 关键词按规范化后匹配, 为空时忽略
+type KeywordFilter struct {
+	All  []string // 包含全部关键词
+	Type string   // 只匹配该类型的关键词, 为空时匹配全部类型
+}
+
+// withKeywords 关键词匹配任一规范化关键词的资源
+func (r *ResourceModel) withKeywords(keys []string, keywordType string) *bun.SelectQuery {
+	query := r.rdb.NewSelect().Model((*ResourceKeywords)(nil)).
+		Column("resource_id").
+		Where("keyword_key IN (?)", bun.In(keys))
+	if keywordType != "" {
+		query = query.Where("type = ?", keywordType)
+	}
+	return query
+}
+
+// whereTags 添加标签筛选条件, 每个标签展开为自身及子孙标签
+func (r *ResourceModel) whereTags(ctx context.Context, query *bun.SelectQuery, tags TagFilter) (*bun.SelectQuery, error) {
+	withTags := func(uids []string) *bun.SelectQuery {
+		return r.rdb.NewSelect().Model((*ResourceTags)(nil)).
+			Column("resource_id").
+			Where("tag_uid IN (?)", bun.In(uids))
+	}
+	for _, uid := range uniqueStrings(tags.All) {
+		subtree, err := tagSubtree(ctx, r.rdb, []string{uid})
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("r.id IN (?)", withTags(subtree))
+	}
+	if len(uniqueStrings(tags.Any)) > 0 {
+		subtree, err := tagSubtree(ctx, r.rdb, tags.Any)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("r.id IN (?)", withTags(subtree))
+	}
+	if len(uniqueStrings(tags.None)) > 0 {
+		subtree, err := tagSubtree(ctx, r.rdb, tags.None)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("r.id NOT IN (?)", withTags(subtree))
+	}
+	return query, nil
+}
+
+// GetList 分页查询资源列表, title 匹配标题包含该内容或提取的关键词与之相同的资源
+func (r *ResourceModel) GetList(ctx context.Context, page, size int, resourceType, title string, tags TagFilter, keywords KeywordFilter) (*ResourceList, error) {
 	// 构建查询
-	query := r.db.NewSelect().Model((*Resource)(nil))
+	query := r.rdb.NewSelect().Model((*Resource)(nil))
 
 	// 添加条件
 	if title != "" {
-		query = query.Where("title LIKE ?", "%"+title+"%")
+		query = query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			q = whereContains(r.rdb, q, "title", title)
+			if key := tagname.Key(title); key != "" {
+				q = q.WhereOr("r.id IN (?)", r.withKeywords([]string{key}, ""))
+			}
+			return q
+		})
+	}
+	for _, keyword := range uniqueStrings(keywords.All) {
+		if key := tagname.Key(keyword); key != "" {
+			query = query.Where("r.id IN (?)", r.withKeywords([]string{key}, keywords.Type))
+		}
 	}
 	if resourceType != "" {
 		query = query.Where("type = ?", resourceType)
 	}
-	if len(tagUids) > 0 {
-		for _, tagUid := range tagUids {
-			query = query.Where("tags like ?", "%"+tagUid+"%")
-		}
+	query, err := r.whereTags(ctx, query, tags)
+	if err != nil {
+		logx.Error("GetList tags error", err)
+		return nil, err
 	}
 
 	// 获取总记录数
@@ -133,3 +249,27 @@ func (r *ResourceModel) GetList(ctx context.Context, page, size int, resourceTyp
 		List:  resources,
 	}, nil
 }
+
+// GetAll 获取全部资源, 用于导出
+func (r *ResourceModel) GetAll(ctx context.Context) ([]*Resource, error) {
+	var resources []*Resource
+	err := r.rdb.NewSelect().Model(&resources).Order("id ASC").Scan(ctx)
+	if err != nil {
+		logx.Error("GetAll error", err)
+	}
+	return resources, err
+}
+
+// uniqueStrings 去除空值和重复值, 保留顺序
+func uniqueStrings(values []string) []string {
+	result := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
@@ -17,7 +17,6 @@ type Resource struct {
 	Describe  string    `bun:"describe,notnull" json:"describe"` // 资源描述
 	Content   string    `bun:"content,notnull" json:"content"`   // 资源内容
 	Type      string    `bun:"type,notnull" json:"type"`         // 资源类型（如：wechat, zhihu等）
-	Tags      string    `bun:"tags,notnull" json:"tags"`         // 资源标签
 	CreatedAt time.Time `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
 	UpdatedAt time.Time `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
 }
@@ -30,7 +29,11 @@ type ResourceGen interface {
 	Delete(ctx context.Context, id int64) error
 	Get(ctx context.Context, id int64) (*Resource, error)
 	GetByURL(ctx context.Context, url string) (*Resource, error)
-	GetList(ctx context.Context, page, size int, resourceType, title string, tagUids []string) (*ResourceList, error)
+	ExistsURL(ctx context.Context, url string) (bool, error)
+	GetAll(ctx context.Context) ([]*Resource, error)
+	GetByTag(ctx context.Context, tagUid string) ([]*Resource, error)
+	GetBatch(ctx context.Context, afterID int64, limit int) ([]*Resource, error)
+	GetList(ctx context.Context, page, size int, resourceType, title string, tags TagFilter, keywords KeywordFilter) (*ResourceList, error)
 }
 
 func (m *Resource) BeforeInsert(ctx context.Context, query *bun.InsertQuery) error {
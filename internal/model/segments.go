@@ -0,0 +1,118 @@
+package model
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/uptrace/bun"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var _ SegmentsGen = (*SegmentsModel)(nil)
+
+type SegmentsModel struct {
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
+}
+
+func NewSegmentsModel(db *DB) *SegmentsModel {
+	return &SegmentsModel{
+		db:  db.Writer,
+		rdb: db.Reader,
+	}
+}
+
+// TableName 返回表名
+func (m *SegmentsModel) TableName() string {
+	return "segments"
+}
+
+func (m *SegmentsModel) InitData() {
+
+}
+
+// ReplaceByResource 替换资源的全部分段, 资源重新解析时使用
+func (m *SegmentsModel) ReplaceByResource(ctx context.Context, resourceID int64, segments []*Segments) error {
+	err := m.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Model((*Segments)(nil)).Where("resource_id = ?", resourceID).Exec(ctx)
+		if err != nil || len(segments) == 0 {
+			return err
+		}
+		for i, segment := range segments {
+			segment.ResourceID = resourceID
+			segment.Seq = int64(i)
+		}
+		_, err = tx.NewInsert().Model(&segments).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		logx.Errorf("ReplaceByResource resourceID: %d, segments: %d, error: %v", resourceID, len(segments), err)
+	}
+	return err
+}
+
+func (m *SegmentsModel) GetByResource(ctx context.Context, resourceID int64) ([]*Segments, error) {
+	var segments []*Segments
+	err := m.rdb.NewSelect().Model(&segments).Where("resource_id = ?", resourceID).Order("seq ASC").Scan(ctx)
+	return segments, err
+}
+
+func (m *SegmentsModel) UpdateEmbedding(ctx context.Context, id int64, embedding pgvector.Vector) error {
+	_, err := m.db.NewUpdate().Model((*Segments)(nil)).
+		Set("embedding = ?", embedding).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		logx.Errorf("UpdateEmbedding id: %d, error: %v", id, err)
+	}
+	return err
+}
+
+// Search 按余弦距离检索最相近的分段
+// PostgreSQL 使用 pgvector 在数据库中计算距离, SQLite 读取全部向量后在内存中计算
+func (m *SegmentsModel) Search(ctx context.Context, embedding pgvector.Vector, limit int) ([]*Segments, error) {
+	var segments []*Segments
+	if isPostgres(m.rdb) {
+		err := m.rdb.NewSelect().Model(&segments).
+			ColumnExpr("sg.*").
+			ColumnExpr("sg.embedding <=> ? AS distance", embedding).
+			Where("sg.embedding IS NOT NULL").
+			OrderExpr("distance ASC").
+			Limit(limit).
+			Scan(ctx)
+		return segments, err
+	}
+
+	err := m.rdb.NewSelect().Model(&segments).Where("embedding IS NOT NULL").Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := embedding.Slice()
+	for _, segment := range segments {
+		segment.Distance = CosineDistance(query, segment.Embedding.Slice())
+	}
+	sort.SliceStable(segments, func(i, j int) bool { return segments[i].Distance < segments[j].Distance })
+	if len(segments) > limit {
+		segments = segments[:limit]
+	}
+	return segments, nil
+}
+
+// CosineDistance 余弦距离, 与 pgvector 的 <=> 一致, 维度不同或为零向量时返回最大距离
+func CosineDistance(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 2
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 2
+	}
+	return 1 - dot/(math.Sqrt(na)*math.Sqrt(nb))
+}
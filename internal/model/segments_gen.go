@@ -0,0 +1,43 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/uptrace/bun"
+)
+
+// Segments 资源分段, 保存切分后的内容和向量
+type Segments struct {
+	bun.BaseModel `bun:"table:segments,alias:sg"`
+
+	ID         int64            `bun:"id,pk,autoincrement" json:"id"`
+	ResourceID int64            `bun:"resource_id,notnull" json:"resource_id"` // 资源 id
+	Seq        int64            `bun:"seq,notnull" json:"seq"`                 // 分段序号
+	Content    string           `bun:"content,notnull" json:"content"`         // 分段内容
+	Embedding  *pgvector.Vector `bun:"embedding,type:vector" json:"embedding"` // 向量, 未生成时为空
+	Distance   float64          `bun:"distance,scanonly" json:"distance"`      // 检索时与查询向量的余弦距离
+	CreatedAt  time.Time        `bun:"created_at,notnull" json:"created_at"`
+	UpdatedAt  time.Time        `bun:"updated_at,notnull" json:"updated_at"`
+}
+
+type SegmentsGen interface {
+	TableName() string
+	InitData()
+	ReplaceByResource(ctx context.Context, resourceID int64, segments []*Segments) error
+	GetByResource(ctx context.Context, resourceID int64) ([]*Segments, error)
+	UpdateEmbedding(ctx context.Context, id int64, embedding pgvector.Vector) error
+	Search(ctx context.Context, embedding pgvector.Vector, limit int) ([]*Segments, error)
+}
+
+func (m *Segments) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		m.CreatedAt = time.Now()
+		m.UpdatedAt = m.CreatedAt
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
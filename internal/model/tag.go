@@ -2,20 +2,55 @@ package model
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
 
+	"github.com/pgvector/pgvector-go"
 	"github.com/uptrace/bun"
 	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/tagname"
 )
 
 var _ TagGen = (*TagsModel)(nil)
 
+var (
+	ErrTagNotFound       = errors.New("tag not found")
+	ErrTagParentNotFound = errors.New("parent tag not found")
+	ErrTagCycle          = errors.New("tag cannot be moved under itself or its descendants")
+	ErrTagMergeTarget    = errors.New("merge target is one of the sources or their descendants")
+	ErrTagProtected      = errors.New("default tag cannot be removed")
+	ErrTagInUse          = errors.New("tag is referenced by tag rules")
+	ErrTagReferenced     = errors.New("tag is referenced by resources")
+	ErrTagReassignTarget = errors.New("reassign target does not exist or is the tag itself")
+)
+
+// 删除标签时对已关联资源的处理方式
+const (
+	TagDeleteDetach   = "detach"      // 解除资源关联
+	TagDeleteReassign = "reassign_to" // 资源改为关联目标标签
+	TagDeleteForbid   = "forbid"      // 有资源关联时不允许删除
+)
+
+// DefaultTagUid 初始化时创建的默认标签, 新建资源没有标签时使用
+const DefaultTagUid = "default"
+
+// 查询祖先标签的最大层数, 避免历史数据中存在环时无限递归
+const maxTagDepth = 64
+
 type TagsModel struct {
-	db *bun.DB
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
 }
 
-func NewTagsModel(db *bun.DB) *TagsModel {
+func NewTagsModel(db *DB) *TagsModel {
 	return &TagsModel{
-		db: db,
+		db:  db.Writer,
+		rdb: db.Reader,
 	}
 }
 
@@ -27,7 +62,7 @@ func (m *TagsModel) TableName() string {
 func (m *TagsModel) InitData() {
 	tags := []*Tags{
 		{
-			Uid:         "default",
+			Uid:         DefaultTagUid,
 			Name:        "默认",
 			Description: "默认标签",
 			Color:       "red",
@@ -36,7 +71,7 @@ func (m *TagsModel) InitData() {
 	}
 	for _, tag := range tags {
 		// 判断是否存在
-		exist, err := m.db.NewSelect().Model((*Tags)(nil)).
+		exist, err := m.rdb.NewSelect().Model((*Tags)(nil)).
 			Where("uid = ?", tag.Uid).
 			Exists(context.Background())
 		if err != nil {
@@ -88,25 +123,26 @@ func (m *TagsModel) Delete(ctx context.Context, id int64) error {
 
 func (m *TagsModel) Get(ctx context.Context, id int64) (*Tags, error) {
 	var tag Tags
-	err := m.db.NewSelect().Model(&tag).Where("id = ?", id).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&tag).Where("id = ?", id).Scan(ctx)
 	return &tag, err
 }
 
 func (m *TagsModel) GetUid(ctx context.Context, uid string) (*Tags, error) {
 	var tag Tags
-	err := m.db.NewSelect().Model(&tag).Where("uid = ?", uid).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&tag).Where("uid = ?", uid).Scan(ctx)
 	return &tag, err
 }
 
 func (m *TagsModel) GetUids(ctx context.Context, uids []string) ([]*Tags, error) {
 	var tags []*Tags
-	err := m.db.NewSelect().Model(&tags).Where("uid IN (?)", bun.In(uids)).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&tags).Where("uid IN (?)", bun.In(uids)).Scan(ctx)
 	return tags, err
 }
 
+// GetName 按规范化的名称查找标签
 func (m *TagsModel) GetName(ctx context.Context, name string) (*Tags, error) {
 	var tag Tags
-	err := m.db.NewSelect().Model(&tag).Where("name = ?", name).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&tag).Where("name_key = ?", tagname.Key(name)).Order("id ASC").Limit(1).Scan(ctx)
 	return &tag, err
 }
 
@@ -119,11 +155,11 @@ type TagsList struct {
 // GetList 分页查询标签列表
 func (m *TagsModel) GetList(ctx context.Context, page, size int64, name string) (*TagsList, error) {
 	// 构建查询
-	query := m.db.NewSelect().Model((*Tags)(nil))
+	query := m.rdb.NewSelect().Model((*Tags)(nil))
 
 	// 添加条件
 	if name != "" {
-		query = query.Where("name LIKE ?", "%"+name+"%")
+		query = whereContains(m.rdb, query, "name", name)
 	}
 
 	// 获取总记录数
@@ -151,13 +187,571 @@ func (m *TagsModel) GetList(ctx context.Context, page, size int64, name string)
 	}, nil
 }
 
+// FindBatchByNames 按规范化的名称和别名查找标签, 多个名称指向同一个标签时只返回一次
 func (m *TagsModel) FindBatchByNames(ctx context.Context, names []string) ([]*Tags, error) {
+	matched, err := m.MatchNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]*Tags, 0, len(matched))
+	seen := make(map[string]bool, len(matched))
+	for _, name := range names {
+		if tag, ok := matched[name]; ok && !seen[tag.Uid] {
+			seen[tag.Uid] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// MatchNames 按规范化的名称查找标签, 名称没有匹配时按别名查找, 返回名称到标签的映射, 没有匹配的名称不在结果中
+// 大小写、全角半角、空白和编程语言名称的语言后缀不同的名称视为同一个标签, 历史数据中规范化后重名的标签取最早创建的
+func (m *TagsModel) MatchNames(ctx context.Context, names []string) (map[string]*Tags, error) {
+	matched := make(map[string]*Tags, len(names))
+	byKey := make(map[string]*Tags)
+	var keys []string
+	for _, name := range names {
+		if key := tagname.Key(name); key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return matched, nil
+	}
 	var tags []*Tags
-	err := m.db.NewSelect().Model(&tags).Where("name IN (?)", bun.In(names)).Scan(ctx)
-	return tags, err
+	if err := m.rdb.NewSelect().Model(&tags).Where("name_key IN (?)", bun.In(keys)).Order("id ASC").Scan(ctx); err != nil {
+		logx.Errorf("MatchNames names: %v, error: %v", names, err)
+		return nil, err
+	}
+	for _, tag := range tags {
+		if _, ok := byKey[tag.NameKey]; !ok {
+			byKey[tag.NameKey] = tag
+		}
+	}
+
+	var rest []string
+	for _, key := range keys {
+		if _, ok := byKey[key]; !ok {
+			rest = append(rest, key)
+		}
+	}
+	if len(rest) > 0 {
+		var aliases []*TagAliases
+		if err := m.rdb.NewSelect().Model(&aliases).Where("alias_key IN (?)", bun.In(rest)).Order("id ASC").Scan(ctx); err != nil {
+			logx.Errorf("MatchNames aliases: %v, error: %v", rest, err)
+			return nil, err
+		}
+		uids := make([]string, 0, len(aliases))
+		for _, alias := range aliases {
+			uids = append(uids, alias.TagUid)
+		}
+		var aliased []*Tags
+		if len(uids) > 0 {
+			if err := m.rdb.NewSelect().Model(&aliased).Where("uid IN (?)", bun.In(uids)).Scan(ctx); err != nil {
+				logx.Errorf("MatchNames aliased uids: %v, error: %v", uids, err)
+				return nil, err
+			}
+		}
+		byUid := make(map[string]*Tags, len(aliased))
+		for _, tag := range aliased {
+			byUid[tag.Uid] = tag
+		}
+		for _, alias := range aliases {
+			if _, ok := byKey[alias.AliasKey]; !ok && byUid[alias.TagUid] != nil {
+				byKey[alias.AliasKey] = byUid[alias.TagUid]
+			}
+		}
+	}
+
+	for _, name := range names {
+		if tag, ok := byKey[tagname.Key(name)]; ok {
+			matched[name] = tag
+		}
+	}
+	return matched, nil
 }
 
 func (m *TagsModel) CreateBatch(ctx context.Context, tags []Tags) error {
 	_, err := m.db.NewInsert().Model(&tags).Exec(ctx)
 	return err
 }
+
+// GetAll 获取全部标签, 用于导出
+func (m *TagsModel) GetAll(ctx context.Context) ([]*Tags, error) {
+	var tags []*Tags
+	err := m.rdb.NewSelect().Model(&tags).Order("id ASC").Scan(ctx)
+	if err != nil {
+		logx.Error("GetAll error", err)
+	}
+	return tags, err
+}
+
+// EnsureByNames 按规范化的名称和别名查找标签, 不存在的批量创建, 返回名称到唯一标识的映射
+// 规范化后相同的多个新名称只按第一个创建
+func (m *TagsModel) EnsureByNames(ctx context.Context, names []string, description string) (map[string]string, error) {
+	names = uniqueStrings(names)
+	uids := make(map[string]string, len(names))
+	if len(names) == 0 {
+		return uids, nil
+	}
+	exists, err := m.MatchNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	created := make(map[string]string)
+	newTags := make([]Tags, 0)
+	for _, name := range names {
+		if tag, ok := exists[name]; ok {
+			uids[name] = tag.Uid
+			continue
+		}
+		key := tagname.Key(name)
+		if uid, ok := created[key]; ok {
+			uids[name] = uid
+			continue
+		}
+		tag := Tags{
+			Uid:         GenUid(),
+			Name:        name,
+			Description: description,
+		}
+		uids[name] = tag.Uid
+		created[key] = tag.Uid
+		newTags = append(newTags, tag)
+	}
+	if len(newTags) > 0 {
+		if err := m.CreateBatch(ctx, newTags); err != nil {
+			logx.Errorf("EnsureByNames CreateBatch tags: %d, error: %v", len(newTags), err)
+			return nil, err
+		}
+	}
+	return uids, nil
+}
+
+// GetMissingEmbedding 获取还没有名称向量的标签
+func (m *TagsModel) GetMissingEmbedding(ctx context.Context) ([]*Tags, error) {
+	var tags []*Tags
+	err := m.rdb.NewSelect().Model(&tags).Where("embedding IS NULL").Order("id ASC").Scan(ctx)
+	if err != nil {
+		logx.Errorf("GetMissingEmbedding error: %v", err)
+	}
+	return tags, err
+}
+
+func (m *TagsModel) UpdateEmbedding(ctx context.Context, id int64, embedding pgvector.Vector) error {
+	_, err := m.db.NewUpdate().Model((*Tags)(nil)).
+		Set("embedding = ?", embedding).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		logx.Errorf("UpdateEmbedding id: %d, error: %v", id, err)
+	}
+	return err
+}
+
+// Search 按名称向量的余弦距离检索最相近的标签, 与 SegmentsModel.Search 一致
+func (m *TagsModel) Search(ctx context.Context, embedding pgvector.Vector, limit int) ([]*Tags, error) {
+	var tags []*Tags
+	if isPostgres(m.rdb) {
+		err := m.rdb.NewSelect().Model(&tags).
+			ColumnExpr("t.*").
+			ColumnExpr("t.embedding <=> ? AS distance", embedding).
+			Where("t.embedding IS NOT NULL").
+			OrderExpr("distance ASC").
+			Limit(limit).
+			Scan(ctx)
+		return tags, err
+	}
+
+	err := m.rdb.NewSelect().Model(&tags).Where("embedding IS NOT NULL").Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := embedding.Slice()
+	for _, tag := range tags {
+		tag.Distance = CosineDistance(query, tag.Embedding.Slice())
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Distance < tags[j].Distance })
+	if len(tags) > limit {
+		tags = tags[:limit]
+	}
+	return tags, nil
+}
+
+// SetParent 移动标签到父标签下, 父标签为空时作为根标签
+// 父标签不能是标签自身或其子孙标签
+func (m *TagsModel) SetParent(ctx context.Context, uid, parentUid string) error {
+	err := m.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if parentUid != "" {
+			exists, err := tx.NewSelect().Model((*Tags)(nil)).Where("uid = ?", parentUid).Exists(ctx)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return ErrTagParentNotFound
+			}
+			descendants, err := tagSubtree(ctx, tx, []string{uid})
+			if err != nil {
+				return err
+			}
+			for _, descendant := range descendants {
+				if descendant == parentUid {
+					return ErrTagCycle
+				}
+			}
+		}
+		_, err := tx.NewUpdate().Model((*Tags)(nil)).
+			Set("parent_uid = ?", parentUid).
+			Where("uid = ?", uid).
+			Exec(ctx)
+		return err
+	})
+	if err != nil && !errors.Is(err, ErrTagParentNotFound) && !errors.Is(err, ErrTagCycle) {
+		logx.Errorf("SetParent uid: %s, parentUid: %s, error: %v", uid, parentUid, err)
+	}
+	return err
+}
+
+// GetDescendantUids 返回标签及其全部子孙标签的唯一标识
+func (m *TagsModel) GetDescendantUids(ctx context.Context, uids []string) ([]string, error) {
+	return tagSubtree(ctx, m.rdb, uids)
+}
+
+// GetAncestors 返回标签的全部祖先标签, 从根标签开始
+func (m *TagsModel) GetAncestors(ctx context.Context, uid string) ([]*Tags, error) {
+	var tags []*Tags
+	err := m.rdb.NewRaw(`WITH RECURSIVE path(uid, parent_uid, depth) AS (
+			SELECT uid, parent_uid, 0 FROM tags WHERE uid = ?
+			UNION ALL
+			SELECT t.uid, t.parent_uid, path.depth + 1 FROM tags t JOIN path ON t.uid = path.parent_uid
+			WHERE path.depth < ?
+		)
+		SELECT t.* FROM path JOIN tags t ON t.uid = path.uid WHERE path.depth > 0 ORDER BY path.depth DESC`,
+		uid, maxTagDepth).Scan(ctx, &tags)
+	if err != nil {
+		logx.Errorf("GetAncestors uid: %s, error: %v", uid, err)
+	}
+	return tags, err
+}
+
+// tagSubtree 返回标签及其全部子孙标签的唯一标识, 不存在的标签原样返回
+// 递归使用 UNION 去重, 数据中存在环时也能结束
+func tagSubtree(ctx context.Context, db bun.IDB, uids []string) ([]string, error) {
+	uids = uniqueStrings(uids)
+	if len(uids) == 0 {
+		return uids, nil
+	}
+	var descendants []string
+	err := db.NewRaw(`WITH RECURSIVE tree(uid) AS (
+			SELECT uid FROM tags WHERE parent_uid IN (?)
+			UNION
+			SELECT t.uid FROM tags t JOIN tree ON t.parent_uid = tree.uid
+		)
+		SELECT uid FROM tree`, bun.In(uids)).Scan(ctx, &descendants)
+	if err != nil {
+		return nil, err
+	}
+	return uniqueStrings(append(uids, descendants...)), nil
+}
+
+// MergeResult 合并标签的结果
+type MergeResult struct {
+	Merged    int64 // 合并的标签数
+	Resources int64 // 关联被合并标签的资源数
+}
+
+// Merge 将多个标签合并到目标标签, 在同一个事务中完成
+// 资源关联和规则改为目标标签, 子标签移到目标标签下, 默认标签不能被合并, 被合并标签的名称和别名作为目标标签的别名, 最后删除被合并的标签
+func (m *TagsModel) Merge(ctx context.Context, targetUid string, sourceUids []string) (*MergeResult, error) {
+	result := &MergeResult{}
+	err := m.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		sourceUids = uniqueStrings(sourceUids)
+		exists, err := tx.NewSelect().Model((*Tags)(nil)).Where("uid = ?", targetUid).Exists(ctx)
+		if err != nil {
+			return err
+		}
+		var sources []*Tags
+		if err := tx.NewSelect().Model(&sources).Where("uid IN (?)", bun.In(sourceUids)).Scan(ctx); err != nil {
+			return err
+		}
+		if !exists || len(sources) != len(sourceUids) {
+			return ErrTagNotFound
+		}
+		if slices.Contains(sourceUids, DefaultTagUid) {
+			return ErrTagProtected
+		}
+		// 目标标签是被合并标签的子孙标签时, 移动子标签会成环
+		subtree, err := tagSubtree(ctx, tx, sourceUids)
+		if err != nil {
+			return err
+		}
+		for _, uid := range subtree {
+			if uid == targetUid {
+				return ErrTagMergeTarget
+			}
+		}
+
+		err = tx.NewSelect().Model((*ResourceTags)(nil)).
+			ColumnExpr("COUNT(DISTINCT resource_id)").
+			Where("tag_uid IN (?)", bun.In(sourceUids)).
+			Scan(ctx, &result.Resources)
+		if err != nil {
+			return err
+		}
+		if err := moveResourceTags(ctx, tx, targetUid, sourceUids); err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().Model((*TagRules)(nil)).
+			Set("tag_uid = ?", targetUid).
+			Where("tag_uid IN (?)", bun.In(sourceUids)).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().Model((*Tags)(nil)).
+			Set("parent_uid = ?", targetUid).
+			Where("parent_uid IN (?)", bun.In(sourceUids)).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewUpdate().Model((*TagAliases)(nil)).
+			Set("tag_uid = ?", targetUid).
+			Where("tag_uid IN (?)", bun.In(sourceUids)).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		// 规范化后相同的名称只添加一个别名, 与其他标签的别名相同时改为目标标签的别名
+		aliases := make([]*TagAliases, 0, len(sources))
+		keys := make(map[string]bool, len(sources))
+		for _, source := range sources {
+			if key := tagname.Key(source.Name); !keys[key] {
+				keys[key] = true
+				aliases = append(aliases, &TagAliases{Alias: source.Name, TagUid: targetUid})
+			}
+		}
+		_, err = tx.NewInsert().Model(&aliases).
+			On("CONFLICT (alias_key) DO UPDATE").
+			Set("tag_uid = EXCLUDED.tag_uid").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewDelete().Model((*Tags)(nil)).Where("uid IN (?)", bun.In(sourceUids)).Exec(ctx)
+		result.Merged = int64(len(sources))
+		return err
+	})
+	if err != nil && !errors.Is(err, ErrTagNotFound) && !errors.Is(err, ErrTagMergeTarget) && !errors.Is(err, ErrTagProtected) {
+		logx.Errorf("Merge targetUid: %s, sourceUids: %v, error: %v", targetUid, sourceUids, err)
+	}
+	return result, err
+}
+
+// SplitResult 拆分标签的结果
+type SplitResult struct {
+	Assigned      int64 // 改为新标签的资源数
+	Remaining     int64 // 仍关联原标签的资源数
+	SourceDeleted bool  // 原标签是否已删除
+}
+
+// Split 将原标签的资源关联按分配结果改为新标签, 保留原关联的置信度和原始标签
+// 未分配的资源保留原标签, removeSource 为 true 且原标签没有资源时删除原标签, 子标签移到原标签的父标签下
+// 默认标签和被规则引用的标签不会删除
+func (m *TagsModel) Split(ctx context.Context, sourceUid string, assignments map[int64][]string, removeSource bool) (*SplitResult, error) {
+	result := &SplitResult{}
+	err := m.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var source Tags
+		err := tx.NewSelect().Model(&source).Where("uid = ?", sourceUid).Scan(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTagNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var links []*ResourceTags
+		if err := tx.NewSelect().Model(&links).Where("tag_uid = ?", sourceUid).Scan(ctx); err != nil {
+			return err
+		}
+		for _, link := range links {
+			var targets []*ResourceTags
+			for _, uid := range uniqueStrings(assignments[link.ResourceID]) {
+				if uid != sourceUid {
+					targets = append(targets, &ResourceTags{
+						ResourceID: link.ResourceID,
+						TagUid:     uid,
+						Confidence: link.Confidence,
+						Label:      link.Label,
+					})
+				}
+			}
+			if len(targets) == 0 {
+				result.Remaining++
+				continue
+			}
+			_, err := tx.NewInsert().Model(&targets).On("CONFLICT (resource_id, tag_uid) DO NOTHING").Exec(ctx)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.NewDelete().Model(link).WherePK().Exec(ctx); err != nil {
+				return err
+			}
+			result.Assigned++
+		}
+		if !removeSource || result.Remaining > 0 || sourceUid == DefaultTagUid {
+			return nil
+		}
+		// 被规则引用的标签保留, 避免规则失效
+		rules, err := tx.NewSelect().Model((*TagRules)(nil)).Where("tag_uid = ?", sourceUid).Count(ctx)
+		if err != nil || rules > 0 {
+			return err
+		}
+
+		_, err = tx.NewUpdate().Model((*Tags)(nil)).
+			Set("parent_uid = ?", source.ParentUid).
+			Where("parent_uid = ?", sourceUid).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		// 原标签的含义已拆分, 别名不再指向任何标签
+		if _, err := tx.NewDelete().Model((*TagAliases)(nil)).Where("tag_uid = ?", sourceUid).Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := tx.NewDelete().Model(&source).WherePK().Exec(ctx); err != nil {
+			return err
+		}
+		result.SourceDeleted = true
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrTagNotFound) {
+		logx.Errorf("Split sourceUid: %s, assignments: %d, error: %v", sourceUid, len(assignments), err)
+	}
+	return result, err
+}
+
+// TagReferences 标签的引用情况
+type TagReferences struct {
+	Resources int64 // 关联的资源数
+	Children  int64 // 子标签数
+	Rules     int64 // 引用标签的规则数
+}
+
+// References 查询标签的引用情况
+func (m *TagsModel) References(ctx context.Context, uid string) (*TagReferences, error) {
+	refs := &TagReferences{}
+	exists, err := m.rdb.NewSelect().Model((*Tags)(nil)).Where("uid = ?", uid).Exists(ctx)
+	if err == nil && !exists {
+		return refs, ErrTagNotFound
+	}
+	if err == nil {
+		err = countTagReferences(ctx, m.rdb, uid, refs)
+	}
+	if err != nil && !errors.Is(err, ErrTagNotFound) {
+		logx.Errorf("References uid: %s, error: %v", uid, err)
+	}
+	return refs, err
+}
+
+// Remove 按策略处理资源关联后删除标签, 在同一个事务中完成
+// 默认标签和被规则引用的标签不能删除, 子标签移到被删除标签的父标签下, 标签的别名一并删除
+func (m *TagsModel) Remove(ctx context.Context, uid, strategy, targetUid string) (*TagReferences, error) {
+	refs := &TagReferences{}
+	err := m.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var tag Tags
+		err := tx.NewSelect().Model(&tag).Where("uid = ?", uid).Scan(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTagNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if uid == DefaultTagUid {
+			return ErrTagProtected
+		}
+		if err := countTagReferences(ctx, tx, uid, refs); err != nil {
+			return err
+		}
+		if refs.Rules > 0 {
+			return ErrTagInUse
+		}
+
+		switch strategy {
+		case TagDeleteForbid:
+			if refs.Resources > 0 {
+				return ErrTagReferenced
+			}
+		case TagDeleteReassign:
+			exists, err := tx.NewSelect().Model((*Tags)(nil)).Where("uid = ?", targetUid).Exists(ctx)
+			if err != nil {
+				return err
+			}
+			if !exists || targetUid == uid {
+				return ErrTagReassignTarget
+			}
+			if err := moveResourceTags(ctx, tx, targetUid, []string{uid}); err != nil {
+				return err
+			}
+		case TagDeleteDetach:
+		default:
+			return fmt.Errorf("unknown delete strategy %q", strategy)
+		}
+		if _, err := tx.NewDelete().Model((*ResourceTags)(nil)).Where("tag_uid = ?", uid).Exec(ctx); err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().Model((*Tags)(nil)).
+			Set("parent_uid = ?", tag.ParentUid).
+			Where("parent_uid = ?", uid).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.NewDelete().Model((*TagAliases)(nil)).Where("tag_uid = ?", uid).Exec(ctx); err != nil {
+			return err
+		}
+		_, err = tx.NewDelete().Model(&tag).WherePK().Exec(ctx)
+		return err
+	})
+	switch {
+	case err == nil, errors.Is(err, ErrTagNotFound), errors.Is(err, ErrTagProtected), errors.Is(err, ErrTagInUse),
+		errors.Is(err, ErrTagReferenced), errors.Is(err, ErrTagReassignTarget):
+	default:
+		logx.Errorf("Remove uid: %s, strategy: %s, targetUid: %s, error: %v", uid, strategy, targetUid, err)
+	}
+	return refs, err
+}
+
+// countTagReferences 统计标签关联的资源、子标签和引用标签的规则
+func countTagReferences(ctx context.Context, db bun.IDB, uid string, refs *TagReferences) error {
+	err := db.NewSelect().Model((*ResourceTags)(nil)).
+		ColumnExpr("COUNT(DISTINCT resource_id)").
+		Where("tag_uid = ?", uid).
+		Scan(ctx, &refs.Resources)
+	if err != nil {
+		return err
+	}
+	children, err := db.NewSelect().Model((*Tags)(nil)).Where("parent_uid = ?", uid).Count(ctx)
+	if err != nil {
+		return err
+	}
+	rules, err := db.NewSelect().Model((*TagRules)(nil)).Where("tag_uid = ?", uid).Count(ctx)
+	if err != nil {
+		return err
+	}
+	refs.Children, refs.Rules = int64(children), int64(rules)
+	return nil
+}
+
+// moveResourceTags 将原标签的资源关联改为目标标签
+// 已关联目标标签的资源保留原关联, 有手动关联的资源保持手动关联
+func moveResourceTags(ctx context.Context, db bun.IDB, targetUid string, sourceUids []string) error {
+	_, err := db.ExecContext(ctx, `INSERT INTO resource_tags (resource_id, tag_uid, confidence, label, created_at)
+		SELECT resource_id, ?, MAX(confidence), MIN(label), ? FROM resource_tags WHERE tag_uid IN (?) GROUP BY resource_id
+		ON CONFLICT (resource_id, tag_uid) DO NOTHING`, targetUid, time.Now(), bun.In(sourceUids))
+	if err != nil {
+		return err
+	}
+	_, err = db.NewDelete().Model((*ResourceTags)(nil)).Where("tag_uid IN (?)", bun.In(sourceUids)).Exec(ctx)
+	return err
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var _ TagAliasesGen = (*TagAliasesModel)(nil)
+
+// ErrTagAliasExists 别名规范化后与已有别名相同
+var ErrTagAliasExists = errors.New("tag alias already exists")
+
+type TagAliasesModel struct {
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
+}
+
+func NewTagAliasesModel(db *DB) *TagAliasesModel {
+	return &TagAliasesModel{
+		db:  db.Writer,
+		rdb: db.Reader,
+	}
+}
+
+// TableName 返回表名
+func (m *TagAliasesModel) TableName() string {
+	return "tag_aliases"
+}
+
+func (m *TagAliasesModel) InitData() {
+
+}
+
+func (m *TagAliasesModel) GetAll(ctx context.Context) ([]*TagAliases, error) {
+	var aliases []*TagAliases
+	err := m.rdb.NewSelect().Model(&aliases).Order("id ASC").Scan(ctx)
+	if err != nil {
+		logx.Errorf("GetAll tag aliases error: %v", err)
+	}
+	return aliases, err
+}
+
+func (m *TagAliasesModel) GetByTag(ctx context.Context, tagUid string) ([]*TagAliases, error) {
+	var aliases []*TagAliases
+	err := m.rdb.NewSelect().Model(&aliases).Where("tag_uid = ?", tagUid).Order("id ASC").Scan(ctx)
+	return aliases, err
+}
+
+// CreateBatch 为标签添加别名, 规范化后与已有别名相同时返回 ErrTagAliasExists
+func (m *TagAliasesModel) CreateBatch(ctx context.Context, aliases []*TagAliases) error {
+	_, err := m.db.NewInsert().Model(&aliases).Exec(ctx)
+	if isUniqueViolation(err) {
+		return ErrTagAliasExists
+	}
+	if err != nil {
+		logx.Errorf("CreateBatch tag aliases: %d, error: %v", len(aliases), err)
+	}
+	return err
+}
+
+func (m *TagAliasesModel) Get(ctx context.Context, id int64) (*TagAliases, error) {
+	var alias TagAliases
+	err := m.rdb.NewSelect().Model(&alias).Where("id = ?", id).Scan(ctx)
+	return &alias, err
+}
+
+func (m *TagAliasesModel) Delete(ctx context.Context, id int64) error {
+	_, err := m.db.NewDelete().Model((*TagAliases)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		logx.Errorf("Delete tag alias id: %d, error: %v", id, err)
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/XXueTu/wise/internal/tagname"
+)
+
+// TagAliases 标签别名
+type TagAliases struct {
+	bun.BaseModel `bun:"table:tag_aliases,alias:ta"`
+
+	ID        int64     `bun:"id,pk,autoincrement" json:"id"`
+	Alias     string    `bun:"alias,notnull" json:"alias"`     // 别名
+	AliasKey  string    `bun:"alias_key,notnull" json:"-"`     // 规范化的别名
+	TagUid    string    `bun:"tag_uid,notnull" json:"tag_uid"` // 标签唯一标识
+	CreatedAt time.Time `bun:"created_at,notnull" json:"created_at"`
+}
+
+type TagAliasesGen interface {
+	TableName() string
+	InitData()
+	GetAll(ctx context.Context) ([]*TagAliases, error)
+	GetByTag(ctx context.Context, tagUid string) ([]*TagAliases, error)
+	CreateBatch(ctx context.Context, aliases []*TagAliases) error
+	Get(ctx context.Context, id int64) (*TagAliases, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+func (m *TagAliases) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	if _, ok := query.(*bun.InsertQuery); ok {
+		m.AliasKey = tagname.Key(m.Alias)
+		m.CreatedAt = time.Now()
+	}
+	return nil
+}
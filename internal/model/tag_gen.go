@@ -4,21 +4,29 @@ import (
 	"context"
 	"time"
 
+	"github.com/pgvector/pgvector-go"
 	"github.com/uptrace/bun"
+
+	"github.com/XXueTu/wise/internal/tagname"
 )
 
 // Tags 标签集合
 type Tags struct {
 	bun.BaseModel `bun:"table:tags,alias:t"`
 
-	ID          int64     `bun:"id,pk,autoincrement" json:"id"`
-	Uid         string    `bun:"uid,notnull" json:"uid"`                 // 标签唯一标识
-	Name        string    `bun:"name,notnull" json:"name"`               // 标签名称
-	Description string    `bun:"description,notnull" json:"description"` // 标签描述
-	Color       string    `bun:"color,notnull" json:"color"`             // 标签颜色
-	Icon        string    `bun:"icon,notnull" json:"icon"`               // 标签图标
-	CreatedAt   time.Time `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
-	UpdatedAt   time.Time `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
+	ID          int64            `bun:"id,pk,autoincrement" json:"id"`
+	Uid         string           `bun:"uid,notnull" json:"uid"`                 // 标签唯一标识
+	Name        string           `bun:"name,notnull" json:"name"`               // 标签名称
+	NameKey     string           `bun:"name_key,notnull" json:"-"`              // 规范化的名称, 由名称生成
+	Description string           `bun:"description,notnull" json:"description"` // 标签描述
+	Color       string           `bun:"color,notnull" json:"color"`             // 标签颜色
+	Icon        string           `bun:"icon,notnull" json:"icon"`               // 标签图标
+	ParentUid   string           `bun:"parent_uid,notnull" json:"parent_uid"`   // 父标签唯一标识, 根标签为空
+	Embedding   *pgvector.Vector `bun:"embedding,type:vector" json:"-"`         // 名称向量, 用于归并相近的标签, 未生成时为空
+	CreatedAt   time.Time        `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt   time.Time        `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
+
+	Distance float64 `bun:"distance,scanonly" json:"-"` // 检索时与查询向量的余弦距离
 }
 
 type TagGen interface {
@@ -33,15 +41,32 @@ type TagGen interface {
 	GetName(ctx context.Context, name string) (*Tags, error)
 	GetList(ctx context.Context, page, size int64, name string) (*TagsList, error)
 	FindBatchByNames(ctx context.Context, names []string) ([]*Tags, error)
+	MatchNames(ctx context.Context, names []string) (map[string]*Tags, error)
 	CreateBatch(ctx context.Context, tags []Tags) error
+	EnsureByNames(ctx context.Context, names []string, description string) (map[string]string, error)
+	GetAll(ctx context.Context) ([]*Tags, error)
+	GetMissingEmbedding(ctx context.Context) ([]*Tags, error)
+	UpdateEmbedding(ctx context.Context, id int64, embedding pgvector.Vector) error
+	Search(ctx context.Context, embedding pgvector.Vector, limit int) ([]*Tags, error)
+	SetParent(ctx context.Context, uid, parentUid string) error
+	GetDescendantUids(ctx context.Context, uids []string) ([]string, error)
+	GetAncestors(ctx context.Context, uid string) ([]*Tags, error)
+	Merge(ctx context.Context, targetUid string, sourceUids []string) (*MergeResult, error)
+	Split(ctx context.Context, sourceUid string, assignments map[int64][]string, removeSource bool) (*SplitResult, error)
+	References(ctx context.Context, uid string) (*TagReferences, error)
+	Remove(ctx context.Context, uid, strategy, targetUid string) (*TagReferences, error)
 }
 
-func (m *Tags) BeforeInsert(ctx context.Context, query *bun.InsertQuery) error {
-	m.CreatedAt = time.Now()
-	return nil
-}
-
-func (m *Tags) BeforeUpdate(ctx context.Context, query *bun.UpdateQuery) error {
-	m.UpdatedAt = time.Now()
+// BeforeAppendModel 在写入前生成规范化的名称并设置时间, BeforeInsert/BeforeUpdate 作用在零值模型上不会生效
+func (m *Tags) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		m.NameKey = tagname.Key(m.Name)
+		m.CreatedAt = time.Now()
+		m.UpdatedAt = m.CreatedAt
+	case *bun.UpdateQuery:
+		m.NameKey = tagname.Key(m.Name)
+		m.UpdatedAt = time.Now()
+	}
 	return nil
 }
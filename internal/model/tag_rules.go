@@ -0,0 +1,102 @@
+package model
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var _ TagRulesGen = (*TagRulesModel)(nil)
+
+type TagRulesModel struct {
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
+}
+
+func NewTagRulesModel(db *DB) *TagRulesModel {
+	return &TagRulesModel{
+		db:  db.Writer,
+		rdb: db.Reader,
+	}
+}
+
+// TableName 返回表名
+func (m *TagRulesModel) TableName() string {
+	return "tag_rules"
+}
+
+func (m *TagRulesModel) InitData() {
+
+}
+
+func (m *TagRulesModel) Create(ctx context.Context, rule *TagRules) error {
+	_, err := m.db.NewInsert().Model(rule).Exec(ctx)
+	if err != nil {
+		logx.Errorf("Create rule: %s, error: %v", rule.Name, err)
+	}
+	return err
+}
+
+func (m *TagRulesModel) Update(ctx context.Context, rule *TagRules) error {
+	_, err := m.db.NewUpdate().Model(rule).WherePK().Exec(ctx)
+	if err != nil {
+		logx.Errorf("Update uid: %s, error: %v", rule.Uid, err)
+	}
+	return err
+}
+
+func (m *TagRulesModel) Delete(ctx context.Context, uid string) error {
+	_, err := m.db.NewDelete().Model((*TagRules)(nil)).Where("uid = ?", uid).Exec(ctx)
+	if err != nil {
+		logx.Errorf("Delete uid: %s, error: %v", uid, err)
+	}
+	return err
+}
+
+func (m *TagRulesModel) GetUid(ctx context.Context, uid string) (*TagRules, error) {
+	var rule TagRules
+	err := m.rdb.NewSelect().Model(&rule).Where("uid = ?", uid).Scan(ctx)
+	return &rule, err
+}
+
+func (m *TagRulesModel) GetUids(ctx context.Context, uids []string) ([]*TagRules, error) {
+	var rules []*TagRules
+	err := m.rdb.NewSelect().Model(&rules).
+		Where("uid IN (?)", bun.In(uids)).
+		Order("priority ASC", "id ASC").
+		Scan(ctx)
+	return rules, err
+}
+
+// GetAll 获取全部规则, 按执行顺序排序
+func (m *TagRulesModel) GetAll(ctx context.Context) ([]*TagRules, error) {
+	var rules []*TagRules
+	err := m.rdb.NewSelect().Model(&rules).Order("priority ASC", "id ASC").Scan(ctx)
+	if err != nil {
+		logx.Errorf("GetAll error: %v", err)
+	}
+	return rules, err
+}
+
+// GetEnabled 获取启用的规则, 按执行顺序排序
+func (m *TagRulesModel) GetEnabled(ctx context.Context) ([]*TagRules, error) {
+	var rules []*TagRules
+	err := m.rdb.NewSelect().Model(&rules).
+		Where("enabled = ?", true).
+		Order("priority ASC", "id ASC").
+		Scan(ctx)
+	if err != nil {
+		logx.Errorf("GetEnabled error: %v", err)
+	}
+	return rules, err
+}
+
+// CountByTags 统计引用标签的规则数
+func (m *TagRulesModel) CountByTags(ctx context.Context, tagUids []string) (int64, error) {
+	if len(tagUids) == 0 {
+		return 0, nil
+	}
+	count, err := m.rdb.NewSelect().Model((*TagRules)(nil)).Where("tag_uid IN (?)", bun.In(tagUids)).Count(ctx)
+	return int64(count), err
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// 标签规则的匹配方式
+const (
+	TagRuleTypeDomain       = "domain"        // URL 域名, 包含子域名
+	TagRuleTypeURLRegex     = "url_regex"     // URL 正则
+	TagRuleTypeKeyword      = "keyword"       // 标题或内容包含关键词, 忽略大小写
+	TagRuleTypeResourceType = "resource_type" // 资源类型
+	TagRuleTypeLabel        = "label"         // 大模型返回的原始标签, 忽略大小写
+)
+
+// 关键词规则匹配的字段
+const (
+	TagRuleFieldTitle   = "title"
+	TagRuleFieldContent = "content"
+)
+
+// 标签规则的动作
+const (
+	TagRuleActionAdd    = "add"
+	TagRuleActionRemove = "remove"
+)
+
+// TagRules 自定义标签规则
+type TagRules struct {
+	bun.BaseModel `bun:"table:tag_rules,alias:tr"`
+
+	ID        int64     `bun:"id,pk,autoincrement" json:"id"`
+	Uid       string    `bun:"uid,notnull" json:"uid"`           // 规则唯一标识
+	Name      string    `bun:"name,notnull" json:"name"`         // 规则名称
+	Type      string    `bun:"type,notnull" json:"type"`         // 匹配方式
+	Field     string    `bun:"field,notnull" json:"field"`       // keyword 匹配的字段, 为空时匹配标题和内容
+	Pattern   string    `bun:"pattern,notnull" json:"pattern"`   // 匹配内容
+	Action    string    `bun:"action,notnull" json:"action"`     // 添加或移除标签
+	TagUid    string    `bun:"tag_uid,notnull" json:"tag_uid"`   // 标签唯一标识
+	Priority  int64     `bun:"priority,notnull" json:"priority"` // 优先级, 数值小的先执行
+	Enabled   bool      `bun:"enabled,notnull" json:"enabled"`   // 是否启用
+	CreatedAt time.Time `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt time.Time `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
+}
+
+type TagRulesGen interface {
+	TableName() string
+	InitData()
+	Create(ctx context.Context, rule *TagRules) error
+	Update(ctx context.Context, rule *TagRules) error
+	Delete(ctx context.Context, uid string) error
+	GetUid(ctx context.Context, uid string) (*TagRules, error)
+	GetUids(ctx context.Context, uids []string) ([]*TagRules, error)
+	GetAll(ctx context.Context) ([]*TagRules, error)
+	GetEnabled(ctx context.Context) ([]*TagRules, error)
+	CountByTags(ctx context.Context, tagUids []string) (int64, error)
+}
+
+func (m *TagRules) BeforeInsert(ctx context.Context, query *bun.InsertQuery) error {
+	m.CreatedAt = time.Now()
+	m.UpdatedAt = m.CreatedAt
+	return nil
+}
+
+func (m *TagRules) BeforeUpdate(ctx context.Context, query *bun.UpdateQuery) error {
+	m.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var _ TaskPausesGen = (*TaskPausesModel)(nil)
+
+type TaskPausesModel struct {
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
+}
+
+func NewTaskPausesModel(db *DB) *TaskPausesModel {
+	return &TaskPausesModel{
+		db:  db.Writer,
+		rdb: db.Reader,
+	}
+}
+
+// TableName 返回表名
+func (m *TaskPausesModel) TableName() string {
+	return TaskPausesTable
+}
+
+func (m *TaskPausesModel) InitData() {
+
+}
+
+// Pause 暂停所有实例领取任务直到 until, 相同原因重复暂停时延长截止时间
+func (m *TaskPausesModel) Pause(ctx context.Context, name string, until time.Time) error {
+	_, err := m.db.NewInsert().
+		Model(&TaskPauses{Name: name, PausedUntil: until}).
+		On("CONFLICT (name) DO UPDATE").
+		Set("paused_until = EXCLUDED.paused_until").
+		Exec(ctx)
+	if err != nil {
+		logx.Errorf("Pause name: %s, error: %v", name, err)
+	}
+	return err
+}
+
+// Resume 解除暂停
+func (m *TaskPausesModel) Resume(ctx context.Context, name string) error {
+	_, err := m.db.NewDelete().Model((*TaskPauses)(nil)).Where("name = ?", name).Exec(ctx)
+	if err != nil {
+		logx.Errorf("Resume name: %s, error: %v", name, err)
+	}
+	return err
+}
+
+// Paused 是否存在未到期的暂停, 读写连接读取, 暂停后立即可见
+func (m *TaskPausesModel) Paused(ctx context.Context, now time.Time) (bool, error) {
+	return m.db.NewSelect().Model((*TaskPauses)(nil)).Where("paused_until > ?", now).Exists(ctx)
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// TaskPauses 任务调度暂停, 暂停期间所有实例都不领取任务
+type TaskPauses struct {
+	bun.BaseModel `bun:"table:task_pauses,alias:tp"`
+
+	Name        string    `bun:"name,pk" json:"name"`                      // 暂停原因
+	PausedUntil time.Time `bun:"paused_until,notnull" json:"paused_until"` // 暂停截止时间, 到期自动解除
+}
+
+type TaskPausesGen interface {
+	TableName() string
+	InitData()
+	Pause(ctx context.Context, name string, until time.Time) error
+	Resume(ctx context.Context, name string) error
+	Paused(ctx context.Context, now time.Time) (bool, error)
+}
+
+const (
+	TaskPausesTable  = "task_pauses"
+	TaskPauseRestore = "restore" // 恢复备份
+)
@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"time"
 
 	"github.com/uptrace/bun"
 	"github.com/zeromicro/go-zero/core/logx"
@@ -10,12 +11,14 @@ import (
 var _ TaskPlansGen = (*TaskPlansModel)(nil)
 
 type TaskPlansModel struct {
-	db *bun.DB
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
 }
 
-func NewTaskPlansModel(db *bun.DB) *TaskPlansModel {
+func NewTaskPlansModel(db *DB) *TaskPlansModel {
 	return &TaskPlansModel{
-		db: db,
+		db:  db.Writer,
+		rdb: db.Reader,
 	}
 }
 
@@ -72,24 +75,48 @@ func (m *TaskPlansModel) Delete(ctx context.Context, id int64) error {
 
 func (m *TaskPlansModel) Get(ctx context.Context, id int64) (*TaskPlans, error) {
 	var taskPlans TaskPlans
-	err := m.db.NewSelect().Model(&taskPlans).Where("id = ?", id).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&taskPlans).Where("id = ?", id).Scan(ctx)
 	return &taskPlans, err
 }
 
 func (m *TaskPlansModel) GetInitByTid(ctx context.Context, tid string) ([]*TaskPlans, error) {
 	var taskPlans []*TaskPlans
-	err := m.db.NewSelect().Model(&taskPlans).Where("tid = ?", tid).Where("status = ?", TaskPlanStatusInit).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&taskPlans).Where("tid = ?", tid).Where("status = ?", TaskPlanStatusInit).Scan(ctx)
 	return taskPlans, err
 }
 
 func (m *TaskPlansModel) GetByTid(ctx context.Context, tid string) ([]*TaskPlans, error) {
 	var taskPlans []*TaskPlans
-	err := m.db.NewSelect().Model(&taskPlans).Where("tid = ?", tid).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&taskPlans).Where("tid = ?", tid).Scan(ctx)
 	return taskPlans, err
 }
 
 func (m *TaskPlansModel) GetByPid(ctx context.Context, pid string) (*TaskPlans, error) {
 	var taskPlans TaskPlans
-	err := m.db.NewSelect().Model(&taskPlans).Where("pid = ?", pid).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&taskPlans).Where("pid = ?", pid).Scan(ctx)
 	return &taskPlans, err
 }
+
+// FailUnfinished 将任务下未完成的计划标记为失败
+func (m *TaskPlansModel) FailUnfinished(ctx context.Context, tid string, errMsg string) error {
+	_, err := m.db.NewUpdate().Model((*TaskPlans)(nil)).
+		Set("status = ?", TaskPlanStatusFailed).
+		Set("error = ?", errMsg).
+		Set("updated_at = ?", time.Now()).
+		Where("tid = ?", tid).
+		Where("status IN (?)", bun.In([]string{TaskPlanStatusInit, TaskPlanStatusRunning})).
+		Exec(ctx)
+	if err != nil {
+		logx.Errorf("FailUnfinished tid: %s, error: %v", tid, err)
+	}
+	return err
+}
+
+// GetRange 获取时间范围内创建的任务计划, 只查询统计需要的字段
+func (m *TaskPlansModel) GetRange(ctx context.Context, start time.Time, end time.Time) ([]*TaskPlans, error) {
+	var taskPlans []*TaskPlans
+	query := m.rdb.NewSelect().Model(&taskPlans).
+		Column("tid", "name", "status", "duration", "prompt_tokens", "completion_tokens")
+	err := whereCreatedBetween(m.rdb, query, start, end).Scan(ctx)
+	return taskPlans, err
+}
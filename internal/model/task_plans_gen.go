@@ -12,20 +12,25 @@ type TaskPlans struct {
 	bun.BaseModel `bun:"table:task_plans,alias:p"`
 
 	ID        int64     `bun:"id,pk,autoincrement" json:"id"`
-	Tid       string    `bun:"tid,notnull" json:"tid"`               // 任务唯一标识
-	Pid       string    `bun:"pid,notnull" json:"pid"`               // 任务计划唯一标识
-	BeforePid string    `bun:"before_pid,notnull" json:"before_pid"` // 上一个任务计划唯一标识
-	Next      string    `bun:"next,notnull" json:"next"`             // 下一个任务类型
-	Types     string    `bun:"types,notnull" json:"types"`           // 任务类型
-	Name      string    `bun:"name,notnull" json:"name"`             // 任务名称
-	Index     int64     `bun:"index,notnull" json:"index"`           // 任务计划索引
-	Status    string    `bun:"status,notnull" json:"status"`         // 任务状态 init,running,success,failed,cancelled
-	Params    string    `bun:"params,notnull" json:"params"`         // 任务参数
-	Result    string    `bun:"result,notnull" json:"result"`         // 任务结果
-	Duration  int64     `bun:"duration,notnull" json:"duration"`     // 任务耗时 ms
-	Error     string    `bun:"error,notnull" json:"error"`           // 任务错误
-	CreatedAt time.Time `bun:"created_at,notnull" json:"created_at"`
-	UpdatedAt time.Time `bun:"updated_at,notnull" json:"updated_at"`
+	Tid       string    `bun:"tid,notnull" json:"tid"`                // 任务唯一标识
+	Pid       string    `bun:"pid,notnull" json:"pid"`                // 任务计划唯一标识
+	BeforePid string    `bun:"before_pid,notnull" json:"before_pid"`  // 上一个任务计划唯一标识
+	Next      string    `bun:"next,notnull" json:"next"`              // 下一个任务类型
+	Types     string    `bun:"types,notnull" json:"types"`            // 任务类型
+	Name      string    `bun:"name,notnull" json:"name"`              // 任务名称
+	Index     int64     `bun:"index,notnull" json:"index"`            // 任务计划索引
+	Status    string    `bun:"status,notnull" json:"status"`          // 任务状态 init,running,success,failed,cancelled
+	Params    string    `bun:"params,notnull" json:"params"`          // 任务参数
+	Result    string    `bun:"result,notnull" json:"result"`          // 任务结果
+	Duration  int64     `bun:"duration,notnull" json:"duration"`      // 任务耗时 ms
+	Error     string    `bun:"error,notnull" json:"error"`            // 任务错误
+	StartedAt time.Time `bun:"started_at,nullzero" json:"started_at"` // 开始时间
+	EndedAt   time.Time `bun:"ended_at,nullzero" json:"ended_at"`     // 结束时间
+
+	PromptTokens     int64     `bun:"prompt_tokens,notnull" json:"prompt_tokens"`         // 输入 token 数
+	CompletionTokens int64     `bun:"completion_tokens,notnull" json:"completion_tokens"` // 输出 token 数
+	CreatedAt        time.Time `bun:"created_at,notnull" json:"created_at"`
+	UpdatedAt        time.Time `bun:"updated_at,notnull" json:"updated_at"`
 }
 
 type TaskPlansGen interface {
@@ -39,6 +44,8 @@ type TaskPlansGen interface {
 	GetInitByTid(ctx context.Context, tid string) ([]*TaskPlans, error)
 	GetByTid(ctx context.Context, tid string) ([]*TaskPlans, error)
 	GetByPid(ctx context.Context, pid string) (*TaskPlans, error)
+	FailUnfinished(ctx context.Context, tid string, errMsg string) error
+	GetRange(ctx context.Context, start time.Time, end time.Time) ([]*TaskPlans, error)
 }
 
 const (
@@ -2,6 +2,9 @@ package model
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/uptrace/bun"
 	"github.com/zeromicro/go-zero/core/logx"
@@ -10,12 +13,14 @@ import (
 var _ TasksGen = (*TasksModel)(nil)
 
 type TasksModel struct {
-	db *bun.DB
+	db  *bun.DB // 写连接
+	rdb *bun.DB // 只读连接池
 }
 
-func NewTasksModel(db *bun.DB) *TasksModel {
+func NewTasksModel(db *DB) *TasksModel {
 	return &TasksModel{
-		db: db,
+		db:  db.Writer,
+		rdb: db.Reader,
 	}
 }
 
@@ -63,25 +68,43 @@ func (m *TasksModel) Delete(ctx context.Context, id int64) error {
 
 func (m *TasksModel) Get(ctx context.Context, id int64) (*Tasks, error) {
 	var task Tasks
-	err := m.db.NewSelect().Model(&task).Where("id = ?", id).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&task).Where("id = ?", id).Scan(ctx)
 	return &task, err
 }
 
 func (m *TasksModel) GetStatus(ctx context.Context, status string) ([]*Tasks, error) {
 	var tasks []*Tasks
-	err := m.db.NewSelect().Model(&tasks).Where("status = ?", status).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&tasks).Where("status = ?", status).Scan(ctx)
 	return tasks, err
 }
 
 func (m *TasksModel) GetStatusLimit(ctx context.Context, status string, limit int) ([]*Tasks, error) {
 	var tasks []*Tasks
-	err := m.db.NewSelect().Model(&tasks).Where("status = ?", status).Limit(limit).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&tasks).Where("status = ?", status).Limit(limit).Scan(ctx)
+	return tasks, err
+}
+
+// GetRunnableLimit 获取可执行的任务, 包括待执行和已到重试时间的任务
+// 按优先级从高到低、同优先级先进先出排序, aging 大于 0 时等待越久优先级越高
+func (m *TasksModel) GetRunnableLimit(ctx context.Context, now time.Time, aging time.Duration, limit int) ([]*Tasks, error) {
+	var tasks []*Tasks
+	query := m.rdb.NewSelect().Model(&tasks).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("status = ?", TaskStatusInit).
+				WhereOr("status = ? AND (next_run_at IS NULL OR next_run_at <= ?)", TaskStatusRetry, now)
+		})
+	if seconds := int64(aging / time.Second); seconds > 0 {
+		query = query.OrderExpr("priority + "+waitedSecondsExpr(m.rdb)+" / ? DESC", now, seconds)
+	} else {
+		query = query.Order("priority DESC")
+	}
+	err := query.Order("id ASC").Limit(limit).Scan(ctx)
 	return tasks, err
 }
 
 func (m *TasksModel) GetByTid(ctx context.Context, tid string) (*Tasks, error) {
 	var task Tasks
-	err := m.db.NewSelect().Model(&task).Where("tid = ?", tid).Scan(ctx)
+	err := m.rdb.NewSelect().Model(&task).Where("tid = ?", tid).Scan(ctx)
 	return &task, err
 }
 
@@ -93,11 +116,11 @@ type TasksList struct {
 
 func (m *TasksModel) GetPage(ctx context.Context, page int64, pageSize int64, name string, status string, types string) (*TasksList, error) {
 	// 构建查询
-	query := m.db.NewSelect().Model((*Tasks)(nil))
+	query := m.rdb.NewSelect().Model((*Tasks)(nil))
 
 	// 添加条件
 	if name != "" {
-		query = query.Where("name LIKE ?", "%"+name+"%")
+		query = whereContains(m.rdb, query, "name", name)
 	}
 	if status != "" {
 		query = query.Where("status = ?", status)
@@ -142,25 +165,208 @@ func (m *TasksModel) UpdateState(ctx context.Context, tid string, state string,
 	return m.Update(ctx, task)
 }
 
-// UpdateStateAndStep 更新任务状态和步骤
-func (m *TasksModel) UpdateStateAndStep(ctx context.Context, tid string, state string, step int64, result string) error {
-	task, err := m.GetByTid(ctx, tid)
+// UpdateStateAndStep 更新执行中任务的状态和步骤, 只有租约持有者可以更新, 不覆盖心跳续约的租约
+func (m *TasksModel) UpdateStateAndStep(ctx context.Context, tid string, owner string, state string, step int64, result string) error {
+	_, err := m.db.NewUpdate().Model((*Tasks)(nil)).
+		Set("current_state = ?", state).
+		Set("current_step = ?", step).
+		Set("result = ?", result).
+		Set("updated_at = ?", time.Now()).
+		Where("tid = ?", tid).
+		Where("status = ?", TaskStatusRunning).
+		Where("lease_owner = ?", owner).
+		Exec(ctx)
 	if err != nil {
-		return err
+		logx.Errorf("UpdateStateAndStep tid: %s, owner: %s, error: %v", tid, owner, err)
 	}
-	task.CurrentState = state
-	task.CurrentStep = step
-	task.Result = result
-	return m.Update(ctx, task)
+	return err
 }
 
-// UpdateStatus 更新任务状态
-func (m *TasksModel) UpdateStatus(ctx context.Context, tid string, status string, error string) error {
-	task, err := m.GetByTid(ctx, tid)
+// UpdateStatus 结束执行中的任务并释放租约, 任务已取消或已被其他实例回收领取时不更新, 返回是否更新
+func (m *TasksModel) UpdateStatus(ctx context.Context, tid string, owner string, status string, error string) (bool, error) {
+	res, err := m.db.NewUpdate().Model((*Tasks)(nil)).
+		Set("status = ?", status).
+		Set("error = ?", error).
+		Set("lease_owner = ''").
+		Set("lease_until = NULL").
+		Set("updated_at = ?", time.Now()).
+		Where("tid = ?", tid).
+		Where("status = ?", TaskStatusRunning).
+		Where("lease_owner = ?", owner).
+		Exec(ctx)
 	if err != nil {
-		return err
+		logx.Errorf("UpdateStatus tid: %s, owner: %s, error: %v", tid, owner, err)
+		return false, err
 	}
-	task.Status = status
-	task.Error = error
-	return m.Update(ctx, task)
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Claim 原子领取可执行的任务并持有租约, 多个实例同时领取时只有一个成功, 调度暂停期间不能领取
+func (m *TasksModel) Claim(ctx context.Context, tid string, owner string, until time.Time, now time.Time) (bool, error) {
+	var claimed string
+	err := m.db.NewUpdate().Model((*Tasks)(nil)).
+		Set("status = ?", TaskStatusRunning).
+		Set("lease_owner = ?", owner).
+		Set("lease_until = ?", until).
+		Set("updated_at = ?", now).
+		Where("tid = ?", tid).
+		WhereGroup(" AND ", func(q *bun.UpdateQuery) *bun.UpdateQuery {
+			return q.Where("status = ?", TaskStatusInit).
+				WhereOr("status = ? AND (next_run_at IS NULL OR next_run_at <= ?)", TaskStatusRetry, now)
+		}).
+		Where("NOT EXISTS (SELECT 1 FROM ? WHERE paused_until > ?)", bun.Ident(TaskPausesTable), now).
+		Returning("tid").
+		Scan(ctx, &claimed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		logx.Errorf("Claim tid: %s, owner: %s, error: %v", tid, owner, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// Recover 回收租约过期的任务, 以原租约持有者为条件, 多个实例同时回收时只有一个成功
+func (m *TasksModel) Recover(ctx context.Context, task *Tasks, now time.Time) (bool, error) {
+	query := m.releaseQuery(task, task.LeaseOwner, now).
+		Where("lease_until IS NULL OR lease_until < ?", now)
+	return m.release(ctx, query, task.Tid)
+}
+
+// Release 执行失败或中断后写入重试状态并释放租约, 只有租约持有者可以释放, 返回是否更新
+func (m *TasksModel) Release(ctx context.Context, task *Tasks, owner string, now time.Time) (bool, error) {
+	return m.release(ctx, m.releaseQuery(task, owner, now), task.Tid)
+}
+
+// releaseQuery 写入任务的状态、重试次数、错误和下次执行时间并释放租约, 以运行中和租约持有者为条件
+func (m *TasksModel) releaseQuery(task *Tasks, owner string, now time.Time) *bun.UpdateQuery {
+	return m.db.NewUpdate().Model((*Tasks)(nil)).
+		Set("status = ?", task.Status).
+		Set("retry_count = ?", task.RetryCount).
+		Set("error = ?", task.Error).
+		Set("next_run_at = ?", task.NextRunAt).
+		Set("lease_owner = ''").
+		Set("lease_until = NULL").
+		Set("updated_at = ?", now).
+		Where("tid = ?", task.Tid).
+		Where("status = ?", TaskStatusRunning).
+		Where("lease_owner = ?", owner)
+}
+
+func (m *TasksModel) release(ctx context.Context, query *bun.UpdateQuery, tid string) (bool, error) {
+	res, err := query.Exec(ctx)
+	if err != nil {
+		logx.Errorf("Release tid: %s, error: %v", tid, err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+type leaseOwnerKey struct{}
+
+// WithLeaseOwner 记录执行任务的租约持有者, 执行过程中的更新以此为条件
+func WithLeaseOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, leaseOwnerKey{}, owner)
+}
+
+// LeaseOwner 获取执行任务的租约持有者
+func LeaseOwner(ctx context.Context) string {
+	owner, _ := ctx.Value(leaseOwnerKey{}).(string)
+	return owner
+}
+
+// RenewLease 续约, 只有租约持有者可以续约
+func (m *TasksModel) RenewLease(ctx context.Context, tid string, owner string, until time.Time) error {
+	_, err := m.db.NewUpdate().Model((*Tasks)(nil)).
+		Set("lease_until = ?", until).
+		Where("tid = ?", tid).
+		Where("lease_owner = ?", owner).
+		Where("status = ?", TaskStatusRunning).
+		Exec(ctx)
+	if err != nil {
+		logx.Errorf("RenewLease tid: %s, owner: %s, error: %v", tid, owner, err)
+	}
+	return err
+}
+
+// GetExpiredLease 获取租约已过期的运行中任务
+func (m *TasksModel) GetExpiredLease(ctx context.Context, now time.Time) ([]*Tasks, error) {
+	var tasks []*Tasks
+	err := m.rdb.NewSelect().Model(&tasks).
+		Where("status = ?", TaskStatusRunning).
+		Where("lease_until IS NULL OR lease_until < ?", now).
+		Scan(ctx)
+	return tasks, err
+}
+
+// UpdateDuration 记录任务最近一次执行的起止时间和耗时
+func (m *TasksModel) UpdateDuration(ctx context.Context, tid string, startedAt time.Time, endedAt time.Time) error {
+	_, err := m.db.NewUpdate().Model((*Tasks)(nil)).
+		Set("started_at = ?", startedAt).
+		Set("ended_at = ?", endedAt).
+		Set("duration = ?", endedAt.Sub(startedAt).Milliseconds()).
+		Where("tid = ?", tid).
+		Exec(ctx)
+	if err != nil {
+		logx.Errorf("UpdateDuration tid: %s, error: %v", tid, err)
+	}
+	return err
+}
+
+// GetRange 获取时间范围内创建的任务, 只查询统计需要的字段
+func (m *TasksModel) GetRange(ctx context.Context, start time.Time, end time.Time) ([]*Tasks, error) {
+	var tasks []*Tasks
+	query := m.rdb.NewSelect().Model(&tasks).
+		Column("tid", "types", "status", "duration")
+	err := whereCreatedBetween(m.rdb, query, start, end).Scan(ctx)
+	return tasks, err
+}
+
+// GetByTids 批量获取任务, 只查询展示进度需要的字段
+func (m *TasksModel) GetByTids(ctx context.Context, tids []string) ([]*Tasks, error) {
+	var tasks []*Tasks
+	if len(tids) == 0 {
+		return tasks, nil
+	}
+	err := m.rdb.NewSelect().Model(&tasks).
+		Column("tid", "status", "error", "retry_count").
+		Where("tid IN (?)", bun.In(tids)).
+		Scan(ctx)
+	return tasks, err
+}
+
+// ExistsActive 是否存在相同参数且未结束的任务
+func (m *TasksModel) ExistsActive(ctx context.Context, types string, params string) (bool, error) {
+	return m.rdb.NewSelect().Model((*Tasks)(nil)).
+		Where("types = ?", types).
+		Where("params = ?", params).
+		Where("status IN (?)", bun.In([]string{TaskStatusInit, TaskStatusRetry, TaskStatusRunning})).
+		Exists(ctx)
+}
+
+// RequeueFailed 将失败、死信和取消的任务重新入队, 重置重试次数, 返回重新入队的任务
+func (m *TasksModel) RequeueFailed(ctx context.Context, tids []string, now time.Time) ([]string, error) {
+	requeued := make([]string, 0)
+	if len(tids) == 0 {
+		return requeued, nil
+	}
+	err := m.db.NewUpdate().Model((*Tasks)(nil)).
+		Set("status = ?", TaskStatusInit).
+		Set("retry_count = 0").
+		Set("current_step = 0").
+		Set("error = '{}'").
+		Set("next_run_at = ?", now).
+		Set("updated_at = ?", now).
+		Where("tid IN (?)", bun.In(tids)).
+		Where("status IN (?)", bun.In([]string{TaskStatusFailed, TaskStatusDead, TaskStatusCancelled})).
+		Returning("tid").
+		Scan(ctx, &requeued)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logx.Errorf("RequeueFailed tids: %v, error: %v", tids, err)
+		return nil, err
+	}
+	return requeued, nil
 }
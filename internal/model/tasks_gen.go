@@ -24,6 +24,12 @@ type Tasks struct {
 	Duration      int64     `bun:"duration,notnull" json:"duration"`           // 任务耗时 ms
 	Error         string    `bun:"error,notnull" json:"error"`                 // 任务错误
 	Extend        string    `bun:"extend,notnull" json:"extend"`               // 扩展字段
+	NextRunAt     time.Time `bun:"next_run_at,nullzero" json:"next_run_at"`    // 下次执行时间
+	Priority      int64     `bun:"priority,notnull" json:"priority"`           // 优先级, 越大越先执行
+	LeaseOwner    string    `bun:"lease_owner,notnull" json:"lease_owner"`     // 租约持有者
+	LeaseUntil    time.Time `bun:"lease_until,nullzero" json:"lease_until"`    // 租约到期时间
+	StartedAt     time.Time `bun:"started_at,nullzero" json:"started_at"`      // 最近一次开始执行时间
+	EndedAt       time.Time `bun:"ended_at,nullzero" json:"ended_at"`          // 最近一次结束执行时间
 	CreatedAt     time.Time `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
 	UpdatedAt     time.Time `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
 }
@@ -38,10 +44,21 @@ type TasksGen interface {
 	GetByTid(ctx context.Context, tid string) (*Tasks, error)
 	GetStatus(ctx context.Context, status string) ([]*Tasks, error)
 	GetStatusLimit(ctx context.Context, status string, limit int) ([]*Tasks, error)
+	GetRunnableLimit(ctx context.Context, now time.Time, aging time.Duration, limit int) ([]*Tasks, error)
 	GetPage(ctx context.Context, page int64, pageSize int64, name string, status string, types string) (*TasksList, error)
 	UpdateState(ctx context.Context, tid string, state string, result string) error
-	UpdateStateAndStep(ctx context.Context, tid string, state string, step int64, result string) error
-	UpdateStatus(ctx context.Context, tid string, status string, error string) error
+	UpdateStateAndStep(ctx context.Context, tid string, owner string, state string, step int64, result string) error
+	UpdateStatus(ctx context.Context, tid string, owner string, status string, error string) (bool, error)
+	Claim(ctx context.Context, tid string, owner string, until time.Time, now time.Time) (bool, error)
+	Recover(ctx context.Context, task *Tasks, now time.Time) (bool, error)
+	Release(ctx context.Context, task *Tasks, owner string, now time.Time) (bool, error)
+	RenewLease(ctx context.Context, tid string, owner string, until time.Time) error
+	GetExpiredLease(ctx context.Context, now time.Time) ([]*Tasks, error)
+	UpdateDuration(ctx context.Context, tid string, startedAt time.Time, endedAt time.Time) error
+	GetRange(ctx context.Context, start time.Time, end time.Time) ([]*Tasks, error)
+	GetByTids(ctx context.Context, tids []string) ([]*Tasks, error)
+	ExistsActive(ctx context.Context, types string, params string) (bool, error)
+	RequeueFailed(ctx context.Context, tids []string, now time.Time) ([]string, error)
 }
 
 const (
@@ -51,14 +68,23 @@ const (
 	TaskStatusFailed    = "failed"    // 失败
 	TaskStatusRetry     = "retry"     // 重试中
 	TaskStatusCancelled = "cancelled" // 已取消
+	TaskStatusDead      = "dead"      // 死信, 不再自动重试
 )
 
-func (m *Tasks) BeforeInsert(ctx context.Context, query *bun.InsertQuery) error {
-	m.CreatedAt = time.Now()
-	return nil
-}
+const (
+	TaskPriorityLow    int64 = 0  // 批量导入
+	TaskPriorityNormal int64 = 5  // 普通提交
+	TaskPriorityHigh   int64 = 10 // 交互式提交
+)
 
-func (m *Tasks) BeforeUpdate(ctx context.Context, query *bun.UpdateQuery) error {
-	m.UpdatedAt = time.Now()
+// BeforeAppendModel 在写入前设置时间, BeforeInsert/BeforeUpdate 作用在零值模型上不会生效
+func (m *Tasks) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		m.CreatedAt = time.Now()
+		m.UpdatedAt = m.CreatedAt
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
 	return nil
 }
@@ -0,0 +1,51 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/internal/model"
+)
+
+const (
+	TypeSqlite = "sqlite"
+	TypeRedis  = "redis"
+)
+
+// TaskQueue 任务队列, 负责分发和领取任务
+// 任务记录始终以数据库为准, 领取通过数据库原子更新完成, 保证多个实例不会重复执行同一个任务
+type TaskQueue interface {
+	// Push 通知任务可以执行, 新建、重试、重新入队时调用
+	Push(ctx context.Context, task *model.Tasks) error
+	// Pending 获取可执行的候选任务, 按优先级排序
+	Pending(ctx context.Context, limit int) ([]*model.Tasks, error)
+	// Claim 领取任务并持有租约, 返回 false 表示任务已被其他实例领取或不再可执行
+	Claim(ctx context.Context, tid string, owner string, leaseUntil time.Time) (bool, error)
+	// Ack 任务本次执行结束, 无论成功、失败还是等待重试
+	Ack(ctx context.Context, tid string) error
+	// Close 释放队列资源
+	Close() error
+}
+
+// New 按配置创建任务队列
+func New(c config.TaskConfig, tasks *model.TasksModel) (TaskQueue, error) {
+	switch c.Queue.Type {
+	case "", TypeSqlite:
+		return NewSqliteQueue(tasks, c.Aging), nil
+	case TypeRedis:
+		return NewRedisQueue(c.Queue, tasks, c.Aging)
+	default:
+		return nil, fmt.Errorf("unknown queue type: %s", c.Queue.Type)
+	}
+}
+
+// MustNew 创建任务队列, 失败时 panic
+func MustNew(c config.TaskConfig, tasks *model.TasksModel) TaskQueue {
+	q, err := New(c, tasks)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
@@ -0,0 +1,197 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/internal/model"
+)
+
+var _ TaskQueue = (*RedisQueue)(nil)
+
+// 其他消费者超过该时长未确认的消息会被接管, 用于实例异常退出后继续分发
+const claimMinIdle = 5 * time.Minute
+
+// RedisQueue 使用 Redis Streams 分发任务, 消费组内每条消息只投递给一个实例
+// 领取仍通过数据库原子更新完成, 重复投递的消息不会导致任务重复执行
+type RedisQueue struct {
+	client   *redis.Client
+	tasks    *model.TasksModel
+	aging    time.Duration
+	stream   string
+	group    string
+	consumer string
+
+	mu   sync.Mutex
+	held map[string]string // 已读取未确认的消息, 任务唯一标识 -> 消息 id
+}
+
+func NewRedisQueue(c config.QueueConfig, tasks *model.TasksModel, aging time.Duration) (*RedisQueue, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     c.Host,
+		Password: c.Pass,
+		DB:       c.DB,
+	})
+	ctx := context.Background()
+	err := client.XGroupCreateMkStream(ctx, c.Stream, c.Group, "0").Err()
+	if err != nil && !strings.Contains(err.Error(), "BUSYGROUP") {
+		_ = client.Close()
+		return nil, fmt.Errorf("create redis stream group: %w", err)
+	}
+	hostname, _ := os.Hostname()
+	return &RedisQueue{
+		client:   client,
+		tasks:    tasks,
+		aging:    aging,
+		stream:   c.Stream,
+		group:    c.Group,
+		consumer: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		held:     make(map[string]string),
+	}, nil
+}
+
+func (q *RedisQueue) Push(ctx context.Context, task *model.Tasks) error {
+	err := q.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.stream,
+		Values: map[string]any{"tid": task.Tid},
+	}).Err()
+	if err != nil {
+		logx.Errorf("RedisQueue Push tid: %s, error: %v", task.Tid, err)
+	}
+	return err
+}
+
+// Pending 读取新消息和超时未确认的消息, 结合数据库中的任务状态筛选可执行的任务
+// 未到重试时间或因并发限制未领取的任务保留消息, 下次调度继续判断
+func (q *RedisQueue) Pending(ctx context.Context, limit int) ([]*model.Tasks, error) {
+	claimed, _, err := q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   q.stream,
+		Group:    q.group,
+		Consumer: q.consumer,
+		MinIdle:  claimMinIdle,
+		Start:    "0-0",
+		Count:    int64(limit),
+	}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		logx.Errorf("RedisQueue XAutoClaim error: %v", err)
+		return nil, err
+	}
+	q.hold(ctx, claimed)
+
+	streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    q.group,
+		Consumer: q.consumer,
+		Streams:  []string{q.stream, ">"},
+		Count:    int64(limit),
+		Block:    -1,
+	}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		logx.Errorf("RedisQueue XReadGroup error: %v", err)
+		return nil, err
+	}
+	for _, stream := range streams {
+		q.hold(ctx, stream.Messages)
+	}
+
+	q.mu.Lock()
+	tids := make([]string, 0, len(q.held))
+	for tid := range q.held {
+		tids = append(tids, tid)
+	}
+	q.mu.Unlock()
+
+	now := time.Now()
+	tasks := make([]*model.Tasks, 0, len(tids))
+	for _, tid := range tids {
+		task, err := q.tasks.GetByTid(ctx, tid)
+		if errors.Is(err, sql.ErrNoRows) {
+			_ = q.Ack(ctx, tid)
+			continue
+		}
+		if err != nil {
+			logx.Errorf("RedisQueue Pending tid: %s, error: %v", tid, err)
+			continue
+		}
+		switch {
+		case task.Status == model.TaskStatusInit:
+			tasks = append(tasks, task)
+		case task.Status == model.TaskStatusRetry:
+			if task.NextRunAt.IsZero() || !task.NextRunAt.After(now) {
+				tasks = append(tasks, task)
+			}
+		default:
+			// 已被领取或已结束, 重新入队时会再次推送
+			_ = q.Ack(ctx, tid)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		si, sj := q.score(tasks[i], now), q.score(tasks[j], now)
+		if si != sj {
+			return si > sj
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+// score 与 SQLite 队列一致, 等待每超过一个 aging 提升一级优先级
+func (q *RedisQueue) score(task *model.Tasks, now time.Time) int64 {
+	if q.aging <= 0 {
+		return task.Priority
+	}
+	return task.Priority + int64(now.Sub(task.CreatedAt)/q.aging)
+}
+
+// hold 记录读取到的消息, 同一个任务的重复消息只保留最新一条
+func (q *RedisQueue) hold(ctx context.Context, messages []redis.XMessage) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, msg := range messages {
+		tid, _ := msg.Values["tid"].(string)
+		if tid == "" {
+			_ = q.client.XAck(ctx, q.stream, q.group, msg.ID).Err()
+			continue
+		}
+		if old, ok := q.held[tid]; ok && old != msg.ID {
+			_ = q.client.XAck(ctx, q.stream, q.group, old).Err()
+		}
+		q.held[tid] = msg.ID
+	}
+}
+
+func (q *RedisQueue) Claim(ctx context.Context, tid string, owner string, leaseUntil time.Time) (bool, error) {
+	return q.tasks.Claim(ctx, tid, owner, leaseUntil, time.Now())
+}
+
+func (q *RedisQueue) Ack(ctx context.Context, tid string) error {
+	q.mu.Lock()
+	id, ok := q.held[tid]
+	delete(q.held, tid)
+	q.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	err := q.client.XAck(ctx, q.stream, q.group, id).Err()
+	if err != nil {
+		logx.Errorf("RedisQueue Ack tid: %s, error: %v", tid, err)
+	}
+	return err
+}
+
+func (q *RedisQueue) Close() error {
+	return q.client.Close()
+}
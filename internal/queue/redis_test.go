@@ -0,0 +1,168 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/internal/model"
+)
+
+func newTestRedisQueue(t *testing.T, mr *miniredis.Miniredis, tasks *model.TasksModel, consumer string) *RedisQueue {
+	q, err := NewRedisQueue(config.QueueConfig{Host: mr.Addr(), Stream: "wise:tasks", Group: "wise-workers"}, tasks, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = q.Close() })
+	q.consumer = consumer
+	return q
+}
+
+// pendingCount 消费组中已投递未确认的消息数
+func pendingCount(t *testing.T, q *RedisQueue) int64 {
+	pending, err := q.client.XPending(context.Background(), q.stream, q.group).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pending.Count
+}
+
+func tids(tasks []*model.Tasks) []string {
+	list := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		list = append(list, task.Tid)
+	}
+	return list
+}
+
+func TestRedisQueuePending(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	tasks := newTestTasksModel(t)
+	now := time.Now()
+	for _, task := range []*model.Tasks{
+		{Tid: "init", Status: model.TaskStatusInit, Priority: model.TaskPriorityLow},
+		{Tid: "high", Status: model.TaskStatusInit, Priority: model.TaskPriorityHigh},
+		{Tid: "retry-due", Status: model.TaskStatusRetry, NextRunAt: now.Add(-time.Minute)},
+		{Tid: "retry-later", Status: model.TaskStatusRetry, NextRunAt: now.Add(time.Hour)},
+		{Tid: "success", Status: model.TaskStatusSuccess},
+	} {
+		task.Types, task.Params, task.CurrentState, task.Result, task.Error, task.Extend = "URL_ANALYSE", "", "start", "{}", "{}", "{}"
+		if err := tasks.Create(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q := newTestRedisQueue(t, mr, tasks, "worker-1")
+	for _, tid := range []string{"init", "high", "retry-due", "retry-later", "success", "missing", "init"} {
+		if err := q.Push(ctx, &model.Tasks{Tid: tid}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pending, err := q.Pending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := tids(pending)
+	want := []string{"high", "init", "retry-due"}
+	if len(got) != len(want) {
+		t.Fatalf("Pending() = %v, want %v", got, want)
+	}
+	if got[0] != "high" {
+		t.Errorf("Pending() = %v, want high priority first", got)
+	}
+
+	// 已结束和不存在的任务及重复消息已确认, 未到重试时间的任务保留消息
+	if n := pendingCount(t, q); n != 4 {
+		t.Errorf("pending messages = %d, want 4", n)
+	}
+	if _, ok := q.held["retry-later"]; !ok {
+		t.Error("retry-later should be held until its next run")
+	}
+	for _, tid := range []string{"success", "missing"} {
+		if _, ok := q.held[tid]; ok {
+			t.Errorf("%s should not be held", tid)
+		}
+	}
+
+	// 再次调度时保留的消息继续参与筛选, 不会重复读取
+	pending, err = q.Pending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != len(want) {
+		t.Errorf("Pending() again = %v, want %v", tids(pending), want)
+	}
+
+	for _, tid := range want {
+		if err := q.Ack(ctx, tid); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Ack(ctx, "not-held"); err != nil {
+		t.Fatal(err)
+	}
+	if n := pendingCount(t, q); n != 1 {
+		t.Errorf("pending messages after Ack = %d, want 1", n)
+	}
+	if len(q.held) != 1 {
+		t.Errorf("held = %v, want only retry-later", q.held)
+	}
+}
+
+func TestRedisQueueAutoClaim(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	now := time.Now()
+	mr.SetTime(now)
+	tasks := newTestTasksModel(t)
+	task := &model.Tasks{Tid: "init", Types: "URL_ANALYSE", Status: model.TaskStatusInit, CurrentState: "start", Result: "{}", Error: "{}", Extend: "{}"}
+	if err := tasks.Create(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+	crashed := newTestRedisQueue(t, mr, tasks, "worker-1")
+	q := newTestRedisQueue(t, mr, tasks, "worker-2")
+	if err := crashed.Push(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+
+	// 消息投递给异常退出的实例后未确认
+	pending, err := crashed.Pending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("Pending() = %v, want [init]", tids(pending))
+	}
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    int
+	}{
+		{name: "idle", elapsed: time.Minute, want: 0},
+		{name: "claimed", elapsed: claimMinIdle + time.Minute, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr.SetTime(now.Add(tt.elapsed))
+			pending, err := q.Pending(ctx, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pending) != tt.want {
+				t.Errorf("Pending() = %v, want %d tasks", tids(pending), tt.want)
+			}
+		})
+	}
+
+	if err := q.Ack(ctx, "init"); err != nil {
+		t.Fatal(err)
+	}
+	if n := pendingCount(t, q); n != 0 {
+		t.Errorf("pending messages after Ack = %d, want 0", n)
+	}
+}
@@ -0,0 +1,44 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/XXueTu/wise/internal/model"
+)
+
+var _ TaskQueue = (*SqliteQueue)(nil)
+
+// SqliteQueue 直接使用 tasks 表作为队列, 多个实例共享同一个数据库文件时通过原子更新领取
+type SqliteQueue struct {
+	tasks *model.TasksModel
+	aging time.Duration
+}
+
+func NewSqliteQueue(tasks *model.TasksModel, aging time.Duration) *SqliteQueue {
+	return &SqliteQueue{
+		tasks: tasks,
+		aging: aging,
+	}
+}
+
+// Push 任务已写入 tasks 表, 无需额外通知
+func (q *SqliteQueue) Push(ctx context.Context, task *model.Tasks) error {
+	return nil
+}
+
+func (q *SqliteQueue) Pending(ctx context.Context, limit int) ([]*model.Tasks, error) {
+	return q.tasks.GetRunnableLimit(ctx, time.Now(), q.aging, limit)
+}
+
+func (q *SqliteQueue) Claim(ctx context.Context, tid string, owner string, leaseUntil time.Time) (bool, error) {
+	return q.tasks.Claim(ctx, tid, owner, leaseUntil, time.Now())
+}
+
+func (q *SqliteQueue) Ack(ctx context.Context, tid string) error {
+	return nil
+}
+
+func (q *SqliteQueue) Close() error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/model/migrations"
+)
+
+func newTestTasksModel(t *testing.T) *model.TasksModel {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file::memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { _ = db.Close() })
+	if _, err := migrations.Up(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return model.NewTasksModel(&model.DB{Writer: db, Reader: db})
+}
+
+func TestSqliteQueueClaim(t *testing.T) {
+	ctx := context.Background()
+	tasks := newTestTasksModel(t)
+	now := time.Now()
+	for _, task := range []*model.Tasks{
+		{Tid: "init", Status: model.TaskStatusInit},
+		{Tid: "retry-due", Status: model.TaskStatusRetry, NextRunAt: now.Add(-time.Minute)},
+		{Tid: "retry-later", Status: model.TaskStatusRetry, NextRunAt: now.Add(time.Hour)},
+		{Tid: "success", Status: model.TaskStatusSuccess},
+	} {
+		task.Types, task.Params, task.CurrentState, task.Result, task.Error, task.Extend = "URL_ANALYSE", "", "start", "{}", "{}", "{}"
+		if err := tasks.Create(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q := NewSqliteQueue(tasks, 0)
+
+	pending, err := q.Pending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("Pending() = %d tasks, want 2", len(pending))
+	}
+
+	tests := []struct {
+		tid  string
+		want bool
+	}{
+		{tid: "init", want: true},
+		{tid: "retry-due", want: true},
+		{tid: "retry-later", want: false},
+		{tid: "success", want: false},
+		{tid: "missing", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tid, func(t *testing.T) {
+			// 多个实例同时领取, 只有一个成功
+			var wg sync.WaitGroup
+			var claimed atomic.Int64
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					ok, err := q.Claim(ctx, tt.tid, "owner", time.Now().Add(time.Minute))
+					if err != nil {
+						t.Error(err)
+					}
+					if ok {
+						claimed.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+			want := int64(0)
+			if tt.want {
+				want = 1
+			}
+			if claimed.Load() != want {
+				t.Errorf("Claim() succeeded %d times, want %d", claimed.Load(), want)
+			}
+		})
+	}
+}
@@ -1,27 +1,117 @@
 package svc
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/backup"
 	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/internal/event"
 	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/queue"
+	"github.com/XXueTu/wise/internal/tagging"
+	llm "github.com/XXueTu/wise/pkg/model"
 )
 
 type ServiceContext struct {
-	Config         config.Config
-	ModelsModel    *model.ModelsModel
-	ResourceModel  *model.ResourceModel
-	TagsModel      *model.TagsModel
-	TasksModel     *model.TasksModel
-	TaskPlansModel *model.TaskPlansModel
+	Config                config.Config
+	DB                    *model.DB
+	ModelsModel           *model.ModelsModel
+	ResourceModel         *model.ResourceModel
+	ResourceTagsModel     *model.ResourceTagsModel
+	ResourceKeywordsModel *model.ResourceKeywordsModel
+	TagsModel             *model.TagsModel
+	TagAliasesModel       *model.TagAliasesModel
+	TagRulesModel         *model.TagRulesModel
+	TasksModel            *model.TasksModel
+	TaskPausesModel       *model.TaskPausesModel
+	TaskPlansModel        *model.TaskPlansModel
+	BatchesModel          *model.BatchesModel
+	BatchItemsModel       *model.BatchItemsModel
+	SegmentsModel         *model.SegmentsModel
+	TaskEvents            *event.Bus
+	TaskQueue             queue.TaskQueue
+	Backup                *backup.Manager
+	TagResolver           *tagging.Resolver
+	TagStats              *tagging.Stats
+	TagRules              *tagging.RuleEngine
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
-	db := model.InitDB()
+	db := model.InitDB(c.Database)
+	tasksModel := model.NewTasksModel(db)
+	tagsModel := model.NewTagsModel(db)
+	tagAliasesModel := model.NewTagAliasesModel(db)
+	resourceTagsModel := model.NewResourceTagsModel(db)
+	resourceModel := model.NewResourceModel(db)
+	tagRulesModel := model.NewTagRulesModel(db)
 	return &ServiceContext{
-		Config:         c,
-		ModelsModel:    model.NewModelsModel(db),
-		ResourceModel:  model.NewResourceModel(db),
-		TagsModel:      model.NewTagsModel(db),
-		TasksModel:     model.NewTasksModel(db),
-		TaskPlansModel: model.NewTaskPlansModel(db),
+		Config:                c,
+		DB:                    db,
+		ModelsModel:           model.NewModelsModel(db),
+		ResourceModel:         resourceModel,
+		ResourceTagsModel:     resourceTagsModel,
+		ResourceKeywordsModel: model.NewResourceKeywordsModel(db),
+		TagsModel:             tagsModel,
+		TagAliasesModel:       tagAliasesModel,
+		TagRulesModel:         tagRulesModel,
+		TasksModel:            tasksModel,
+		TaskPausesModel:       model.NewTaskPausesModel(db),
+		TaskPlansModel:        model.NewTaskPlansModel(db),
+		BatchesModel:          model.NewBatchesModel(db),
+		BatchItemsModel:       model.NewBatchItemsModel(db),
+		SegmentsModel:         model.NewSegmentsModel(db),
+		TaskEvents:            mustNewTaskEvents(c.Task.Queue),
+		TaskQueue:             queue.MustNew(c.Task, tasksModel),
+		Backup:                backup.NewManager(c.Backup, db.Writer),
+		TagResolver:           newTagResolver(c.Tagging, tagsModel, tagAliasesModel),
+		TagStats:              tagging.NewStats(db, tagsModel, resourceTagsModel),
+		TagRules:              tagging.NewRuleEngine(tagRulesModel, resourceModel, resourceTagsModel),
+	}
+}
+
+// mustNewTaskEvents 使用 Redis 队列时 worker 可能运行在其他进程, 任务事件经 Redis 转发
+func mustNewTaskEvents(c config.QueueConfig) *event.Bus {
+	if c.Type != queue.TypeRedis {
+		return event.NewBus()
+	}
+	bus, err := event.NewRedisBus(redis.NewClient(&redis.Options{
+		Addr:     c.Host,
+		Password: c.Pass,
+		DB:       c.DB,
+	}), c.Events)
+	if err != nil {
+		panic(fmt.Errorf("subscribe redis task events: %w", err))
+	}
+	return bus
+}
+
+// newTagResolver 配置了向量模型时按名称相似度归并标签, 创建失败时只按名称和同义词匹配
+func newTagResolver(c config.TaggingConfig, tags *model.TagsModel, aliases *model.TagAliasesModel) *tagging.Resolver {
+	var embedder embedding.Embedder
+	if c.EmbeddingModel != "" {
+		e, err := llm.NewEmbeddingModel(context.Background(), c.EmbeddingModel)
+		if err != nil {
+			logx.Errorf("create embedding model %s error: %v", c.EmbeddingModel, err)
+		} else {
+			embedder = e
+		}
+	}
+	return tagging.NewResolver(c, tags, aliases, embedder)
+}
+
+// Close 停止定时备份, 关闭任务事件、任务队列和数据库连接
+func (s *ServiceContext) Close() error {
+	s.Backup.Stop()
+	if err := s.TaskEvents.Close(); err != nil {
+		logx.Errorf("close task events error: %v", err)
+	}
+	if err := s.TaskQueue.Close(); err != nil {
+		logx.Errorf("close task queue error: %v", err)
 	}
+	return s.DB.Close()
 }
@@ -0,0 +1,177 @@
+package tagging
+
+import (
+	"context"
+	"sort"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/tagname"
+)
+
+// AliasMember 建议合并为别名的标签
+type AliasMember struct {
+	Usage
+	Method     string  // 归入分组的方式, 与标签匹配方式相同
+	Similarity float64 // 按名称向量归入时与分组中最相近标签的相似度
+}
+
+// AliasGroup 建议合并的一组标签, 保留 Target, 其余标签合并后名称作为 Target 的别名
+type AliasGroup struct {
+	Target  Usage
+	Members []AliasMember
+}
+
+// 归入分组方式的优先级, 同一个标签有多种方式时取最确定的
+var methodRank = map[string]int{MethodExact: 0, MethodAlias: 1, MethodSynonym: 2, MethodEmbedding: 3}
+
+// ProposeAliases 将指向同一事物的标签聚类
+// 规范化后名称相同、名称是其他标签的别名、同义词指向同一个名称或名称向量相似度达到 MatchThreshold 的标签归为一组
+// 默认标签所在的组保留默认标签, 其余组保留资源最多的标签, 资源数相同时保留最早创建的
+func (r *Resolver) ProposeAliases(ctx context.Context, usage []Usage) ([]AliasGroup, error) {
+	aliases, err := r.aliases.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := newCluster(len(usage))
+	canonical := make(map[string]int)
+	byKey := make(map[string]int)
+	for i, u := range usage {
+		key := tagname.Key(u.Tag.Name)
+		if j, ok := byKey[key]; ok {
+			c.union(i, j, MethodExact, 1)
+			continue
+		}
+		byKey[key] = i
+		// 同义词指向的名称作为规范名称, 没有配置同义词的标签以自身名称为规范名称
+		if name, ok := r.synonyms[key]; ok {
+			key = tagname.Key(name)
+		}
+		if j, ok := canonical[key]; ok {
+			c.union(i, j, MethodSynonym, 1)
+		} else {
+			canonical[key] = i
+		}
+	}
+	byUid := make(map[string]int, len(usage))
+	for i, u := range usage {
+		byUid[u.Tag.Uid] = i
+	}
+	for _, alias := range aliases {
+		i, ok := byKey[alias.AliasKey]
+		j, found := byUid[alias.TagUid]
+		if ok && found && i != j {
+			c.union(i, j, MethodAlias, 1)
+		}
+	}
+	for i := range usage {
+		if usage[i].Tag.Embedding == nil {
+			continue
+		}
+		a := usage[i].Tag.Embedding.Slice()
+		for j := i + 1; j < len(usage); j++ {
+			if usage[j].Tag.Embedding == nil {
+				continue
+			}
+			similarity := 1 - model.CosineDistance(a, usage[j].Tag.Embedding.Slice())
+			if similarity >= r.c.MatchThreshold {
+				c.union(i, j, MethodEmbedding, similarity)
+			}
+		}
+	}
+	return c.groups(usage), nil
+}
+
+// cluster 按边合并标签的并查集, 记录每个标签归入分组的方式
+type cluster struct {
+	parent []int
+	method []string
+	score  []float64
+}
+
+func newCluster(n int) *cluster {
+	c := &cluster{parent: make([]int, n), method: make([]string, n), score: make([]float64, n)}
+	for i := range c.parent {
+		c.parent[i] = i
+	}
+	return c
+}
+
+func (c *cluster) find(i int) int {
+	for c.parent[i] != i {
+		c.parent[i] = c.parent[c.parent[i]]
+		i = c.parent[i]
+	}
+	return i
+}
+
+func (c *cluster) union(i, j int, method string, score float64) {
+	for _, k := range []int{i, j} {
+		if c.method[k] == "" || methodRank[method] < methodRank[c.method[k]] ||
+			(method == c.method[k] && score > c.score[k]) {
+			c.method[k], c.score[k] = method, score
+		}
+	}
+	if a, b := c.find(i), c.find(j); a != b {
+		c.parent[a] = b
+	}
+}
+
+// groups 返回包含多个标签的分组, 按分组的资源总数从多到少排序
+func (c *cluster) groups(usage []Usage) []AliasGroup {
+	members := make(map[int][]int)
+	for i := range usage {
+		root := c.find(i)
+		members[root] = append(members[root], i)
+	}
+	type group struct {
+		AliasGroup
+		total int64
+	}
+	var result []group
+	for _, indexes := range members {
+		if len(indexes) < 2 {
+			continue
+		}
+		target := indexes[0]
+		for _, i := range indexes[1:] {
+			if isPreferred(usage[i], usage[target]) {
+				target = i
+			}
+		}
+		g := group{AliasGroup: AliasGroup{Target: usage[target]}, total: usage[target].Count}
+		for _, i := range indexes {
+			if i == target {
+				continue
+			}
+			member := AliasMember{Usage: usage[i], Method: c.method[i]}
+			if member.Method == MethodEmbedding {
+				member.Similarity = c.score[i]
+			}
+			g.Members = append(g.Members, member)
+			g.total += usage[i].Count
+		}
+		result = append(result, g)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].total != result[j].total {
+			return result[i].total > result[j].total
+		}
+		return result[i].Target.Tag.ID < result[j].Target.Tag.ID
+	})
+	groups := make([]AliasGroup, len(result))
+	for i, g := range result {
+		groups[i] = g.AliasGroup
+	}
+	return groups
+}
+
+// isPreferred a 是否比 b 更适合作为保留的标签
+func isPreferred(a, b Usage) bool {
+	if (a.Tag.Uid == model.DefaultTagUid) != (b.Tag.Uid == model.DefaultTagUid) {
+		return a.Tag.Uid == model.DefaultTagUid
+	}
+	if a.Count != b.Count {
+		return a.Count > b.Count
+	}
+	return a.Tag.ID < b.Tag.ID
+}
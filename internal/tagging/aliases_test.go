@@ -0,0 +1,68 @@
+package tagging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pgvector/pgvector-go"
+
+	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/internal/model"
+)
+
+func TestProposeAliases(t *testing.T) {
+	ctx := context.Background()
+	tags, aliases := newTestModels(t)
+	k8s, kubernetes := pgvector.NewVector([]float32{1, 0.1, 0}), pgvector.NewVector([]float32{1, 0, 0})
+	err := tags.CreateBatch(ctx, []model.Tags{
+		{Uid: "go", Name: "Go"},
+		{Uid: "go1", Name: "go 语言"},
+		{Uid: "go2", Name: "Golang"},
+		{Uid: "pg", Name: "PostgreSQL"},
+		{Uid: "pg1", Name: "postgres"},
+		{Uid: "k8s", Name: "K8s", Embedding: &k8s},
+		{Uid: "kube", Name: "Kubernetes", Embedding: &kubernetes},
+		{Uid: "nl", Name: "自然语言"},
+		{Uid: "nature", Name: "自然"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := aliases.CreateBatch(ctx, []*model.TagAliases{{Alias: "Postgres", TagUid: "pg"}}); err != nil {
+		t.Fatal(err)
+	}
+	all, err := tags.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int64{"go": 1, "go1": 3, "go2": 2, "pg": 5, "kube": 1}
+	usage := make([]Usage, len(all))
+	for i, tag := range all {
+		usage[i] = Usage{Tag: tag, Count: counts[tag.Uid]}
+	}
+
+	c := config.TaggingConfig{MatchThreshold: 0.9, Synonyms: map[string][]string{"Golang": {"go"}}}
+	groups, err := NewResolver(c, tags, aliases, nil).ProposeAliases(ctx, usage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, group := range groups {
+		members := make([]string, len(group.Members))
+		for i, member := range group.Members {
+			members[i] = fmt.Sprintf("%s:%s", member.Tag.Name, member.Method)
+		}
+		got = append(got, group.Target.Tag.Name+"<"+strings.Join(members, ","))
+	}
+	// 资源最多的 go 语言 保留, 自然语言 与 自然 不归为一组
+	want := []string{
+		"go 语言<Go:exact,Golang:synonym",
+		"PostgreSQL<postgres:alias",
+		"Kubernetes<K8s:embedding",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("ProposeAliases = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,272 @@
+package tagging
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/pgvector/pgvector-go"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/tagname"
+)
+
+// 标签匹配方式
+const (
+	MethodExact     = "exact"     // 规范化后名称相同
+	MethodAlias     = "alias"     // 合并标签留下的别名
+	MethodSynonym   = "synonym"   // 配置的同义词
+	MethodEmbedding = "embedding" // 名称向量相近
+	MethodNew       = "new"       // 新建标签
+)
+
+// 一次请求向量模型的最大文本数
+const embedBatchSize = 64
+
+// Label 大模型返回的标签
+type Label struct {
+	Name       string  // 标签名称
+	Confidence float64 // 大模型给出的置信度, 0 到 1
+}
+
+// Match 标签的匹配结果
+type Match struct {
+	Label      string  // 大模型返回的原始标签
+	TagUid     string  // 匹配或新建的标签唯一标识
+	TagName    string  // 标签名称
+	Method     string  // 匹配方式
+	Confidence float64 // 最终置信度, 按相似度匹配时乘以相似度
+}
+
+// Resolver 将大模型返回的标签归并到已有标签
+type Resolver struct {
+	c        config.TaggingConfig
+	tags     *model.TagsModel
+	aliases  *model.TagAliasesModel
+	embedder embedding.Embedder // 为空时不按相似度匹配
+	synonyms map[string]string  // 规范化的同义词 -> 标签名称
+}
+
+func NewResolver(c config.TaggingConfig, tags *model.TagsModel, aliases *model.TagAliasesModel, embedder embedding.Embedder) *Resolver {
+	synonyms := make(map[string]string)
+	for name, words := range c.Synonyms {
+		for _, word := range words {
+			synonyms[tagname.Key(word)] = name
+		}
+	}
+	return &Resolver{
+		c:        c,
+		tags:     tags,
+		aliases:  aliases,
+		embedder: embedder,
+		synonyms: synonyms,
+	}
+}
+
+// Resolve 依次按规范化的名称、别名、同义词、名称向量相似度匹配已有标签
+// 名称和别名通过 TagsModel.MatchNames 查找, 与创建标签时的归并方式一致, 规范化后重名的标签取最早创建的
+// 匹配不到的标签按置信度从高到低创建, 置信度低于 CreateThreshold 或超过 MaxNewTags 的丢弃
+func (r *Resolver) Resolve(ctx context.Context, labels []Label) ([]Match, error) {
+	labels = dedupe(labels)
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.Name)
+		if name, ok := r.synonyms[tagname.Key(label.Name)]; ok {
+			names = append(names, name)
+		}
+	}
+	matched, err := r.tags.MatchNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Match
+	var pending []Label
+	for _, label := range labels {
+		key := tagname.Key(label.Name)
+		if tag, ok := matched[label.Name]; ok {
+			method := MethodExact
+			if tag.NameKey != key {
+				method = MethodAlias
+			}
+			matches = append(matches, newMatch(label, tag, method, label.Confidence))
+			continue
+		}
+		if name, ok := r.synonyms[key]; ok {
+			if tag, ok := matched[name]; ok {
+				matches = append(matches, newMatch(label, tag, MethodSynonym, label.Confidence))
+				continue
+			}
+			// 同义词指向的标签还不存在时按该名称创建
+			label.Name = name
+		}
+		pending = append(pending, label)
+	}
+	// 多个同义词指向同一个不存在的标签时只创建一次
+	pending = dedupe(pending)
+
+	embeddings := make(map[string]pgvector.Vector)
+	if r.embedder != nil && len(pending) > 0 {
+		if err := r.embedTags(ctx); err != nil {
+			return nil, err
+		}
+		names := make([]string, len(pending))
+		for i, label := range pending {
+			names[i] = label.Name
+		}
+		vectors, err := r.embed(ctx, names)
+		if err != nil {
+			return nil, err
+		}
+		rest := pending[:0]
+		for i, label := range pending {
+			embeddings[label.Name] = vectors[i]
+			similar, err := r.tags.Search(ctx, vectors[i], 1)
+			if err != nil {
+				logx.Errorf("Resolve Search label: %s, error: %v", label.Name, err)
+				return nil, err
+			}
+			if len(similar) > 0 {
+				similarity := 1 - similar[0].Distance
+				if similarity >= r.c.MatchThreshold {
+					matches = append(matches, newMatch(label, similar[0], MethodEmbedding, label.Confidence*similarity))
+					continue
+				}
+			}
+			rest = append(rest, label)
+		}
+		pending = rest
+	}
+
+	created, err := r.create(ctx, pending, embeddings)
+	if err != nil {
+		return nil, err
+	}
+	return uniqueMatches(append(matches, created...)), nil
+}
+
+// create 按置信度从高到低创建新标签
+func (r *Resolver) create(ctx context.Context, labels []Label, embeddings map[string]pgvector.Vector) ([]Match, error) {
+	sort.SliceStable(labels, func(i, j int) bool { return labels[i].Confidence > labels[j].Confidence })
+	var matches []Match
+	var newTags []model.Tags
+	for _, label := range labels {
+		if label.Confidence < r.c.CreateThreshold || len(newTags) >= r.c.MaxNewTags {
+			logx.Infof("Resolve drop label: %s, confidence: %.2f", label.Name, label.Confidence)
+			continue
+		}
+		tag := model.Tags{
+			Uid:         model.GenUid(),
+			Name:        label.Name,
+			Description: "自动标签",
+		}
+		if vector, ok := embeddings[label.Name]; ok {
+			tag.Embedding = &vector
+		}
+		newTags = append(newTags, tag)
+		matches = append(matches, newMatch(label, &tag, MethodNew, label.Confidence))
+	}
+	if len(newTags) == 0 {
+		return nil, nil
+	}
+	if err := r.tags.CreateBatch(ctx, newTags); err != nil {
+		logx.Errorf("Resolve CreateBatch tags: %d, error: %v", len(newTags), err)
+		return nil, err
+	}
+	return matches, nil
+}
+
+// embedTags 为还没有名称向量的标签生成向量
+func (r *Resolver) embedTags(ctx context.Context) error {
+	missing, err := r.tags.GetMissingEmbedding(ctx)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	names := make([]string, len(missing))
+	for i, tag := range missing {
+		names[i] = tag.Name
+	}
+	vectors, err := r.embed(ctx, names)
+	if err != nil {
+		return err
+	}
+	for i, tag := range missing {
+		if err := r.tags.UpdateEmbedding(ctx, tag.ID, vectors[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) embed(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	vectors := make([]pgvector.Vector, 0, len(texts))
+	for start := 0; start < len(texts); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(texts))
+		result, err := r.embedder.EmbedStrings(ctx, texts[start:end])
+		if err != nil {
+			logx.Errorf("Resolve EmbedStrings texts: %d, error: %v", end-start, err)
+			return nil, err
+		}
+		for _, values := range result {
+			vector := make([]float32, len(values))
+			for i, v := range values {
+				vector[i] = float32(v)
+			}
+			vectors = append(vectors, pgvector.NewVector(vector))
+		}
+	}
+	return vectors, nil
+}
+
+func newMatch(label Label, tag *model.Tags, method string, confidence float64) Match {
+	return Match{
+		Label:      label.Name,
+		TagUid:     tag.Uid,
+		TagName:    tag.Name,
+		Method:     method,
+		Confidence: confidence,
+	}
+}
+
+// uniqueMatches 多个标签归并到同一个标签时保留置信度最高的匹配
+func uniqueMatches(matches []Match) []Match {
+	index := make(map[string]int)
+	result := make([]Match, 0, len(matches))
+	for _, match := range matches {
+		if i, ok := index[match.TagUid]; ok {
+			if match.Confidence > result[i].Confidence {
+				result[i] = match
+			}
+			continue
+		}
+		index[match.TagUid] = len(result)
+		result = append(result, match)
+	}
+	return result
+}
+
+// dedupe 去掉空标签, 规范化后相同的标签保留置信度最高的一个
+func dedupe(labels []Label) []Label {
+	index := make(map[string]int)
+	result := make([]Label, 0, len(labels))
+	for _, label := range labels {
+		label.Name = strings.Join(strings.Fields(label.Name), " ")
+		if label.Name == "" {
+			continue
+		}
+		key := tagname.Key(label.Name)
+		if i, ok := index[key]; ok {
+			result[i].Confidence = max(result[i].Confidence, label.Confidence)
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, label)
+	}
+	return result
+}
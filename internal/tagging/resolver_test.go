@@ -0,0 +1,154 @@
+package tagging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/embedding"
+
+	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/model/migrations"
+)
+
+// newTestDB 创建迁移后的内存数据库
+func newTestDB(t *testing.T) *model.DB {
+	db := model.OpenDB(config.DatabaseConfig{Driver: model.DriverSQLite, Path: ":memory:", Synchronous: "NORMAL", BusyTimeout: time.Second})
+	t.Cleanup(func() { _ = db.Close() })
+	if _, err := migrations.Up(context.Background(), db.Writer); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newTestModels(t *testing.T) (*model.TagsModel, *model.TagAliasesModel) {
+	db := newTestDB(t)
+	return model.NewTagsModel(db), model.NewTagAliasesModel(db)
+}
+
+// fakeEmbedder 按预设的向量返回, 未预设的文本返回与其他向量都不相近的向量
+type fakeEmbedder map[string][]float64
+
+func (e fakeEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vector, ok := e[text]
+		if !ok {
+			vector = []float64{0, 0, 1}
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+func TestResolverResolve(t *testing.T) {
+	ctx := context.Background()
+	tags, aliases := newTestModels(t)
+	err := tags.CreateBatch(ctx, []model.Tags{
+		{Uid: "go", Name: "Golang"},
+		{Uid: "db", Name: "数据库"},
+		{Uid: "pg", Name: "PostgreSQL"},
+		{Uid: "pg1", Name: "Postgres"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 合并后 Postgres 作为 PostgreSQL 的别名
+	if _, err := tags.Merge(ctx, "pg", []string{"pg1"}); err != nil {
+		t.Fatal(err)
+	}
+	c := config.TaggingConfig{
+		MatchThreshold:  0.9,
+		CreateThreshold: 0.8,
+		MaxNewTags:      1,
+		Synonyms:        map[string][]string{"数据库": {"DB", "database"}},
+	}
+	embedder := fakeEmbedder{
+		"Golang":     {1, 0, 0},
+		"数据库":        {0, 1, 0},
+		"PostgreSQL": {0, 0.5, 0.5},
+		"Go语言":       {0.99, 0.1, 0},
+		"SQL":        {0.5, 0.5, 0},
+		"Kafka":      {0, 0, 1},
+		"Redis":      {0, 0, 1},
+		"Unknown":    {0, 0, 1},
+	}
+	r := NewResolver(c, tags, aliases, embedder)
+	matches, err := r.Resolve(ctx, []Label{
+		{Name: " golang ", Confidence: 0.9},
+		{Name: "database", Confidence: 0.7},
+		{Name: "postgres", Confidence: 0.8},
+		{Name: "Go语言", Confidence: 1},
+		{Name: "SQL", Confidence: 0.95},
+		{Name: "Kafka", Confidence: 0.85},
+		{Name: "Redis", Confidence: 0.5},
+		{Name: "", Confidence: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]Match)
+	for _, match := range matches {
+		got[match.TagName] = match
+	}
+	tests := []struct {
+		tag    string
+		method string
+		label  string
+	}{
+		// golang 按名称匹配, Go语言 按相似度归入同一个标签, 保留置信度更高的匹配
+		{tag: "Golang", method: MethodEmbedding, label: "Go语言"},
+		{tag: "数据库", method: MethodSynonym, label: "database"},
+		{tag: "PostgreSQL", method: MethodAlias, label: "postgres"},
+		// SQL 与已有标签都不够相近, 按置信度创建, Kafka 超过 MaxNewTags, Redis 置信度不足
+		{tag: "SQL", method: MethodNew, label: "SQL"},
+	}
+	if len(got) != len(tests) {
+		t.Errorf("Resolve = %+v, want %d matches", matches, len(tests))
+	}
+	for _, tt := range tests {
+		match, ok := got[tt.tag]
+		if !ok {
+			t.Errorf("tag %s not matched", tt.tag)
+			continue
+		}
+		if match.Method != tt.method || match.Label != tt.label {
+			t.Errorf("tag %s matched by %s from %s, want %s from %s", tt.tag, match.Method, match.Label, tt.method, tt.label)
+		}
+	}
+	if confidence := got["Golang"].Confidence; confidence >= 1 || confidence < 0.9 {
+		t.Errorf("embedding confidence = %f, want similarity of Go语言", confidence)
+	}
+
+	all, err := tags.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 {
+		t.Errorf("tags = %d, want 4", len(all))
+	}
+	for _, tag := range all {
+		if tag.Embedding == nil {
+			t.Errorf("tag %s has no embedding", tag.Name)
+		}
+	}
+}
+
+func TestResolverDuplicateNames(t *testing.T) {
+	ctx := context.Background()
+	tags, aliases := newTestModels(t)
+	// 历史数据中规范化后重名的标签, 归入最早创建的
+	if err := tags.CreateBatch(ctx, []model.Tags{{Uid: "first", Name: "Go"}, {Uid: "second", Name: "go"}}); err != nil {
+		t.Fatal(err)
+	}
+	r := NewResolver(config.TaggingConfig{CreateThreshold: 1}, tags, aliases, nil)
+	matches, err := r.Resolve(ctx, []Label{{Name: "GO", Confidence: 0.9}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].TagUid != "first" || matches[0].Method != MethodExact {
+		t.Errorf("Resolve = %+v, want exact match of first", matches)
+	}
+}
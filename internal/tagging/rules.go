@@ -0,0 +1,331 @@
+package tagging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/tagname"
+)
+
+// 遍历资源时每批读取的数量
+const scanBatchSize = 200
+
+// Subject 规则匹配的资源
+type Subject struct {
+	Resource *model.Resource
+	Labels   []string // 大模型返回的原始标签
+	TagUids  []string // 资源当前的标签
+
+	title   string // 小写的标题, 首次匹配关键词时生成
+	content string // 小写的内容
+}
+
+func (s *Subject) lowerTitle() string {
+	if s.title == "" {
+		s.title = strings.ToLower(s.Resource.Title)
+	}
+	return s.title
+}
+
+func (s *Subject) lowerContent() string {
+	if s.content == "" {
+		s.content = strings.ToLower(s.Resource.Content)
+	}
+	return s.content
+}
+
+// Effect 规则对资源标签的修改
+type Effect struct {
+	Add    []string // 添加的标签
+	Remove []string // 移除的标签
+}
+
+// Empty 是否没有修改
+func (e Effect) Empty() bool {
+	return len(e.Add) == 0 && len(e.Remove) == 0
+}
+
+// Rule 编译后的标签规则
+type Rule struct {
+	*model.TagRules
+	pattern string         // 规范化后的匹配内容
+	regexp  *regexp.Regexp // url_regex 规则的正则
+}
+
+// CompileRule 校验并编译规则
+func CompileRule(rule *model.TagRules) (*Rule, error) {
+	r := &Rule{TagRules: rule, pattern: strings.TrimSpace(rule.Pattern)}
+	if r.pattern == "" {
+		return nil, errors.New("pattern is empty")
+	}
+	switch rule.Action {
+	case model.TagRuleActionAdd, model.TagRuleActionRemove:
+	default:
+		return nil, fmt.Errorf("unknown action %q", rule.Action)
+	}
+	switch rule.Type {
+	case model.TagRuleTypeDomain:
+		r.pattern = strings.TrimPrefix(strings.ToLower(r.pattern), "*.")
+	case model.TagRuleTypeURLRegex:
+		re, err := regexp.Compile(r.pattern)
+		if err != nil {
+			return nil, err
+		}
+		r.regexp = re
+	case model.TagRuleTypeKeyword:
+		switch rule.Field {
+		case "", model.TagRuleFieldTitle, model.TagRuleFieldContent:
+		default:
+			return nil, fmt.Errorf("unknown field %q", rule.Field)
+		}
+		r.pattern = strings.ToLower(r.pattern)
+	case model.TagRuleTypeResourceType:
+	case model.TagRuleTypeLabel:
+		r.pattern = tagname.Key(r.pattern)
+	default:
+		return nil, fmt.Errorf("unknown type %q", rule.Type)
+	}
+	return r, nil
+}
+
+// CompileRules 编译多个规则, 无法编译的规则跳过, 数据库中的规则在保存时已经校验过
+func CompileRules(rules []*model.TagRules) []*Rule {
+	compiled := make([]*Rule, 0, len(rules))
+	for _, rule := range rules {
+		r, err := CompileRule(rule)
+		if err != nil {
+			logx.Errorf("CompileRules uid: %s, error: %v", rule.Uid, err)
+			continue
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled
+}
+
+// Match 资源是否满足规则
+func (r *Rule) Match(s *Subject) bool {
+	switch r.Type {
+	case model.TagRuleTypeDomain:
+		u, err := url.Parse(s.Resource.URL)
+		if err != nil {
+			return false
+		}
+		host := strings.ToLower(u.Hostname())
+		return host == r.pattern || strings.HasSuffix(host, "."+r.pattern)
+	case model.TagRuleTypeURLRegex:
+		return r.regexp.MatchString(s.Resource.URL)
+	case model.TagRuleTypeKeyword:
+		if r.Field != model.TagRuleFieldContent && strings.Contains(s.lowerTitle(), r.pattern) {
+			return true
+		}
+		return r.Field != model.TagRuleFieldTitle && strings.Contains(s.lowerContent(), r.pattern)
+	case model.TagRuleTypeResourceType:
+		return strings.EqualFold(s.Resource.Type, r.pattern)
+	case model.TagRuleTypeLabel:
+		for _, label := range s.Labels {
+			if tagname.Key(label) == r.pattern {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Evaluate 按顺序执行规则, 同一个标签以最后匹配的规则为准, 只返回会改变资源标签的修改
+func Evaluate(rules []*Rule, s *Subject) Effect {
+	actions := make(map[string]string)
+	var order []string
+	for _, rule := range rules {
+		if !rule.Match(s) {
+			continue
+		}
+		if _, ok := actions[rule.TagUid]; !ok {
+			order = append(order, rule.TagUid)
+		}
+		actions[rule.TagUid] = rule.Action
+	}
+	var effect Effect
+	for _, uid := range order {
+		has := slices.Contains(s.TagUids, uid)
+		switch {
+		case actions[uid] == model.TagRuleActionAdd && !has:
+			effect.Add = append(effect.Add, uid)
+		case actions[uid] == model.TagRuleActionRemove && has:
+			effect.Remove = append(effect.Remove, uid)
+		}
+	}
+	return effect
+}
+
+// WithRule 将规则按执行顺序放入规则集, 唯一标识相同的规则被替换, 返回新的规则集
+// 与 TagRulesModel.GetEnabled 的顺序一致, 按优先级和 id 排序, 未保存的规则排在同优先级的最后
+func WithRule(rules []*Rule, rule *Rule) []*Rule {
+	result := WithoutRule(rules, rule.Uid)
+	i := slices.IndexFunc(result, func(r *Rule) bool {
+		if r.Priority != rule.Priority {
+			return r.Priority > rule.Priority
+		}
+		return rule.ID != 0 && r.ID > rule.ID
+	})
+	if i < 0 {
+		i = len(result)
+	}
+	return slices.Insert(result, i, rule)
+}
+
+// WithoutRule 返回去掉指定规则后的规则集, 唯一标识为空时只复制
+func WithoutRule(rules []*Rule, uid string) []*Rule {
+	return slices.DeleteFunc(slices.Clone(rules), func(r *Rule) bool {
+		return uid != "" && r.Uid == uid
+	})
+}
+
+// Outcome 执行规则后资源的标签, 不改变 TagUids
+func (s *Subject) Outcome(effect Effect) []string {
+	uids := slices.DeleteFunc(slices.Clone(s.TagUids), func(uid string) bool {
+		return slices.Contains(effect.Remove, uid)
+	})
+	return append(uids, effect.Add...)
+}
+
+// Compare 比较两个规则集对资源标签的修改, 返回 after 相对 before 多添加和多移除的标签
+func Compare(before, after []*Rule, s *Subject) Effect {
+	from := s.Outcome(Evaluate(before, s))
+	to := s.Outcome(Evaluate(after, s))
+	diff := Effect{Add: make([]string, 0), Remove: make([]string, 0)}
+	for _, uid := range to {
+		if !slices.Contains(from, uid) {
+			diff.Add = append(diff.Add, uid)
+		}
+	}
+	for _, uid := range from {
+		if !slices.Contains(to, uid) {
+			diff.Remove = append(diff.Remove, uid)
+		}
+	}
+	return diff
+}
+
+// RuleEngine 按自定义规则修改资源标签
+type RuleEngine struct {
+	rules        *model.TagRulesModel
+	resources    *model.ResourceModel
+	resourceTags *model.ResourceTagsModel
+}
+
+func NewRuleEngine(rules *model.TagRulesModel, resources *model.ResourceModel, resourceTags *model.ResourceTagsModel) *RuleEngine {
+	return &RuleEngine{
+		rules:        rules,
+		resources:    resources,
+		resourceTags: resourceTags,
+	}
+}
+
+// Enabled 获取并编译启用的规则
+func (e *RuleEngine) Enabled(ctx context.Context) ([]*Rule, error) {
+	rules, err := e.rules.GetEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return CompileRules(rules), nil
+}
+
+// ApplyResource 对单个资源执行启用的规则, labels 为本次标注返回的原始标签, 与已保存的原始标签合并匹配
+func (e *RuleEngine) ApplyResource(ctx context.Context, resourceID int64, labels []string) (Effect, error) {
+	rules, err := e.Enabled(ctx)
+	if err != nil || len(rules) == 0 {
+		return Effect{}, err
+	}
+	resource, err := e.resources.Get(ctx, resourceID)
+	if err != nil {
+		return Effect{}, err
+	}
+	links, err := e.resourceTags.GetLinks(ctx, []int64{resourceID})
+	if err != nil {
+		return Effect{}, err
+	}
+	subject := newSubject(resource, links[resourceID])
+	subject.Labels = append(subject.Labels, labels...)
+	effect := Evaluate(rules, subject)
+	return effect, e.Apply(ctx, resourceID, effect)
+}
+
+// Scan 分批遍历全部资源, 对每个会被修改的资源调用 fn, fn 返回错误时停止
+func (e *RuleEngine) Scan(ctx context.Context, rules []*Rule, fn func(resource *model.Resource, effect Effect) error) error {
+	return e.scan(ctx, func(s *Subject) error {
+		effect := Evaluate(rules, s)
+		if effect.Empty() {
+			return nil
+		}
+		return fn(s.Resource, effect)
+	})
+}
+
+// Diff 分批遍历全部资源, 对 after 与 before 执行结果不同的资源调用 fn, 修改为 after 相对 before 的差异
+func (e *RuleEngine) Diff(ctx context.Context, before, after []*Rule, fn func(resource *model.Resource, diff Effect) error) error {
+	return e.scan(ctx, func(s *Subject) error {
+		diff := Compare(before, after, s)
+		if diff.Empty() {
+			return nil
+		}
+		return fn(s.Resource, diff)
+	})
+}
+
+// scan 分批读取资源及其标签, fn 返回错误时停止
+func (e *RuleEngine) scan(ctx context.Context, fn func(s *Subject) error) error {
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		resources, err := e.resources.GetBatch(ctx, afterID, scanBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(resources) == 0 {
+			return nil
+		}
+		ids := make([]int64, len(resources))
+		for i, resource := range resources {
+			ids[i] = resource.ID
+		}
+		links, err := e.resourceTags.GetLinks(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, resource := range resources {
+			if err := fn(newSubject(resource, links[resource.ID])); err != nil {
+				return err
+			}
+		}
+		afterID = resources[len(resources)-1].ID
+	}
+}
+
+// Apply 写入规则对资源标签的修改
+func (e *RuleEngine) Apply(ctx context.Context, resourceID int64, effect Effect) error {
+	if err := e.resourceTags.AddTags(ctx, resourceID, effect.Add); err != nil {
+		return err
+	}
+	return e.resourceTags.RemoveTags(ctx, resourceID, effect.Remove)
+}
+
+func newSubject(resource *model.Resource, links []*model.ResourceTags) *Subject {
+	s := &Subject{Resource: resource}
+	for _, link := range links {
+		s.TagUids = append(s.TagUids, link.TagUid)
+		if link.Label != "" {
+			s.Labels = append(s.Labels, link.Label)
+		}
+	}
+	return s
+}
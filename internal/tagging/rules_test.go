@@ -0,0 +1,198 @@
+package tagging
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/XXueTu/wise/internal/model"
+)
+
+func TestRuleMatch(t *testing.T) {
+	subject := &Subject{
+		Resource: &model.Resource{
+			URL:     "https://blog.Golang.org/gc?from=feed",
+			Title:   "Go GC 调优",
+			Content: "介绍 Go 的垃圾回收, 以及 GOGC 参数",
+			Type:    "Article",
+		},
+		Labels: []string{"Garbage  Collection"},
+	}
+	tests := []struct {
+		name string
+		rule model.TagRules
+		want bool
+	}{
+		{name: "domain", rule: model.TagRules{Type: model.TagRuleTypeDomain, Pattern: "golang.org"}, want: true},
+		{name: "domain wildcard", rule: model.TagRules{Type: model.TagRuleTypeDomain, Pattern: "*.golang.org"}, want: true},
+		{name: "domain suffix only", rule: model.TagRules{Type: model.TagRuleTypeDomain, Pattern: "lang.org"}, want: false},
+		{name: "url regex", rule: model.TagRules{Type: model.TagRuleTypeURLRegex, Pattern: `/gc\b`}, want: true},
+		{name: "keyword title", rule: model.TagRules{Type: model.TagRuleTypeKeyword, Field: model.TagRuleFieldTitle, Pattern: "gc"}, want: true},
+		{name: "keyword content only", rule: model.TagRules{Type: model.TagRuleTypeKeyword, Field: model.TagRuleFieldContent, Pattern: "调优"}, want: false},
+		{name: "keyword any field", rule: model.TagRules{Type: model.TagRuleTypeKeyword, Pattern: "gogc"}, want: true},
+		{name: "resource type", rule: model.TagRules{Type: model.TagRuleTypeResourceType, Pattern: "article"}, want: true},
+		{name: "label", rule: model.TagRules{Type: model.TagRuleTypeLabel, Pattern: "garbage collection"}, want: true},
+		{name: "label partial", rule: model.TagRules{Type: model.TagRuleTypeLabel, Pattern: "garbage"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Action = model.TagRuleActionAdd
+			rule, err := CompileRule(&tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rule.Match(subject); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	invalid := []model.TagRules{
+		{Type: model.TagRuleTypeURLRegex, Pattern: "(", Action: model.TagRuleActionAdd},
+		{Type: model.TagRuleTypeKeyword, Pattern: " ", Action: model.TagRuleActionAdd},
+		{Type: model.TagRuleTypeKeyword, Field: "url", Pattern: "go", Action: model.TagRuleActionAdd},
+		{Type: "unknown", Pattern: "go", Action: model.TagRuleActionAdd},
+		{Type: model.TagRuleTypeDomain, Pattern: "go.dev", Action: "replace"},
+	}
+	for _, rule := range invalid {
+		if _, err := CompileRule(&rule); err == nil {
+			t.Errorf("CompileRule(%+v) succeeded, want error", rule)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	compile := func(rule model.TagRules) *Rule {
+		r, err := CompileRule(&rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	enabled := []*Rule{
+		compile(model.TagRules{ID: 1, Uid: "r1", Type: model.TagRuleTypeDomain, Pattern: "go.dev", Action: model.TagRuleActionAdd, TagUid: "news"}),
+		compile(model.TagRules{ID: 2, Uid: "r2", Type: model.TagRuleTypeDomain, Pattern: "go.dev", Action: model.TagRuleActionRemove, TagUid: "go", Priority: 1}),
+	}
+	tests := []struct {
+		name      string
+		candidate model.TagRules
+		order     string
+		want      Effect
+	}{
+		{
+			name:      "insert after same priority",
+			candidate: model.TagRules{Type: model.TagRuleTypeDomain, Pattern: "go.dev", Action: model.TagRuleActionAdd, TagUid: "go", Priority: 1},
+			order:     "r1,r2,",
+			want:      Effect{Add: []string{"go"}, Remove: []string{}},
+		},
+		{
+			name:      "overridden by later rule",
+			candidate: model.TagRules{Type: model.TagRuleTypeDomain, Pattern: "go.dev", Action: model.TagRuleActionAdd, TagUid: "go"},
+			order:     "r1,,r2",
+			want:      Effect{Add: []string{}, Remove: []string{}},
+		},
+		{
+			name:      "replace saved rule",
+			candidate: model.TagRules{ID: 2, Uid: "r2", Type: model.TagRuleTypeDomain, Pattern: "example.com", Action: model.TagRuleActionRemove, TagUid: "go", Priority: 1},
+			order:     "r1,r2",
+			want:      Effect{Add: []string{"go"}, Remove: []string{}},
+		},
+		{
+			name:      "replace saved rule before others",
+			candidate: model.TagRules{ID: 1, Uid: "r1", Type: model.TagRuleTypeDomain, Pattern: "go.dev", Action: model.TagRuleActionRemove, TagUid: "news", Priority: 1},
+			order:     "r1,r2",
+			want:      Effect{Add: []string{}, Remove: []string{"news"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := WithRule(enabled, compile(tt.candidate))
+			var uids []string
+			for _, rule := range after {
+				uids = append(uids, rule.Uid)
+			}
+			if got := strings.Join(uids, ","); got != tt.order {
+				t.Errorf("WithRule() order = %s, want %s", got, tt.order)
+			}
+			subject := &Subject{Resource: &model.Resource{URL: "https://go.dev/blog"}, TagUids: []string{"go"}}
+			if got := Compare(enabled, after, subject); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if len(enabled) != 2 {
+		t.Errorf("WithRule() modified the enabled rules: %d", len(enabled))
+	}
+}
+
+func TestRuleEngine(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	tags, resources, resourceTags, rules := model.NewTagsModel(db), model.NewResourceModel(db), model.NewResourceTagsModel(db), model.NewTagRulesModel(db)
+	if err := tags.CreateBatch(ctx, []model.Tags{{Uid: "go", Name: "Go"}, {Uid: "news", Name: "资讯"}}); err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]int64)
+	for url, uids := range map[string][]string{
+		"https://go.dev/blog/gc":     {"news"},
+		"https://news.example.com/1": {"news"},
+		"https://example.com/go":     nil,
+	} {
+		resource := &model.Resource{URL: url, Title: url}
+		if err := resources.Create(ctx, resource); err != nil {
+			t.Fatal(err)
+		}
+		if err := resourceTags.SetTags(ctx, resource.ID, uids); err != nil {
+			t.Fatal(err)
+		}
+		ids[url] = resource.ID
+	}
+	// go.dev 的资源添加 Go 并移除资讯, 停用的规则不执行
+	for _, rule := range []*model.TagRules{
+		{Uid: "r1", Type: model.TagRuleTypeDomain, Pattern: "go.dev", Action: model.TagRuleActionAdd, TagUid: "go", Enabled: true},
+		{Uid: "r2", Type: model.TagRuleTypeDomain, Pattern: "go.dev", Action: model.TagRuleActionRemove, TagUid: "news", Enabled: true, Priority: 1},
+		{Uid: "r3", Type: model.TagRuleTypeURLRegex, Pattern: "example", Action: model.TagRuleActionAdd, TagUid: "go", Enabled: false},
+	} {
+		if err := rules.Create(ctx, rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	engine := NewRuleEngine(rules, resources, resourceTags)
+	enabled, err := engine.Enabled(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	effects := make(map[int64]Effect)
+	err = engine.Scan(ctx, enabled, func(resource *model.Resource, effect Effect) error {
+		effects[resource.ID] = effect
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int64]Effect{ids["https://go.dev/blog/gc"]: {Add: []string{"go"}, Remove: []string{"news"}}}
+	if !reflect.DeepEqual(effects, want) {
+		t.Errorf("Scan effects = %+v, want %+v", effects, want)
+	}
+
+	effect, err := engine.ApplyResource(ctx, ids["https://go.dev/blog/gc"], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(effect, want[ids["https://go.dev/blog/gc"]]) {
+		t.Errorf("ApplyResource = %+v", effect)
+	}
+	uids, err := resourceTags.GetTagUids(ctx, ids["https://go.dev/blog/gc"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(uids, []string{"go"}) {
+		t.Errorf("tags after ApplyResource = %v, want [go]", uids)
+	}
+	// 再次执行没有修改
+	if effect, err = engine.ApplyResource(ctx, ids["https://go.dev/blog/gc"], nil); err != nil || !effect.Empty() {
+		t.Errorf("ApplyResource again = %+v, %v, want empty", effect, err)
+	}
+}
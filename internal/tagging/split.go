@@ -0,0 +1,97 @@
+package tagging
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/tagname"
+	llm "github.com/XXueTu/wise/pkg/model"
+)
+
+// 大模型分类时使用的内容长度, 按字符截断
+const classifyContentLimit = 2000
+
+// SplitTarget 拆分后的标签及分配规则
+type SplitTarget struct {
+	Uid         string   // 标签唯一标识
+	ResourceIDs []int64  // 指定分配的资源
+	Keywords    []string // 标题或描述包含任一关键词的资源, 忽略大小写
+}
+
+// PlanSplit 按规则为资源分配拆分后的标签, 一个资源可以分配多个标签, 不满足任何规则的资源不分配
+func PlanSplit(resources []*model.Resource, targets []SplitTarget) map[int64][]string {
+	assignments := make(map[int64][]string)
+	for _, resource := range resources {
+		text := strings.ToLower(resource.Title + "\n" + resource.Describe)
+		for _, target := range targets {
+			matched := slices.Contains(target.ResourceIDs, resource.ID)
+			for _, keyword := range target.Keywords {
+				if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" && strings.Contains(text, keyword) {
+					matched = true
+					break
+				}
+			}
+			if matched {
+				assignments[resource.ID] = append(assignments[resource.ID], target.Uid)
+			}
+		}
+	}
+	return assignments
+}
+
+type classifyResult struct {
+	Tags []string `json:"tags"`
+}
+
+// PlanSplitByLLM 由大模型按资源内容从拆分后的标签中选择, 返回的名称按规范化后匹配
+func PlanSplitByLLM(ctx context.Context, resources []*model.Resource, targets []*model.Tags) (map[int64][]string, error) {
+	chatModel, err := llm.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(targets))
+	uids := make(map[string]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.Name
+		uids[tagname.Key(target.Name)] = target.Uid
+	}
+	parser := schema.NewMessageJSONParser[classifyResult](&schema.MessageJSONParseConfig{
+		ParseFrom: schema.MessageParseFromContent,
+	})
+
+	assignments := make(map[int64][]string)
+	for _, resource := range resources {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		content := resource.Title + "\n" + resource.Describe
+		if resource.Describe == "" {
+			content += resource.Content
+		}
+		if runes := []rune(content); len(runes) > classifyContentLimit {
+			content = string(runes[:classifyContentLimit])
+		}
+		messages, err := llm.ChatPromptClassify(ctx, content, names)
+		if err != nil {
+			return nil, err
+		}
+		respond, err := chatModel.Generate(ctx, messages)
+		if err != nil {
+			return nil, err
+		}
+		result, err := parser.Parse(ctx, respond)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range result.Tags {
+			if uid, ok := uids[tagname.Key(name)]; ok {
+				assignments[resource.ID] = append(assignments[resource.ID], uid)
+			}
+		}
+	}
+	return assignments, nil
+}
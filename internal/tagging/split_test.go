@@ -0,0 +1,50 @@
+package tagging
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/XXueTu/wise/internal/model"
+)
+
+func TestPlanSplit(t *testing.T) {
+	resources := []*model.Resource{
+		{ID: 1, Title: "Vue 3 入门"},
+		{ID: 2, Title: "Gin 中间件", Describe: "使用 Golang 编写 Web 服务"},
+		{ID: 3, Title: "周末随笔"},
+	}
+	tests := []struct {
+		name    string
+		targets []SplitTarget
+		want    map[int64][]string
+	}{
+		{
+			name: "keywords ignore case",
+			targets: []SplitTarget{
+				{Uid: "frontend", Keywords: []string{"vue", " react "}},
+				{Uid: "backend", Keywords: []string{"golang"}},
+			},
+			want: map[int64][]string{1: {"frontend"}, 2: {"backend"}},
+		},
+		{
+			name: "resource ids and multiple targets",
+			targets: []SplitTarget{
+				{Uid: "frontend", ResourceIDs: []int64{2}, Keywords: []string{"vue"}},
+				{Uid: "backend", Keywords: []string{"web", ""}},
+			},
+			want: map[int64][]string{1: {"frontend"}, 2: {"frontend", "backend"}},
+		},
+		{
+			name:    "no rules",
+			targets: []SplitTarget{{Uid: "other"}},
+			want:    map[int64][]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlanSplit(resources, tt.targets); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanSplit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
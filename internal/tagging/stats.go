@@ -0,0 +1,135 @@
+package tagging
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/collection"
+
+	"github.com/XXueTu/wise/internal/model"
+)
+
+const (
+	// 统计结果的缓存时间, 写入后按数据版本失效, 过期只用于释放不再使用的结果
+	statsCacheExpire = 10 * time.Minute
+	// 缓存的统计结果数量
+	statsCacheLimit = 256
+)
+
+// Usage 标签及其关联的资源数
+type Usage struct {
+	Tag   *model.Tags
+	Count int64 // 直接关联的资源数
+}
+
+// Stats 标签使用统计, 结果按数据库中记录的版本缓存, 任一进程写入标签或资源关联后自动失效
+type Stats struct {
+	db           *model.DB
+	tags         *model.TagsModel
+	resourceTags *model.ResourceTagsModel
+	cache        *collection.Cache
+}
+
+func NewStats(db *model.DB, tags *model.TagsModel, resourceTags *model.ResourceTagsModel) *Stats {
+	cache, err := collection.NewCache(statsCacheExpire, collection.WithName("tag-stats"), collection.WithLimit(statsCacheLimit))
+	if err != nil {
+		panic(err)
+	}
+	return &Stats{
+		db:           db,
+		tags:         tags,
+		resourceTags: resourceTags,
+		cache:        cache,
+	}
+}
+
+// Usage 全部标签的使用次数, 按次数从多到少排序, 次数相同时按名称排序
+func (s *Stats) Usage(ctx context.Context) ([]Usage, error) {
+	return take(ctx, s, "usage", func() ([]Usage, error) {
+		tags, err := s.tags.GetAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		counts, err := s.resourceTags.CountByTag(ctx)
+		if err != nil {
+			return nil, err
+		}
+		byUid := make(map[string]int64, len(counts))
+		for _, count := range counts {
+			byUid[count.TagUid] = count.Count
+		}
+		usage := make([]Usage, len(tags))
+		for i, tag := range tags {
+			usage[i] = Usage{Tag: tag, Count: byUid[tag.Uid]}
+		}
+		sort.SliceStable(usage, func(i, j int) bool {
+			if usage[i].Count != usage[j].Count {
+				return usage[i].Count > usage[j].Count
+			}
+			return usage[i].Tag.Name < usage[j].Tag.Name
+		})
+		return usage, nil
+	})
+}
+
+// Orphans 自身和子孙标签都没有关联资源的标签, 不包含默认标签
+func (s *Stats) Orphans(ctx context.Context) ([]*model.Tags, error) {
+	usage, err := s.Usage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	parents := make(map[string]string, len(usage))
+	for _, u := range usage {
+		parents[u.Tag.Uid] = u.Tag.ParentUid
+	}
+	// 有资源的标签及其祖先都不是孤立标签, 层数限制避免历史数据中的环
+	used := make(map[string]bool)
+	for _, u := range usage {
+		if u.Count == 0 {
+			continue
+		}
+		for uid, depth := u.Tag.Uid, 0; uid != "" && !used[uid] && depth < len(usage); uid, depth = parents[uid], depth+1 {
+			used[uid] = true
+		}
+	}
+	var orphans []*model.Tags
+	for _, u := range usage {
+		if !used[u.Tag.Uid] && u.Tag.Uid != model.DefaultTagUid {
+			orphans = append(orphans, u.Tag)
+		}
+	}
+	return orphans, nil
+}
+
+// Growth 按标签和时间统计 [start, end) 内新增的关联数
+func (s *Stats) Growth(ctx context.Context, start, end time.Time, period string) ([]*model.TagCount, error) {
+	key := fmt.Sprintf("growth:%d:%d:%s", start.Unix(), end.Unix(), period)
+	return take(ctx, s, key, func() ([]*model.TagCount, error) {
+		return s.resourceTags.CountByPeriod(ctx, start, end, period)
+	})
+}
+
+// Pairs 同时出现次数最多的标签对
+func (s *Stats) Pairs(ctx context.Context, limit int) ([]*model.TagPair, error) {
+	return take(ctx, s, fmt.Sprintf("pairs:%d", limit), func() ([]*model.TagPair, error) {
+		return s.resourceTags.CoOccurrence(ctx, limit)
+	})
+}
+
+// take 按当前数据版本读取缓存, 读取版本失败时不缓存
+func take[T any](ctx context.Context, s *Stats, key string, fetch func() (T, error)) (T, error) {
+	version, err := s.db.Version(ctx, model.DataTagStats)
+	if err != nil {
+		return fetch()
+	}
+	value, err := s.cache.Take(fmt.Sprintf("%d:%s", version, key), func() (any, error) {
+		return fetch()
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return value.(T), nil
+}
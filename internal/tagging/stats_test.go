@@ -0,0 +1,93 @@
+package tagging
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/model/migrations"
+)
+
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	tags, resources, resourceTags := model.NewTagsModel(db), model.NewResourceModel(db), model.NewResourceTagsModel(db)
+	// 后端下的 Golang 有资源, 前端及其子标签 Vue 没有资源
+	err := tags.CreateBatch(ctx, []model.Tags{
+		{Uid: model.DefaultTagUid, Name: "默认"},
+		{Uid: "backend", Name: "后端"},
+		{Uid: "go", Name: "Golang", ParentUid: "backend"},
+		{Uid: "frontend", Name: "前端"},
+		{Uid: "vue", Name: "Vue", ParentUid: "frontend"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resource := &model.Resource{Title: "gin"}
+	if err := resources.Create(ctx, resource); err != nil {
+		t.Fatal(err)
+	}
+	if err := resourceTags.SetTags(ctx, resource.ID, []string{"go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := NewStats(db, tags, resourceTags)
+	orphans, err := stats.Orphans(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tag := range orphans {
+		names = append(names, tag.Name)
+	}
+	if strings.Join(names, ",") != "Vue,前端" {
+		t.Errorf("Orphans = %v, want [Vue 前端]", names)
+	}
+
+	// 写入后缓存失效
+	usage, err := stats.Usage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage[0].Tag.Uid != "go" || usage[0].Count != 1 {
+		t.Errorf("Usage first = %s:%d, want go:1", usage[0].Tag.Uid, usage[0].Count)
+	}
+	if err := resourceTags.AddTags(ctx, resource.ID, []string{"vue"}); err != nil {
+		t.Fatal(err)
+	}
+	if orphans, err = stats.Orphans(ctx); err != nil || len(orphans) != 0 {
+		t.Errorf("Orphans after AddTags = %d, %v, want none", len(orphans), err)
+	}
+}
+
+func TestStatsOtherProcess(t *testing.T) {
+	ctx := context.Background()
+	// 两个连接打开同一个数据库, 模拟 API 进程和 worker 进程
+	c := config.DatabaseConfig{Driver: model.DriverSQLite, Path: filepath.Join(t.TempDir(), "wise.db"), WAL: true, Synchronous: "NORMAL", BusyTimeout: time.Second}
+	api, worker := model.OpenDB(c), model.OpenDB(c)
+	t.Cleanup(func() {
+		_ = api.Close()
+		_ = worker.Close()
+	})
+	if _, err := migrations.Up(ctx, api.Writer); err != nil {
+		t.Fatal(err)
+	}
+	tags, resourceTags := model.NewTagsModel(api), model.NewResourceTagsModel(api)
+	if err := tags.Create(ctx, &model.Tags{Uid: "go", Name: "Go"}); err != nil {
+		t.Fatal(err)
+	}
+	stats := NewStats(api, tags, resourceTags)
+	if usage, err := stats.Usage(ctx); err != nil || usage[0].Count != 0 {
+		t.Fatalf("Usage = %v, %v, want go:0", usage, err)
+	}
+	if err := model.NewResourceTagsModel(worker).AddTags(ctx, 1, []string{"go"}); err != nil {
+		t.Fatal(err)
+	}
+	if usage, err := stats.Usage(ctx); err != nil || usage[0].Count != 1 {
+		t.Errorf("Usage after write in other process = %v, %v, want go:1", usage, err)
+	}
+}
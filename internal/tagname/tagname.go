@@ -0,0 +1,48 @@
+// Package tagname 标签名称的规范化, 模型和自动标注按同一规则判断名称是否指同一个标签
+// 迁移中保留编写时规则的副本, 修改规则后已有数据需要新的迁移重新计算
+package tagname
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 编程语言名称后的后缀, 如 Go语言、Rust language, 长的在前
+var languageSuffixes = []string{"programminglanguage", "编程语言", "language", "语言"}
+
+// 去掉后缀后需要是已知的编程语言, Sign Language、Natural Language 等名称保留后缀
+var languageNames = map[string]bool{
+	"ada": true, "assembly": true, "c": true, "c#": true, "c++": true, "clojure": true, "cobol": true,
+	"crystal": true, "d": true, "dart": true, "elixir": true, "elm": true, "erlang": true, "f#": true,
+	"fortran": true, "go": true, "groovy": true, "haskell": true, "java": true, "javascript": true,
+	"julia": true, "kotlin": true, "lisp": true, "lua": true, "matlab": true, "nim": true,
+	"objectivec": true, "ocaml": true, "pascal": true, "perl": true, "php": true, "prolog": true,
+	"python": true, "r": true, "ruby": true, "rust": true, "scala": true, "scheme": true,
+	"smalltalk": true, "solidity": true, "sql": true, "swift": true, "typescript": true,
+	"v": true, "visualbasic": true, "zig": true,
+}
+
+// 名称中忽略的连接符
+const separators = "-_·・"
+
+// Key 返回名称的规范化形式
+// 全角字符转为半角, 忽略大小写、空白和连接符, 编程语言名称忽略语言后缀, 自然语言、Sign Language 等名称保留后缀
+func Key(name string) string {
+	name = strings.ToLower(norm.NFKC.String(name))
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsSpace(r) || strings.ContainsRune(separators, r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	key := b.String()
+	for _, suffix := range languageSuffixes {
+		if stem, ok := strings.CutSuffix(key, suffix); ok && languageNames[stem] {
+			return stem
+		}
+	}
+	return key
+}
@@ -0,0 +1,33 @@
+package tagname
+
+import "testing"
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Go", "go"},
+		{"Go语言", "go"},
+		{"go 语言", "go"},
+		{"Ｇｏ　语言", "go"},
+		{"C 编程语言", "c"},
+		{"Rust Programming Language", "rust"},
+		{"machine-learning", "machinelearning"},
+		{"Machine Learning", "machinelearning"},
+		{"C++", "c++"},
+		{"自然语言", "自然语言"},
+		{"语言", "语言"},
+		{"Language", "language"},
+		{"Sign Language", "signlanguage"},
+		{"Natural Language", "naturallanguage"},
+		{"Programming Language", "programminglanguage"},
+		{"Objective-C 语言", "objectivec"},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := Key(tt.name); got != tt.want {
+			t.Errorf("Key(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package task
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/tagname"
+	"github.com/XXueTu/wise/pkg/importer"
+)
+
+const (
+	errDuplicateInBatch = "批次内重复"
+	errResourceExists   = "资源已存在"
+	errTaskExists       = "已有未完成的解析任务"
+)
+
+// CreateBatch 创建批量导入, 为每个新链接创建低优先级的解析任务, 已存在或重复的链接记为重复
+// 链接的文件夹和标签转为标签, 带有标题、收藏时间或标签的链接先保存为资源, 解析完成后补充内容
+// 批次和明细在一个事务中先写入, 之后再创建任务, 任务执行时明细已存在
+// 写入明细后进程退出导致任务未创建的, 由任务回收将明细标记为无效
+func CreateBatch(ctx context.Context, svc *svc.ServiceContext, name string, source string, links []importer.Link) (*model.Batches, []*model.BatchItems, error) {
+	batch := &model.Batches{
+		Bid:    model.GenUid(),
+		Name:   name,
+		Source: source,
+		Total:  int64(len(links)),
+	}
+	items := planBatch(ctx, svc, batch.Bid, links)
+	for _, item := range items {
+		if item.Status != "" {
+			continue
+		}
+		if _, err := ValidateParams(svc, TypeUrlAnalyse, item.URL); err != nil {
+			item.Status = model.BatchItemStatusInvalid
+			item.Error = err.Error()
+			continue
+		}
+		item.Tid = model.GenUid()
+		item.Status = model.BatchItemStatusQueued
+	}
+	tagUids, err := svc.TagsModel.EnsureByNames(ctx, pendingTagNames(links, items), "导入")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := svc.BatchesModel.CreateWithItems(ctx, batch, items); err != nil {
+		return nil, nil, err
+	}
+	for i, item := range items {
+		if item.Status != model.BatchItemStatusQueued {
+			continue
+		}
+		resourceID, err := importResource(ctx, svc, links[i], tagUids)
+		if err == nil {
+			_, err = createTask(ctx, svc, item.Tid, item.URL, "解析URL", TypeUrlAnalyse, model.TaskPriorityLow)
+			// 任务未创建时删除预先保存的资源, 否则链接会被当作已存在
+			if err != nil && resourceID > 0 {
+				_ = svc.ResourceModel.Delete(ctx, resourceID)
+			}
+		}
+		if err != nil {
+			_ = svc.BatchItemsModel.MarkInvalid(ctx, item.Tid, err.Error())
+			item.Tid = ""
+			item.Status = model.BatchItemStatusInvalid
+			item.Error = err.Error()
+		}
+	}
+	logx.Infof("创建批量导入: %s, total: %d", batch.Bid, batch.Total)
+	return batch, items, nil
+}
+
+// PreviewBatch 预览批量导入, 不创建任务和标签, 返回每个链接的处理结果和需要新建的标签
+func PreviewBatch(ctx context.Context, svc *svc.ServiceContext, links []importer.Link) ([]*model.BatchItems, []string, error) {
+	items := planBatch(ctx, svc, "", links)
+	for _, item := range items {
+		if item.Status != "" {
+			continue
+		}
+		if _, err := ValidateParams(svc, TypeUrlAnalyse, item.URL); err != nil {
+			item.Status = model.BatchItemStatusInvalid
+			item.Error = err.Error()
+			continue
+		}
+		item.Status = model.BatchItemStatusQueued
+	}
+	names := pendingTagNames(links, items)
+	if len(names) == 0 {
+		return items, names, nil
+	}
+	exists, err := svc.TagsModel.MatchNames(ctx, names)
+	if err != nil {
+		logx.Errorf("PreviewBatch MatchNames names: %v, error: %v", names, err)
+		return nil, nil, err
+	}
+	// 规范化后相同的名称只会创建一个标签
+	newTags := make([]string, 0)
+	newKeys := make(map[string]bool)
+	for _, name := range names {
+		if _, ok := exists[name]; ok {
+			continue
+		}
+		if key := tagname.Key(name); !newKeys[key] {
+			newKeys[key] = true
+			newTags = append(newTags, name)
+		}
+	}
+	return items, newTags, nil
+}
+
+// planBatch 生成批次明细, 重复的链接标记为重复, 其余状态为空等待创建任务
+func planBatch(ctx context.Context, svc *svc.ServiceContext, bid string, links []importer.Link) []*model.BatchItems {
+	items := make([]*model.BatchItems, 0, len(links))
+	seen := make(map[string]bool, len(links))
+	for _, link := range links {
+		item := &model.BatchItems{
+			Bid:   bid,
+			URL:   link.URL,
+			Title: link.Title,
+		}
+		items = append(items, item)
+		if reason := duplicateReason(ctx, svc, seen, link.URL); reason != "" {
+			item.Status = model.BatchItemStatusDuplicate
+			item.Error = reason
+		}
+	}
+	return items
+}
+
+// duplicateReason 判断链接是否重复, 返回重复原因, 不重复返回空
+func duplicateReason(ctx context.Context, svc *svc.ServiceContext, seen map[string]bool, url string) string {
+	if seen[url] {
+		return errDuplicateInBatch
+	}
+	seen[url] = true
+	if exists, err := svc.ResourceModel.ExistsURL(ctx, url); err == nil && exists {
+		return errResourceExists
+	}
+	if exists, err := svc.TasksModel.ExistsActive(ctx, TypeUrlAnalyse, url); err == nil && exists {
+		return errTaskExists
+	}
+	return ""
+}
+
+// pendingTagNames 汇总待导入链接的标签名称
+func pendingTagNames(links []importer.Link, items []*model.BatchItems) []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for i, item := range items {
+		if item.Status != "" && item.Status != model.BatchItemStatusQueued {
+			continue
+		}
+		for _, name := range links[i].TagNames() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// importResource 保存链接的标题、收藏时间和标签, 没有这些信息时由解析任务创建资源, 返回资源ID
+func importResource(ctx context.Context, svc *svc.ServiceContext, link importer.Link, tagUids map[string]string) (int64, error) {
+	names := link.TagNames()
+	if link.Title == "" && link.AddedAt.IsZero() && len(names) == 0 {
+		return 0, nil
+	}
+	uids := make([]string, 0, len(names))
+	for _, name := range names {
+		uids = append(uids, tagUids[name])
+	}
+	resource := &model.Resource{
+		URL:       link.URL,
+		Title:     link.Title,
+		CreatedAt: link.AddedAt,
+		UpdatedAt: link.AddedAt,
+	}
+	if err := svc.ResourceModel.Create(ctx, resource); err != nil {
+		return 0, err
+	}
+	if err := svc.ResourceTagsModel.SetTags(ctx, resource.ID, uids); err != nil {
+		_ = svc.ResourceModel.Delete(ctx, resource.ID)
+		return 0, err
+	}
+	return resource.ID, nil
+}
@@ -0,0 +1,77 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/XXueTu/wise/internal/archive"
+	"github.com/XXueTu/wise/internal/svc"
+)
+
+// TypeExport 导出知识库任务
+const TypeExport = "EXPORT"
+
+func init() {
+	Register(TypeExport, newExportHandler)
+}
+
+// exportHandler 导出知识库, 参数为导出格式 json 或 markdown
+type exportHandler struct {
+	svc *svc.ServiceContext
+}
+
+func newExportHandler(svc *svc.ServiceContext) TaskHandler {
+	return &exportHandler{svc: svc}
+}
+
+func (h *exportHandler) Validate(params string) error {
+	if params != archive.FormatJson && params != archive.FormatMarkdown {
+		return fmt.Errorf("导出格式仅支持 %s 或 %s", archive.FormatJson, archive.FormatMarkdown)
+	}
+	return nil
+}
+
+func (h *exportHandler) TotalSteps() int64 {
+	return 1
+}
+
+func (h *exportHandler) Handle(ctx context.Context, tid string, params string) error {
+	data, err := archive.Export(ctx, h.svc)
+	if err != nil {
+		return err
+	}
+	path := ExportPath(h.svc.Config.Archive.Dir, tid, params)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// 先写临时文件, 完成后重命名, 避免下载到未写完的文件
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if params == archive.FormatMarkdown {
+		err = archive.WriteMarkdown(f, data)
+	} else {
+		err = archive.WriteJson(f, data)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ExportPath 导出文件路径, json 格式为 .json, markdown 格式为 .zip
+func ExportPath(dir string, tid string, format string) string {
+	ext := ".json"
+	if format == archive.FormatMarkdown {
+		ext = ".zip"
+	}
+	return filepath.Join(dir, "wise-export-"+tid+ext)
+}
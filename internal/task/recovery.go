@@ -0,0 +1,104 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+)
+
+const (
+	errTaskInterrupted = "任务中断: 租约过期"  // 进程异常退出导致任务中断
+	errTaskShutdown    = "任务中断: 服务停止"  // 服务停止时任务被取消
+	errTaskNotCreated  = "任务未创建: 服务中断" // 批量导入写入明细后未创建任务
+
+	// 宽限期结束取消任务后, 再等待任务退出的时间
+	cancelWait = 5 * time.Second
+)
+
+// leaseOwner 生成当前实例的租约持有者标识
+func leaseOwner() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), model.GenUid())
+}
+
+// heartbeat 定期为执行中的任务续约, 返回停止函数
+func (s *TaskScheduler) heartbeat(tid string) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.leaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = s.svc.TasksModel.RenewLease(context.Background(), tid, s.owner, time.Now().Add(s.leaseTTL))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+	}
+}
+
+// recoverTasks 回收租约过期的运行中任务, 还有重试次数的重新入队, 否则进入死信
+// 同时将任务未创建的批量导入明细标记为无效
+func (s *TaskScheduler) recoverTasks() {
+	ctx := context.Background()
+	tasks, err := s.svc.TasksModel.GetExpiredLease(ctx, time.Now())
+	if err != nil {
+		logx.Errorf("recoverTasks error: %v", err)
+		return
+	}
+	for _, task := range tasks {
+		policy := retryPolicy(s.svc.Config.Task, task.Types)
+		task.Error = errTaskInterrupted
+		if task.RetryCount >= policy.MaxRetries {
+			task.Status = model.TaskStatusDead
+		} else {
+			task.Status = model.TaskStatusRetry
+			task.RetryCount++
+			task.NextRunAt = time.Now()
+		}
+		// 多个实例同时回收时只有一个成功
+		recovered, err := s.svc.TasksModel.Recover(ctx, task, time.Now())
+		if err != nil || !recovered {
+			continue
+		}
+		if task.Status == model.TaskStatusRetry {
+			_ = s.svc.TaskQueue.Push(ctx, task)
+		}
+		_ = s.svc.TaskPlansModel.FailUnfinished(ctx, task.Tid, errTaskInterrupted)
+		s.publishStatus(task.Tid, task.Status, task.Error)
+		logx.Infof("回收中断任务: %v, owner: %s, status: %s", task.Tid, task.LeaseOwner, task.Status)
+	}
+	// 批量导入正在创建的任务留出一个租约时长
+	if rows, err := s.svc.BatchItemsModel.MarkOrphaned(ctx, time.Now().Add(-s.leaseTTL), errTaskNotCreated); err == nil && rows > 0 {
+		logx.Infof("批量导入明细未创建任务, 标记为无效: %d", rows)
+	}
+}
+
+// interruptTask 将被中断的任务标记为可恢复, 不计入重试次数
+func (s *TaskScheduler) interruptTask(tid string) {
+	ctx := context.Background()
+	_ = s.svc.TaskPlansModel.FailUnfinished(ctx, tid, errTaskShutdown)
+	task, err := s.svc.TasksModel.GetByTid(ctx, tid)
+	if err != nil {
+		logx.Errorf("interruptTask tid: %s, error: %v", tid, err)
+		return
+	}
+	task.Status = model.TaskStatusRetry
+	task.Error = errTaskShutdown
+	task.NextRunAt = time.Now()
+	if !s.release(ctx, task) {
+		return
+	}
+	_ = s.svc.TaskQueue.Push(ctx, task)
+	s.publishStatus(tid, task.Status, task.Error)
+	logx.Infof("任务已中断, 下次启动继续执行: %v", tid)
+}
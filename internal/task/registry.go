@@ -0,0 +1,81 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/XXueTu/wise/internal/svc"
+)
+
+// ErrUnknownTaskType 未注册的任务类型
+var ErrUnknownTaskType = errors.New("unknown task type")
+
+// TaskHandler 任务处理器, 每种任务类型注册一个
+type TaskHandler interface {
+	// Validate 创建任务时校验参数
+	Validate(params string) error
+	// TotalSteps 任务总步骤
+	TotalSteps() int64
+	// Handle 执行任务, ctx 在停止或超时时取消
+	Handle(ctx context.Context, tid string, params string) error
+}
+
+// HandlerFactory 使用服务上下文创建任务处理器
+type HandlerFactory func(svc *svc.ServiceContext) TaskHandler
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = make(map[string]HandlerFactory)
+)
+
+// Register 注册任务类型的处理器, 重复注册会 panic
+func Register(types string, factory HandlerFactory) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	if factory == nil {
+		panic("task: Register factory is nil")
+	}
+	if _, ok := handlers[types]; ok {
+		panic("task: Register called twice for type " + types)
+	}
+	handlers[types] = factory
+}
+
+// GetHandler 使用服务上下文创建任务类型的处理器
+func GetHandler(svc *svc.ServiceContext, types string) (TaskHandler, error) {
+	handlersMu.RLock()
+	factory, ok := handlers[types]
+	handlersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTaskType, types)
+	}
+	return factory(svc), nil
+}
+
+// Types 已注册的任务类型
+func Types() []string {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	types := make([]string, 0, len(handlers))
+	for t := range handlers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// ValidateParams 校验任务类型和参数, 返回对应的处理器
+func ValidateParams(svc *svc.ServiceContext, types string, params string) (TaskHandler, error) {
+	handler, err := GetHandler(svc, types)
+	if err != nil {
+		return nil, fmt.Errorf("不支持的任务类型: %s, 可选: %s", types, strings.Join(Types(), ","))
+	}
+	if err := handler.Validate(params); err != nil {
+		return nil, fmt.Errorf("任务参数错误: %w", err)
+	}
+	return handler, nil
+}
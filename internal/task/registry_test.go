@@ -0,0 +1,38 @@
+package task
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		types   string
+		params  string
+		wantErr bool
+	}{
+		{name: "url", types: TypeUrlAnalyse, params: "https://github.com/golang/go", wantErr: false},
+		{name: "url-space", types: TypeUrlAnalyse, params: " http://example.com/a ", wantErr: false},
+		{name: "url-invalid", types: TypeUrlAnalyse, params: "not a url", wantErr: true},
+		{name: "url-scheme", types: TypeUrlAnalyse, params: "ftp://example.com", wantErr: true},
+		{name: "unknown-type", types: "UNKNOWN", params: "{}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ValidateParams(nil, tt.types, tt.params); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetHandlerUnknown(t *testing.T) {
+	_, err := GetHandler(nil, "UNKNOWN")
+	if !errors.Is(err, ErrUnknownTaskType) {
+		t.Errorf("GetHandler() error = %v, want %v", err, ErrUnknownTaskType)
+	}
+	if IsRetryable(Permanent(err)) {
+		t.Errorf("unknown task type should not be retryable")
+	}
+}
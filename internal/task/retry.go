@@ -0,0 +1,141 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/pkg/spiders"
+)
+
+// 默认重试策略, 未配置时使用
+var defaultRetry = config.RetryConfig{
+	MaxRetries: 2,
+	BaseDelay:  5 * time.Second,
+	MaxDelay:   10 * time.Minute,
+}
+
+// permanentError 不可重试的错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent 标记错误为不可重试, 任务直接进入死信
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// 不可重试的错误信息, 用于错误链被转成字符串后的兜底判断
+var permanentMessages = []string{
+	spiders.ErrNotSupportedSpider.Error(),
+}
+
+// 可重试的错误信息, 网络抖动和大模型限流
+var retryableMessages = []string{
+	"timeout",
+	"connection reset",
+	"connection refused",
+	"eof",
+	"429",
+	"rate limit",
+	"too many requests",
+	"502",
+	"503",
+	"504",
+}
+
+// IsRetryable 判断错误是否可以重试
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pe *permanentError
+	if errors.As(err, &pe) || errors.Is(err, spiders.ErrNotSupportedSpider) {
+		return false
+	}
+	// 用户取消的任务不再重试
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range permanentMessages {
+		if strings.Contains(msg, m) {
+			return false
+		}
+	}
+	for _, m := range retryableMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	// 未识别的错误按可重试处理, 由重试次数兜底
+	return true
+}
+
+// retryPolicy 获取任务类型对应的重试策略
+func retryPolicy(c config.TaskConfig, types string) config.RetryConfig {
+	policy := defaultRetry
+	for _, r := range c.Retry {
+		if r.Types == "" {
+			policy = mergeRetry(policy, r)
+		}
+	}
+	for _, r := range c.Retry {
+		if r.Types != "" && r.Types == types {
+			policy = mergeRetry(policy, r)
+		}
+	}
+	return policy
+}
+
+func mergeRetry(base, r config.RetryConfig) config.RetryConfig {
+	if r.MaxRetries > 0 {
+		base.MaxRetries = r.MaxRetries
+	}
+	if r.BaseDelay > 0 {
+		base.BaseDelay = r.BaseDelay
+	}
+	if r.MaxDelay > 0 {
+		base.MaxDelay = r.MaxDelay
+	}
+	return base
+}
+
+// backoff 计算第 retryCount 次重试的等待时间, 指数退避加随机抖动
+func backoff(policy config.RetryConfig, retryCount int64) time.Duration {
+	delay := policy.BaseDelay
+	for i := int64(1); i < retryCount && delay < policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	// 在 [delay/2, delay] 之间抖动, 避免同时失败的任务集中重试
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
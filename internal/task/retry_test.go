@@ -0,0 +1,81 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/XXueTu/wise/internal/config"
+	"github.com/XXueTu/wise/pkg/spiders"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "not-supported-spider", err: fmt.Errorf("read: %w", spiders.ErrNotSupportedSpider), want: false},
+		{name: "not-supported-spider-message", err: errors.New("[NodeRunError] not supported spider"), want: false},
+		{name: "permanent", err: Permanent(errors.New("bad params")), want: false},
+		{name: "canceled", err: fmt.Errorf("[NodeRunError] %w", context.Canceled), want: false},
+		{name: "deadline", err: context.DeadlineExceeded, want: true},
+		{name: "rate-limit", err: errors.New("error, status code: 429, message: Too Many Requests"), want: true},
+		{name: "unknown", err: errors.New("something wrong"), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	c := config.TaskConfig{
+		Retry: []config.RetryConfig{
+			{MaxRetries: 4},
+			{Types: "URL_ANALYSE", BaseDelay: time.Minute},
+		},
+	}
+	tests := []struct {
+		name  string
+		types string
+		want  config.RetryConfig
+	}{
+		{name: "default", types: "EXPORT", want: config.RetryConfig{MaxRetries: 4, BaseDelay: 5 * time.Second, MaxDelay: 10 * time.Minute}},
+		{name: "by-type", types: "URL_ANALYSE", want: config.RetryConfig{MaxRetries: 4, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryPolicy(c, tt.types); got != tt.want {
+				t.Errorf("retryPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := config.RetryConfig{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	tests := []struct {
+		name       string
+		retryCount int64
+		max        time.Duration
+	}{
+		{name: "first", retryCount: 1, max: time.Second},
+		{name: "third", retryCount: 3, max: 4 * time.Second},
+		{name: "capped", retryCount: 10, max: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := backoff(policy, tt.retryCount)
+			if got < tt.max/2 || got > tt.max {
+				t.Errorf("backoff() = %v, want in [%v, %v]", got, tt.max/2, tt.max)
+			}
+		})
+	}
+}
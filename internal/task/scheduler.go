@@ -2,40 +2,54 @@ package task
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 	"golang.org/x/sync/semaphore"
 
+	"github.com/XXueTu/wise/internal/event"
 	"github.com/XXueTu/wise/internal/model"
 	"github.com/XXueTu/wise/internal/svc"
-	"github.com/XXueTu/wise/pkg/agent/url_analyse.go"
 )
 
-// CreateTask 创建任务
-func CreateTask(ctx context.Context, svc *svc.ServiceContext, args string, taskName string, taskType string, totalStep int64) error {
-	tid := model.GenUid()
-	// 创建任务
-	err := svc.TasksModel.Create(ctx, &model.Tasks{
+// CreateTask 校验参数并创建任务, 未注册的任务类型会被拒绝
+func CreateTask(ctx context.Context, svc *svc.ServiceContext, args string, taskName string, taskType string, priority int64) (*model.Tasks, error) {
+	return createTask(ctx, svc, model.GenUid(), args, taskName, taskType, priority)
+}
+
+// createTask 使用预先分配的唯一标识创建任务, 批量导入先记录明细再创建任务
+func createTask(ctx context.Context, svc *svc.ServiceContext, tid string, args string, taskName string, taskType string, priority int64) (*model.Tasks, error) {
+	handler, err := ValidateParams(svc, taskType, args)
+	if err != nil {
+		return nil, err
+	}
+	task := &model.Tasks{
 		Tid:          tid,
 		Name:         taskName,
 		Types:        taskType,
 		Status:       model.TaskStatusInit,
 		CurrentState: "start",
 		RetryCount:   0,
-		TotalSteps:   totalStep,
+		TotalSteps:   handler.TotalSteps(),
 		CurrentStep:  0,
 		Params:       args,
 		Result:       "{}",
 		Duration:     0,
 		Error:        "{}",
 		Extend:       "{}",
-	})
-	if err != nil {
-		return err
+		Priority:     priority,
 	}
-	return nil
+	// 创建任务
+	if err := svc.TasksModel.Create(ctx, task); err != nil {
+		logx.Errorf("CreateTask types: %s, params: %s, error: %v", taskType, args, err)
+		return nil, err
+	}
+	// 任务已落库, 推送失败时由 SQLite 队列兜底或重新入队
+	_ = svc.TaskQueue.Push(ctx, task)
+	return task, nil
 }
 
 // TaskScheduler 任务调度器
@@ -47,12 +61,24 @@ type TaskScheduler struct {
 	scanInterval time.Duration
 	stopChan     chan struct{}
 	wg           sync.WaitGroup
-	taskCtxs     sync.Map // 存储任务上下文，用于取消任务
+	taskCtxs     sync.Map                       // 存储任务上下文，用于取消任务
+	typeLimits   map[string]*semaphore.Weighted // 按任务类型限制并发
+	owner        string                         // 当前实例标识, 作为任务租约持有者
+	leaseTTL     time.Duration                  // 租约时长, 执行中定期续约
+	stopOnce     sync.Once
+	draining     atomic.Bool // 正在停止, 被取消的任务标记为可恢复而不是失败
+	paused       atomic.Bool // 暂停调度, 恢复数据库等维护操作期间不领取新任务
+	scanMu       sync.Mutex  // 保证暂停后没有进行中的调度
 }
 
 // NewTaskScheduler 创建任务调度器
 func NewTaskScheduler(svc *svc.ServiceContext) *TaskScheduler {
-
+	typeLimits := make(map[string]*semaphore.Weighted)
+	for _, limit := range svc.Config.Task.Limits {
+		if limit.MaxWorkers > 0 {
+			typeLimits[limit.Types] = semaphore.NewWeighted(limit.MaxWorkers)
+		}
+	}
 	return &TaskScheduler{
 		svc:          svc,
 		maxWorkers:   int64(svc.Config.Task.PoolSize),
@@ -60,18 +86,103 @@ func NewTaskScheduler(svc *svc.ServiceContext) *TaskScheduler {
 		scanInterval: 10 * time.Second,
 		stopChan:     make(chan struct{}),
 		workerPool:   semaphore.NewWeighted(int64(svc.Config.Task.PoolSize)),
+		typeLimits:   typeLimits,
+		owner:        leaseOwner(),
+		leaseTTL:     time.Minute,
 	}
 }
 
 // Start 启动任务调度器
 func (s *TaskScheduler) Start() {
+	// 启动前回收上次退出时遗留的运行中任务
+	s.recoverTasks()
 	go s.startScheduler()
 }
 
-// Stop 停止任务调度器
+// Stop 停止任务调度器, 等待执行中的任务结束
 func (s *TaskScheduler) Stop() {
-	close(s.stopChan)
-	s.wg.Wait()
+	s.Shutdown(0)
+}
+
+// Shutdown 停止调度并在宽限期内等待执行中的任务结束, 超时后取消任务并标记为可恢复
+// grace 小于等于 0 时一直等待, 重复调用会等待第一次调用完成
+func (s *TaskScheduler) Shutdown(grace time.Duration) {
+	s.stopOnce.Do(func() {
+		s.draining.Store(true)
+		close(s.stopChan)
+		if s.waitWorkers(grace) {
+			logx.Info("任务调度器已停止")
+			return
+		}
+
+		logx.Infof("等待任务结束超时 %v, 取消执行中的任务", grace)
+		s.taskCtxs.Range(func(_, value any) bool {
+			if cancel, ok := value.(context.CancelFunc); ok {
+				cancel()
+			}
+			return true
+		})
+		if s.waitWorkers(cancelWait) {
+			logx.Info("任务调度器已停止")
+			return
+		}
+
+		// 仍未退出的任务直接标记为可恢复, 由下次启动继续执行
+		s.taskCtxs.Range(func(key, _ any) bool {
+			s.interruptTask(key.(string))
+			return true
+		})
+		logx.Info("任务调度器已停止, 部分任务未能退出")
+	})
+}
+
+// pausedGlobally 是否通过数据库暂停了所有实例的调度, 如其他进程正在恢复备份
+func (s *TaskScheduler) pausedGlobally() bool {
+	paused, err := s.svc.TaskPausesModel.Paused(context.Background(), time.Now())
+	if err != nil {
+		logx.Errorf("pausedGlobally error: %v", err)
+		return false
+	}
+	return paused
+}
+
+// Pause 暂停领取新任务, 并在超时前等待执行中的任务结束, 超时则恢复调度并返回错误
+func (s *TaskScheduler) Pause(timeout time.Duration) error {
+	s.paused.Store(true)
+	// 等待进行中的调度结束, 之后不会再有新任务启动
+	s.scanMu.Lock()
+	s.scanMu.Unlock()
+	if !s.waitWorkers(timeout) {
+		s.paused.Store(false)
+		return errors.New("等待执行中的任务结束超时")
+	}
+	logx.Info("任务调度器已暂停")
+	return nil
+}
+
+// Resume 恢复调度
+func (s *TaskScheduler) Resume() {
+	s.paused.Store(false)
+	logx.Info("任务调度器已恢复")
+}
+
+// waitWorkers 等待所有工作协程退出, 超时返回 false
+func (s *TaskScheduler) waitWorkers(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	if timeout <= 0 {
+		<-done
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
 }
 
 // CancelTask 取消任务
@@ -98,6 +209,10 @@ func (s *TaskScheduler) startScheduler() {
 	for {
 		select {
 		case <-ticker.C:
+			if s.paused.Load() || s.pausedGlobally() {
+				continue
+			}
+			s.recoverTasks()
 			s.scanAndExecuteTasks()
 		case <-s.stopChan:
 			return
@@ -106,36 +221,55 @@ func (s *TaskScheduler) startScheduler() {
 }
 
 func (s *TaskScheduler) scanAndExecuteTasks() {
+	s.scanMu.Lock()
+	defer s.scanMu.Unlock()
+	if s.paused.Load() {
+		return
+	}
 	ctx := context.Background()
 
-	// 获取所有未完成的任务
-	tasks, err := s.svc.TasksModel.GetStatusLimit(ctx, model.TaskStatusInit, int(s.maxWorkers))
+	// 获取待执行和已到重试时间的任务, 多取一些, 以便被并发限制的类型跳过后其他类型仍能执行
+	tasks, err := s.svc.TaskQueue.Pending(ctx, int(s.maxWorkers)*4)
 	if err != nil {
 		logx.Errorf("获取任务列表失败: %v", err)
 		return
 	}
-	if len(tasks) == 0 {
-		// 查询重试任务
-		tasks, err = s.svc.TasksModel.GetStatusLimit(ctx, model.TaskStatusRetry, int(s.maxWorkers))
-		if err != nil {
-			logx.Errorf("获取重试任务列表失败: %v", err)
-			return
-		}
-		if len(tasks) == 0 {
-			return
-		}
-	}
 
 	for _, task := range tasks {
+		typeLimit := s.typeLimits[task.Types]
+		if typeLimit != nil && !typeLimit.TryAcquire(1) {
+			logx.Debugf("任务类型 %s 并发已满, 跳过任务: %v", task.Types, task.Tid)
+			continue
+		}
 		if !s.workerPool.TryAcquire(1) {
+			if typeLimit != nil {
+				typeLimit.Release(1)
+			}
 			logx.Debugf("工作协程池已满，等待下次调度")
 			return
 		}
+		// 原子领取, 其他实例已领取时跳过
+		claimed, err := s.svc.TaskQueue.Claim(ctx, task.Tid, s.owner, time.Now().Add(s.leaseTTL))
+		if err == nil && claimed {
+			// 领取后由租约保证任务不丢失, 消息可以确认
+			// 未领取的消息保留, 已被其他实例领取的下次获取时确认, 调度暂停期间未领取的之后继续执行
+			_ = s.svc.TaskQueue.Ack(ctx, task.Tid)
+		}
+		if err != nil || !claimed {
+			s.workerPool.Release(1)
+			if typeLimit != nil {
+				typeLimit.Release(1)
+			}
+			continue
+		}
 
 		s.wg.Add(1)
 		go func(t *model.Tasks) {
 			defer s.wg.Done()
 			defer s.workerPool.Release(1)
+			if typeLimit != nil {
+				defer typeLimit.Release(1)
+			}
 			s.executeTask(t)
 		}(task)
 	}
@@ -150,26 +284,92 @@ func (s *TaskScheduler) executeTask(task *model.Tasks) {
 	}()
 
 	logx.Infof("执行任务: %v", task.Tid)
-	_ = s.svc.TasksModel.UpdateStatus(ctx, task.Tid, model.TaskStatusRunning, "{}")
+	stopHeartbeat := s.heartbeat(task.Tid)
+	defer stopHeartbeat()
+	s.publishStatus(task.Tid, model.TaskStatusRunning, "")
 
 	// 执行任务
-	err := url_analyse.RunUrlAnalyseAgent(task.Tid, task.Params)
+	startedAt := time.Now()
+	handler, err := GetHandler(s.svc, task.Types)
+	if err == nil {
+		err = handler.Handle(model.WithLeaseOwner(ctx, s.owner), task.Tid, task.Params)
+	} else {
+		// 未注册的类型重试也无法执行, 直接进入死信
+		err = Permanent(err)
+	}
+	// 取消的上下文不能再写库, 使用新的上下文记录耗时
+	_ = s.svc.TasksModel.UpdateDuration(context.Background(), task.Tid, startedAt, time.Now())
 	if err != nil {
-		// 检查是否需要重试
-		if task.RetryCount < 2 {
-			task.Status = model.TaskStatusRetry
-			task.RetryCount++
-			task.Error = err.Error()
-			_ = s.svc.TasksModel.Update(ctx, task)
-			// 等待重试间隔后重新调度
-			time.Sleep(5 * time.Second)
-			logx.Infof("重试任务: %v", task.Tid)
+		if s.draining.Load() && errors.Is(ctx.Err(), context.Canceled) {
+			s.interruptTask(task.Tid)
 			return
 		}
-		_ = s.svc.TasksModel.UpdateStatus(ctx, task.Tid, model.TaskStatusFailed, err.Error())
-		logx.Errorf("执行任务失败: %v", err)
+		s.handleTaskError(task, err)
 		return
 	}
-	_ = s.svc.TasksModel.UpdateStatus(ctx, task.Tid, model.TaskStatusSuccess, "{}")
+	updated, err := s.svc.TasksModel.UpdateStatus(ctx, task.Tid, s.owner, model.TaskStatusSuccess, "{}")
+	if err != nil || !updated {
+		// 执行过程中任务被取消, 或租约过期后已被其他实例回收
+		logx.Infof("任务已不由当前实例持有, 不更新状态: %v", task.Tid)
+		return
+	}
+	s.publishStatus(task.Tid, model.TaskStatusSuccess, "")
 	logx.Infof("执行任务成功: %v", task.Tid)
 }
+
+// handleTaskError 根据错误类型和重试策略决定任务重试或进入死信
+func (s *TaskScheduler) handleTaskError(task *model.Tasks, err error) {
+	ctx := context.Background()
+	// 重新读取, 避免覆盖执行过程中更新的步骤
+	if latest, getErr := s.svc.TasksModel.GetByTid(ctx, task.Tid); getErr == nil {
+		task = latest
+	}
+	// 已取消的任务保持取消状态, 不进入重试或死信
+	if task.Status == model.TaskStatusCancelled {
+		logx.Infof("任务已取消: %v, err: %v", task.Tid, err)
+		return
+	}
+	policy := retryPolicy(s.svc.Config.Task, task.Types)
+	task.Error = err.Error()
+	if !IsRetryable(err) || task.RetryCount >= policy.MaxRetries {
+		task.Status = model.TaskStatusDead
+		if !s.release(ctx, task) {
+			return
+		}
+		s.publishStatus(task.Tid, task.Status, task.Error)
+		logx.Errorf("执行任务失败, 进入死信: %v, retry: %d, err: %v", task.Tid, task.RetryCount, err)
+		return
+	}
+	task.RetryCount++
+	task.Status = model.TaskStatusRetry
+	task.NextRunAt = time.Now().Add(backoff(policy, task.RetryCount))
+	if !s.release(ctx, task) {
+		return
+	}
+	_ = s.svc.TaskQueue.Push(ctx, task)
+	s.publishStatus(task.Tid, task.Status, task.Error)
+	logx.Infof("重试任务: %v, retry: %d, next_run_at: %s", task.Tid, task.RetryCount, task.NextRunAt.Format(time.DateTime))
+}
+
+// release 写入执行结果并释放租约, 任务已被取消或被其他实例回收时返回 false
+func (s *TaskScheduler) release(ctx context.Context, task *model.Tasks) bool {
+	released, err := s.svc.TasksModel.Release(ctx, task, s.owner, time.Now())
+	if err != nil {
+		logx.Errorf("release tid: %s, error: %v", task.Tid, err)
+		return false
+	}
+	if !released {
+		logx.Infof("任务已不由当前实例持有, 不更新状态: %v", task.Tid)
+	}
+	return released
+}
+
+// publishStatus 推送任务状态变更事件
+func (s *TaskScheduler) publishStatus(tid string, status string, errMsg string) {
+	s.svc.TaskEvents.Publish(event.TaskEvent{
+		Tid:    tid,
+		Type:   event.TaskEventStatus,
+		Status: status,
+		Error:  errMsg,
+	})
+}
@@ -0,0 +1,77 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/tagging"
+)
+
+// TypeTagRuleApply 对全部资源执行标签规则的任务
+const TypeTagRuleApply = "TAG_RULE_APPLY"
+
+func init() {
+	Register(TypeTagRuleApply, newTagRuleApplyHandler)
+}
+
+// TagRuleApplyParams 执行规则任务参数
+type TagRuleApplyParams struct {
+	RuleUids []string `json:"rule_uids"` // 执行的规则, 为空时执行全部启用的规则
+}
+
+// tagRuleApplyHandler 分批遍历全部资源, 逐个写入规则对标签的修改
+type tagRuleApplyHandler struct {
+	svc *svc.ServiceContext
+}
+
+func newTagRuleApplyHandler(svc *svc.ServiceContext) TaskHandler {
+	return &tagRuleApplyHandler{svc: svc}
+}
+
+func (h *tagRuleApplyHandler) Validate(params string) error {
+	var p TagRuleApplyParams
+	return json.Unmarshal([]byte(params), &p)
+}
+
+func (h *tagRuleApplyHandler) TotalSteps() int64 {
+	return 1
+}
+
+func (h *tagRuleApplyHandler) Handle(ctx context.Context, tid string, params string) error {
+	var p TagRuleApplyParams
+	if err := json.Unmarshal([]byte(params), &p); err != nil {
+		return err
+	}
+	var rules []*model.TagRules
+	var err error
+	if len(p.RuleUids) == 0 {
+		rules, err = h.svc.TagRulesModel.GetEnabled(ctx)
+	} else {
+		rules, err = h.svc.TagRulesModel.GetUids(ctx, p.RuleUids)
+	}
+	if err != nil {
+		return err
+	}
+	compiled := tagging.CompileRules(rules)
+	if len(compiled) == 0 {
+		return errors.New("没有可执行的规则")
+	}
+	var resources, added, removed int
+	err = h.svc.TagRules.Scan(ctx, compiled, func(resource *model.Resource, effect tagging.Effect) error {
+		if err := h.svc.TagRules.Apply(ctx, resource.ID, effect); err != nil {
+			return err
+		}
+		resources++
+		added += len(effect.Add)
+		removed += len(effect.Remove)
+		return nil
+	})
+	logx.Infof("tag rule apply tid: %s, rules: %d, resources: %d, added: %d, removed: %d",
+		tid, len(compiled), resources, added, removed)
+	return err
+}
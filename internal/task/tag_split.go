@@ -0,0 +1,79 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/internal/tagging"
+)
+
+// TypeTagSplit 由大模型重新分类的标签拆分任务
+const TypeTagSplit = "TAG_SPLIT"
+
+func init() {
+	Register(TypeTagSplit, newTagSplitHandler)
+}
+
+// TagSplitParams 拆分任务参数
+type TagSplitParams struct {
+	Uid          string   `json:"uid"`           // 原标签
+	TargetUids   []string `json:"target_uids"`   // 拆分后的标签
+	RemoveSource bool     `json:"remove_source"` // 原标签没有资源时删除
+}
+
+// tagSplitHandler 逐个资源由大模型从拆分后的标签中选择, 全部分类完成后在一个事务中改写关联
+type tagSplitHandler struct {
+	svc *svc.ServiceContext
+}
+
+func newTagSplitHandler(svc *svc.ServiceContext) TaskHandler {
+	return &tagSplitHandler{svc: svc}
+}
+
+func (h *tagSplitHandler) Validate(params string) error {
+	var p TagSplitParams
+	if err := json.Unmarshal([]byte(params), &p); err != nil {
+		return err
+	}
+	if p.Uid == "" || len(p.TargetUids) == 0 {
+		return errors.New("缺少原标签或拆分后的标签")
+	}
+	return nil
+}
+
+func (h *tagSplitHandler) TotalSteps() int64 {
+	return 1
+}
+
+func (h *tagSplitHandler) Handle(ctx context.Context, tid string, params string) error {
+	var p TagSplitParams
+	if err := json.Unmarshal([]byte(params), &p); err != nil {
+		return err
+	}
+	targets, err := h.svc.TagsModel.GetUids(ctx, p.TargetUids)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return errors.New("拆分后的标签不存在")
+	}
+	resources, err := h.svc.ResourceModel.GetByTag(ctx, p.Uid)
+	if err != nil {
+		return err
+	}
+	assignments, err := tagging.PlanSplitByLLM(ctx, resources, targets)
+	if err != nil {
+		return err
+	}
+	result, err := h.svc.TagsModel.Split(ctx, p.Uid, assignments, p.RemoveSource)
+	if err != nil {
+		return err
+	}
+	logx.Infof("tag split tid: %s, uid: %s, assigned: %d, remaining: %d, source deleted: %v",
+		tid, p.Uid, result.Assigned, result.Remaining, result.SourceDeleted)
+	return nil
+}
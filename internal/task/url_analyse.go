@@ -0,0 +1,42 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/XXueTu/wise/internal/svc"
+	"github.com/XXueTu/wise/pkg/agent/url_analyse.go"
+)
+
+// TypeUrlAnalyse 解析URL任务
+const TypeUrlAnalyse = "URL_ANALYSE"
+
+func init() {
+	Register(TypeUrlAnalyse, func(*svc.ServiceContext) TaskHandler {
+		return urlAnalyseHandler{}
+	})
+}
+
+// urlAnalyseHandler 解析URL, 参数为链接
+type urlAnalyseHandler struct{}
+
+func (urlAnalyseHandler) Validate(params string) error {
+	u, err := url.ParseRequestURI(strings.TrimSpace(params))
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("仅支持 http/https 链接")
+	}
+	return nil
+}
+
+func (urlAnalyseHandler) TotalSteps() int64 {
+	return url_analyse.TotalSteps()
+}
+
+func (urlAnalyseHandler) Handle(ctx context.Context, tid string, params string) error {
+	return url_analyse.RunUrlAnalyseAgent(ctx, tid, params)
+}
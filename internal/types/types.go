@@ -3,6 +3,21 @@
 
 package types
 
+type ApplyTagRuleRequest struct {
+	Uids []string `json:"uids,optional"` // 执行的规则, 为空时执行全部启用的规则
+}
+
+type ApplyTagRuleResponse struct {
+	Tid string `json:"tid"` // 任务唯一标识
+}
+
+type Backup struct {
+	Name      string `json:"name"`       // 快照文件名
+	Size      int64  `json:"size"`       // 文件大小
+	Checksum  string `json:"checksum"`   // sha256
+	CreatedAt string `json:"created_at"` // 创建时间
+}
+
 type BaseRequest struct {
 	Id   string `json:"id"`   // 主键
 	Name string `json:"name"` // 名称
@@ -12,6 +27,40 @@ type BaseResponse struct {
 	Result string `json:"result"` // 结果
 }
 
+type Batch struct {
+	Bid       string `json:"bid"`        // 批次唯一标识
+	Name      string `json:"name"`       // 批次名称
+	Source    string `json:"source"`     // 来源
+	Total     int64  `json:"total"`      // 链接总数
+	CreatedAt string `json:"created_at"` // 创建时间
+}
+
+type BatchItem struct {
+	Url    string `json:"url"`             // 链接
+	Title  string `json:"title"`           // 导入时的标题
+	Tid    string `json:"tid"`             // 任务唯一标识
+	Status string `json:"status"`          // 状态 queued,running,succeeded,failed,duplicate
+	Error  string `json:"error,omitempty"` // 失败或重复原因
+}
+
+type BatchProgress struct {
+	Queued    int64 `json:"queued"`    // 排队中
+	Running   int64 `json:"running"`   // 执行中
+	Succeeded int64 `json:"succeeded"` // 成功
+	Failed    int64 `json:"failed"`    // 失败
+	Duplicate int64 `json:"duplicate"` // 重复
+}
+
+type BatchResponse struct {
+	Bid       string        `json:"bid"`        // 批次唯一标识
+	Name      string        `json:"name"`       // 批次名称
+	Source    string        `json:"source"`     // 来源 text,txt,csv,html,identify
+	Total     int64         `json:"total"`      // 链接总数
+	Progress  BatchProgress `json:"progress"`   // 聚合进度
+	Items     []BatchItem   `json:"items"`      // 明细
+	CreatedAt string        `json:"created_at"` // 创建时间
+}
+
 type CancelTaskRequest struct {
 	Tid string `json:"tid"` // 任务唯一标识
 }
@@ -20,6 +69,26 @@ type CreateAiResourceRequest struct {
 	URL string `json:"url"` // URL链接
 }
 
+type CreateBackupRequest struct {
+}
+
+type CreateBatchRequest struct {
+	Name   string `json:"name,optional"`    // 批次名称
+	Urls   string `json:"urls"`             // 链接列表, 换行或逗号分隔
+	DryRun bool   `json:"dry_run,optional"` // 仅预览, 不创建任务和标签
+}
+
+type CreateBatchResponse struct {
+	Bid       string      `json:"bid"`             // 批次唯一标识, 预览时为空
+	Total     int64       `json:"total"`           // 链接总数
+	Queued    int64       `json:"queued"`          // 已创建任务数, 预览时为将创建的任务数
+	Duplicate int64       `json:"duplicate"`       // 重复链接数
+	Invalid   int64       `json:"invalid"`         // 无效链接数
+	DryRun    bool        `json:"dry_run"`         // 是否为预览
+	NewTags   []string    `json:"new_tags"`        // 预览时返回需要新建的标签, 由文件夹和标签转换
+	Items     []BatchItem `json:"items,omitempty"` // 预览明细
+}
+
 type CreateBatchTagRequest struct {
 	Tags []CreateTagRequest `json:"tags"`
 }
@@ -30,6 +99,10 @@ type CreateBatchTagResponse struct {
 	CreateTagResponses []CreateTagResponse `json:"create_tag_responses"` // 结果
 }
 
+type CreateExportRequest struct {
+	Format string `json:"format,default=json,options=json|markdown"` // 导出格式, json 为版本化归档, markdown 为 Obsidian 兼容的压缩包
+}
+
 type CreateModelRequest struct {
 	BaseUrl       string   `json:"base_url"`        // 基础URL
 	Config        string   `json:"config"`          // 配置信息
@@ -48,11 +121,21 @@ type CreateResourceRequest struct {
 	TagUids []string `json:"tag_uids"` // 标签ID
 }
 
+type CreateTagAliasRequest struct {
+	TagUid  string   `json:"tag_uid"` // 标签唯一标识
+	Aliases []string `json:"aliases"` // 别名, 规范化后不能与已有标签的名称或别名相同
+}
+
+type CreateTagAliasResponse struct {
+	List []TagAlias `json:"list"` // 新增的别名
+}
+
 type CreateTagRequest struct {
-	Name        string `json:"name"`        // 标签名称
-	Description string `json:"description"` // 标签描述
-	Color       string `json:"color"`       // 标签颜色
-	Icon        string `json:"icon"`        // 标签图标
+	Name        string `json:"name"`                // 标签名称
+	Description string `json:"description"`         // 标签描述
+	Color       string `json:"color"`               // 标签颜色
+	Icon        string `json:"icon"`                // 标签图标
+	ParentUid   string `json:"parent_uid,optional"` // 父标签唯一标识（可选）
 }
 
 type CreateTagResponse struct {
@@ -62,16 +145,27 @@ type CreateTagResponse struct {
 	Description string `json:"description"` // 标签描述
 	Color       string `json:"color"`       // 标签颜色
 	Icon        string `json:"icon"`        // 标签图标
+	ParentUid   string `json:"parent_uid"`  // 父标签唯一标识
 	CreatedAt   string `json:"created_at"`  // 创建时间
 	UpdatedAt   string `json:"updated_at"`  // 更新时间
 }
 
+type CreateTagRuleRequest struct {
+	Name     string `json:"name"`                                                      // 规则名称
+	Type     string `json:"type,options=domain|url_regex|keyword|resource_type|label"` // 匹配方式
+	Field    string `json:"field,optional"`                                            // keyword 匹配的字段 title,content, 为空时都匹配
+	Pattern  string `json:"pattern"`                                                   // 域名、正则、关键词、资源类型或原始标签
+	Action   string `json:"action,default=add,options=add|remove"`                     // 添加或移除标签
+	TagUid   string `json:"tag_uid"`                                                   // 标签唯一标识
+	Priority int64  `json:"priority,optional"`                                         // 优先级
+	Enabled  bool   `json:"enabled,default=true"`                                      // 是否启用
+}
+
 type CreateTaskRequest struct {
-	Name         string `json:"name"`          // 任务名称
-	Types        string `json:"types"`         // 任务类型
-	Params       string `json:"params"`        // 任务参数
-	TotalSteps   int64  `json:"total_steps"`   // 总步骤
-	CurrentState string `json:"current_state"` // 当前状态机
+	Name     string `json:"name"`               // 任务名称
+	Types    string `json:"types"`              // 任务类型, 需已注册
+	Params   string `json:"params"`             // 任务参数, 按任务类型校验
+	Priority int64  `json:"priority,default=5"` // 优先级 0 低 5 普通 10 高
 }
 
 type CreateTaskResponse struct {
@@ -101,11 +195,34 @@ type DeleteResourceRequest struct {
 	Id int64 `form:"id"` // 主键
 }
 
+type DeleteTagAliasRequest struct {
+	Id int64 `json:"id"` // 别名ID
+}
+
+type DeleteTagAliasResponse struct {
+	Result string `json:"result"` // 结果
+}
+
 type DeleteTagRequest struct {
-	Uid string `json:"uid"` // 标签唯一标识
+	Uid       string `json:"uid"`                                                       // 标签唯一标识
+	Strategy  string `json:"strategy,default=forbid,options=detach|reassign_to|forbid"` // 已关联资源的处理方式: detach 解除关联, reassign_to 改为关联目标标签, forbid 有资源时不删除
+	TargetUid string `json:"target_uid,optional"`                                       // reassign_to 的目标标签
+	DryRun    bool   `json:"dry_run,optional"`                                          // 只返回引用情况, 不删除
 }
 
 type DeleteTagResponse struct {
+	Result    string `json:"result"`    // 结果
+	Resources int64  `json:"resources"` // 关联的资源数
+	Children  int64  `json:"children"`  // 子标签数, 删除后移到父标签下
+	Rules     int64  `json:"rules"`     // 引用标签的规则数
+	Deleted   bool   `json:"deleted"`   // 是否已删除
+}
+
+type DeleteTagRuleRequest struct {
+	Uid string `json:"uid"` // 规则唯一标识
+}
+
+type DeleteTagRuleResponse struct {
 	Result string `json:"result"` // 结果
 }
 
@@ -117,6 +234,39 @@ type DeleteTaskResponse struct {
 	Result string `json:"result"` // 结果
 }
 
+type DryRunTagRuleRequest struct {
+	Uid   string               `json:"uid,optional"`     // 已保存的规则, 同时传入 rule 时试运行修改后的规则
+	Rule  CreateTagRuleRequest `json:"rule,optional"`    // 未保存或修改后的规则
+	Limit int64                `json:"limit,default=20"` // 返回的资源数
+}
+
+type DryRunTagRuleResponse struct {
+	Total   int64           `json:"total"`   // 结果与当前启用的规则不同的资源数
+	Added   int64           `json:"added"`   // 比当前多添加标签的资源数
+	Removed int64           `json:"removed"` // 比当前多移除标签的资源数
+	List    []TagRuleEffect `json:"list"`    // 结果不同的资源, 最多 limit 个
+}
+
+type ExportResponse struct {
+	Tid    string `json:"tid"`    // 导出任务唯一标识, 通过任务接口查询进度
+	Format string `json:"format"` // 导出格式
+	Status string `json:"status"` // 任务状态
+}
+
+type GetBackupRequest struct {
+	Name string `path:"name"` // 快照文件名
+}
+
+type GetBatchRequest struct {
+	Id       string `path:"id"`                    // 批次唯一标识
+	Page     int64  `form:"page,default=1"`        // 明细页码
+	PageSize int64  `form:"page_size,default=100"` // 明细每页数量
+}
+
+type GetExportRequest struct {
+	Tid string `path:"tid"` // 导出任务唯一标识
+}
+
 type GetModelRequest struct {
 	Id int64 `form:"id"` // 主键
 }
@@ -142,7 +292,43 @@ type IdentifyResourceRequest struct {
 }
 
 type IdentifyResourceResponse struct {
-	Urls []string `json:"urls"` // 任务ID列表
+	Urls    []string `json:"urls"`               // 任务ID列表
+	BatchId string   `json:"batch_id,omitempty"` // 多个链接时创建的批次唯一标识
+}
+
+type ImportArchiveRequest struct {
+}
+
+type ImportArchiveResponse struct {
+	TagsCreated         int64 `json:"tags_created"`          // 新建标签数
+	TagsUpdated         int64 `json:"tags_updated"`          // 更新标签数
+	ParentsSkipped      int64 `json:"parents_skipped"`       // 父标签不存在或形成环而未设置的标签数
+	AliasesCreated      int64 `json:"aliases_created"`       // 新建别名数, 与已有别名重复的跳过
+	ResourcesCreated    int64 `json:"resources_created"`     // 新建资源数
+	ResourcesUpdated    int64 `json:"resources_updated"`     // 更新资源数
+	ResourceTagsSkipped int64 `json:"resource_tags_skipped"` // 标签不存在而跳过的资源标签数
+	RulesCreated        int64 `json:"rules_created"`         // 新建规则数
+	RulesUpdated        int64 `json:"rules_updated"`         // 更新规则数
+	RulesSkipped        int64 `json:"rules_skipped"`         // 标签不存在而跳过的规则数
+	ModelsCreated       int64 `json:"models_created"`        // 新建模型数
+	ModelsSkipped       int64 `json:"models_skipped"`        // 已存在跳过的模型数
+}
+
+type ListBackupRequest struct {
+}
+
+type ListBackupResponse struct {
+	List []Backup `json:"list"` // 快照列表, 按创建时间倒序
+}
+
+type ListBatchRequest struct {
+	Page     int64 `form:"page,default=1"`       // 页码
+	PageSize int64 `form:"page_size,default=10"` // 每页数量
+}
+
+type ListBatchResponse struct {
+	Total int64   `json:"total"` // 总记录数
+	List  []Batch `json:"list"`  // 批次列表
 }
 
 type ListModelRequest struct {
@@ -160,11 +346,15 @@ type ListModelResponse struct {
 }
 
 type ListResourceRequest struct {
-	Page     int64    `json:"page"`              // 页码
-	PageSize int64    `json:"page_size"`         // 每页数量
-	Type     string   `json:"type,optional"`     // 类型（可选）
-	TagUids  []string `json:"tag_uids,optional"` // 标签（可选）
-	Keyword  string   `json:"keyword,optional"`  // 关键词（可选）
+	Page           int64    `json:"page"`                                                                         // 页码
+	PageSize       int64    `json:"page_size"`                                                                    // 每页数量
+	Type           string   `json:"type,optional"`                                                                // 类型（可选）
+	TagUids        []string `json:"tag_uids,optional"`                                                            // 包含全部标签（可选）
+	AnyTagUids     []string `json:"any_tag_uids,optional"`                                                        // 包含任一标签（可选）
+	ExcludeTagUids []string `json:"exclude_tag_uids,optional"`                                                    // 不包含这些标签（可选）
+	Keyword        string   `json:"keyword,optional"`                                                             // 标题或提取的关键词（可选）
+	Keywords       []string `json:"keywords,optional"`                                                            // 包含全部提取的关键词（可选）
+	KeywordType    string   `json:"keyword_type,optional,options=keyword|person|product|organization|technology"` // 关键词类型（可选）
 }
 
 type ListResourceResponse struct {
@@ -172,15 +362,32 @@ type ListResourceResponse struct {
 	Resources []Resource `json:"resources"` // 资源列表
 }
 
+type ListTagAliasRequest struct {
+	TagUid string `form:"tag_uid,optional"` // 标签唯一标识, 为空时返回全部别名
+}
+
+type ListTagAliasResponse struct {
+	List []TagAlias `json:"list"` // 别名列表
+}
+
 type ListTagRequest struct {
 	Page     int64  `form:"page,default=1"`       // 页码
 	PageSize int64  `form:"page_size,default=10"` // 每页数量
 	Name     string `form:"name,optional"`        // 标签名称（模糊查询）
+	Tree     bool   `form:"tree,optional"`        // 是否返回树形结构, 返回全部标签, 不分页
 }
 
 type ListTagResponse struct {
-	Total int64         `json:"total"` // 总数
-	List  []TagResponse `json:"list"`  // 标签列表
+	Total int64         `json:"total"`          // 总数
+	List  []TagResponse `json:"list"`           // 标签列表
+	Tree  []TagResponse `json:"tree,omitempty"` // 标签树, 只返回根标签, 子标签在 children 中
+}
+
+type ListTagRuleRequest struct {
+}
+
+type ListTagRuleResponse struct {
+	List []TagRule `json:"list"` // 规则列表, 按执行顺序排序
 }
 
 type ListTaskRequest struct {
@@ -196,6 +403,18 @@ type ListTaskResponse struct {
 	List  []TaskResponse `json:"list"`  // 任务列表
 }
 
+type MergeTagRequest struct {
+	TargetUid  string   `json:"target_uid"`  // 保留的标签
+	SourceUids []string `json:"source_uids"` // 合并到目标标签的标签, 合并后删除, 名称作为目标标签的别名
+}
+
+type MergeTagResponse struct {
+	Target    TagResponse `json:"target"`    // 目标标签
+	Merged    int64       `json:"merged"`    // 合并的标签数
+	Resources int64       `json:"resources"` // 改为目标标签的资源数
+	Aliases   []string    `json:"aliases"`   // 目标标签的全部别名
+}
+
 type Model struct {
 	Id            int64    `json:"id"`              // 主键
 	BaseUrl       string   `json:"base_url"`        // 基础URL
@@ -213,35 +432,228 @@ type PauseTaskRequest struct {
 	Tid string `json:"tid"` // 任务唯一标识
 }
 
+type ProposeTagAliasRequest struct {
+	Limit int64 `form:"limit,default=50"` // 返回的分组数, 按分组的资源总数从多到少
+}
+
+type ProposeTagAliasResponse struct {
+	Total int64           `json:"total"` // 分组总数
+	List  []TagAliasGroup `json:"list"`  // 建议合并的分组
+}
+
 type Resource struct {
-	Id        int64    `json:"id"`         // 主键
-	URL       string   `json:"url"`        // URL链接
-	Title     string   `json:"title"`      // 标题
-	Describe  string   `json:"describe"`   // 描述
-	Content   string   `json:"content"`    // 内容
-	Type      string   `json:"type"`       // 类型
-	Tags      []string `json:"tags"`       // 标签
-	TagUids   []string `json:"tag_uids"`   // 标签ID
-	CreatedAt string   `json:"created_at"` // 创建时间
-	UpdatedAt string   `json:"updated_at"` // 更新时间
+	Id        int64             `json:"id"`         // 主键
+	URL       string            `json:"url"`        // URL链接
+	Title     string            `json:"title"`      // 标题
+	Describe  string            `json:"describe"`   // 描述
+	Content   string            `json:"content"`    // 内容
+	Type      string            `json:"type"`       // 类型
+	Tags      []string          `json:"tags"`       // 标签
+	TagUids   []string          `json:"tag_uids"`   // 标签ID
+	Keywords  []ResourceKeyword `json:"keywords"`   // 提取的关键词
+	CreatedAt string            `json:"created_at"` // 创建时间
+	UpdatedAt string            `json:"updated_at"` // 更新时间
+}
+
+type RequeueTaskRequest struct {
+	Tid string `json:"tid"` // 任务唯一标识
+}
+
+type ResourceKeyword struct {
+	Keyword string  `json:"keyword"` // 关键词
+	Type    string  `json:"type"`    // 类型: keyword, person, product, organization, technology
+	Weight  float64 `json:"weight"`  // 权重, 0 到 1
+}
+
+type RestoreBackupRequest struct {
+	Name string `path:"name"` // 快照文件名
+}
+
+type RestoreBackupResponse struct {
+	Restored  string `json:"restored"`   // 已恢复的快照
+	PreBackup string `json:"pre_backup"` // 恢复前自动备份的快照, 可用于回退
 }
 
 type ResumeTaskRequest struct {
 	Tid string `json:"tid"` // 任务唯一标识
 }
 
+type RetryBatchRequest struct {
+	Id string `path:"id"` // 批次唯一标识
+}
+
+type RetryBatchResponse struct {
+	Retried int64 `json:"retried"` // 重新入队的任务数
+}
+
 type RetryTaskRequest struct {
 	Tid string `json:"tid"` // 任务唯一标识
 }
 
+type SplitTagRequest struct {
+	Uid        string           `json:"uid"`                                  // 拆分的标签
+	Mode       string           `json:"mode,default=rules,options=rules|llm"` // 分配方式, rules 按规则立即执行, llm 创建任务由大模型重新分类
+	Targets    []SplitTagTarget `json:"targets"`                              // 拆分后的标签
+	KeepSource bool             `json:"keep_source,optional"`                 // 保留原标签, 新建的标签作为其子标签, 否则原标签没有资源时删除
+}
+
+type SplitTagResponse struct {
+	Mode          string        `json:"mode"`           // 分配方式
+	Targets       []TagResponse `json:"targets"`        // 拆分后的标签
+	Tid           string        `json:"tid,omitempty"`  // llm 模式的任务唯一标识
+	Assigned      int64         `json:"assigned"`       // 改为新标签的资源数
+	Remaining     int64         `json:"remaining"`      // 仍使用原标签的资源数
+	SourceDeleted bool          `json:"source_deleted"` // 原标签是否已删除
+}
+
+type SplitTagTarget struct {
+	Uid         string   `json:"uid,optional"`          // 已有标签, 为空时按名称查找, 不存在时创建
+	Name        string   `json:"name,optional"`         // 标签名称
+	ResourceIds []int64  `json:"resource_ids,optional"` // 分配到该标签的资源, rules 模式使用
+	Keywords    []string `json:"keywords,optional"`     // 标题或描述包含任一关键词的资源分配到该标签, rules 模式使用
+}
+
+type TagAlias struct {
+	Id        int64  `json:"id"`         // 主键ID
+	Alias     string `json:"alias"`      // 别名
+	TagUid    string `json:"tag_uid"`    // 标签唯一标识
+	TagName   string `json:"tag_name"`   // 标签名称
+	CreatedAt string `json:"created_at"` // 创建时间
+}
+
+type TagAliasGroup struct {
+	Target  TagUsage         `json:"target"`  // 保留的标签
+	Members []TagAliasMember `json:"members"` // 建议合并到保留标签的标签
+}
+
+type TagAliasMember struct {
+	Uid        string  `json:"uid"`        // 标签唯一标识
+	Name       string  `json:"name"`       // 标签名称
+	Color      string  `json:"color"`      // 标签颜色
+	Count      int64   `json:"count"`      // 关联的资源数
+	Method     string  `json:"method"`     // 归入分组的方式: exact 规范化后名称相同, alias 名称是别名, synonym 同义词, embedding 名称向量相近
+	Similarity float64 `json:"similarity"` // 名称向量相似度, 只有 embedding 方式有值
+}
+
+type TagCloudItem struct {
+	Uid    string  `json:"uid"`    // 标签唯一标识
+	Name   string  `json:"name"`   // 标签名称
+	Color  string  `json:"color"`  // 标签颜色
+	Icon   string  `json:"icon"`   // 标签图标
+	Count  int64   `json:"count"`  // 关联的资源数
+	Weight float64 `json:"weight"` // 权重 0 到 1, 按使用次数的对数归一化
+}
+
+type TagCloudRequest struct {
+	Limit int64 `form:"limit,default=100"` // 返回的标签数, 按使用次数从多到少
+}
+
+type TagCloudResponse struct {
+	List []TagCloudItem `json:"list"` // 有资源的标签
+}
+
+type TagGrowth struct {
+	Period string     `json:"period"` // 日期, 如 2006-01-02 或 2006-01
+	Count  int64      `json:"count"`  // 新增关联数
+	Tags   []TagUsage `json:"tags"`   // 当期新增最多的标签
+}
+
+type TagPair struct {
+	Uid       string `json:"uid"`        // 标签唯一标识
+	Name      string `json:"name"`       // 标签名称
+	OtherUid  string `json:"other_uid"`  // 另一个标签唯一标识
+	OtherName string `json:"other_name"` // 另一个标签名称
+	Count     int64  `json:"count"`      // 同时关联的资源数
+}
+
 type TagResponse struct {
-	Uid         string `json:"uid"`         // 标签唯一标识
-	Name        string `json:"name"`        // 标签名称
-	Description string `json:"description"` // 标签描述
-	Color       string `json:"color"`       // 标签颜色
-	Icon        string `json:"icon"`        // 标签图标
-	CreatedAt   string `json:"created_at"`  // 创建时间
-	UpdatedAt   string `json:"updated_at"`  // 更新时间
+	Uid         string        `json:"uid"`                 // 标签唯一标识
+	Name        string        `json:"name"`                // 标签名称
+	Description string        `json:"description"`         // 标签描述
+	Color       string        `json:"color"`               // 标签颜色
+	Icon        string        `json:"icon"`                // 标签图标
+	ParentUid   string        `json:"parent_uid"`          // 父标签唯一标识
+	Ancestors   []TagResponse `json:"ancestors,omitempty"` // 祖先标签, 从根标签开始, 获取标签详情时返回
+	Children    []TagResponse `json:"children,omitempty"`  // 子标签, 树形结构时返回
+	CreatedAt   string        `json:"created_at"`          // 创建时间
+	UpdatedAt   string        `json:"updated_at"`          // 更新时间
+}
+
+type TagRule struct {
+	Uid       string `json:"uid"`        // 规则唯一标识
+	Name      string `json:"name"`       // 规则名称
+	Type      string `json:"type"`       // 匹配方式 domain,url_regex,keyword,resource_type,label
+	Field     string `json:"field"`      // keyword 匹配的字段 title,content, 为空时都匹配
+	Pattern   string `json:"pattern"`    // 匹配内容
+	Action    string `json:"action"`     // add 添加标签, remove 移除标签
+	TagUid    string `json:"tag_uid"`    // 标签唯一标识
+	TagName   string `json:"tag_name"`   // 标签名称
+	Priority  int64  `json:"priority"`   // 优先级, 数值小的先执行, 同一个标签以最后匹配的规则为准
+	Enabled   bool   `json:"enabled"`    // 是否启用
+	CreatedAt string `json:"created_at"` // 创建时间
+	UpdatedAt string `json:"updated_at"` // 更新时间
+}
+
+type TagRuleEffect struct {
+	ResourceId int64    `json:"resource_id"` // 资源 id
+	Title      string   `json:"title"`       // 资源标题
+	URL        string   `json:"url"`         // 资源URL
+	Add        []string `json:"add"`         // 比当前多添加的标签唯一标识
+	Remove     []string `json:"remove"`      // 比当前多移除的标签唯一标识
+}
+
+type TagStatsRequest struct {
+	Start  string `form:"start,optional"`                       // 开始时间 2006-01-02 15:04:05, 默认 30 天前, 只用于增长统计
+	End    string `form:"end,optional"`                         // 结束时间 2006-01-02 15:04:05, 默认当前时间
+	Period string `form:"period,default=day,options=day|month"` // 增长统计的时间粒度, 按 UTC 日期分组
+	Top    int64  `form:"top,default=10"`                       // 返回使用最多的标签数和标签对数
+}
+
+type TagStatsResponse struct {
+	Start      string        `json:"start"`       // 开始时间
+	End        string        `json:"end"`         // 结束时间
+	Period     string        `json:"period"`      // 时间粒度
+	TotalTags  int64         `json:"total_tags"`  // 标签总数
+	UsedTags   int64         `json:"used_tags"`   // 有资源的标签数
+	TotalLinks int64         `json:"total_links"` // 资源与标签的关联总数
+	Top        []TagUsage    `json:"top"`         // 使用最多的标签
+	Growth     []TagGrowth   `json:"growth"`      // 按时间统计的新增关联
+	Pairs      []TagPair     `json:"pairs"`       // 同时出现最多的标签对
+	Orphans    []TagResponse `json:"orphans"`     // 自身和子孙标签都没有资源的标签, 不包含默认标签
+}
+
+type TagUsage struct {
+	Uid   string `json:"uid"`   // 标签唯一标识
+	Name  string `json:"name"`  // 标签名称
+	Color string `json:"color"` // 标签颜色
+	Count int64  `json:"count"` // 关联的资源数
+}
+
+type TaskEvent struct {
+	Tid    string `json:"tid"`             // 任务唯一标识
+	Type   string `json:"type"`            // 事件类型 task_status,plan_start,plan_end,plan_error
+	Status string `json:"status"`          // 任务或节点状态
+	Node   string `json:"node,omitempty"`  // 节点名称
+	Pid    string `json:"pid,omitempty"`   // 任务计划唯一标识
+	Step   int64  `json:"step"`            // 当前步骤
+	Error  string `json:"error,omitempty"` // 错误信息
+	Time   string `json:"time"`            // 事件时间
+}
+
+type TaskEventsRequest struct {
+	Tid string `form:"tid,optional"` // 任务唯一标识, 为空订阅全部任务
+}
+
+type TaskNodeStats struct {
+	Name             string  `json:"name"`              // 节点名称
+	Total            int64   `json:"total"`             // 执行次数
+	Success          int64   `json:"success"`           // 成功数
+	Failed           int64   `json:"failed"`            // 失败数
+	P50              int64   `json:"p50"`               // 耗时 p50 ms
+	P95              int64   `json:"p95"`               // 耗时 p95 ms
+	PromptTokens     int64   `json:"prompt_tokens"`     // 输入 token 数
+	CompletionTokens int64   `json:"completion_tokens"` // 输出 token 数
+	Cost             float64 `json:"cost"`              // 大模型成本
 }
 
 type TaskOperationResponse struct {
@@ -249,16 +661,27 @@ type TaskOperationResponse struct {
 }
 
 type TaskPlanDetail struct {
-	Pid       string `json:"pid"`        // 任务计划唯一标识
-	Name      string `json:"name"`       // 任务计划名称
-	Index     int64  `json:"index"`      // 任务计划索引
-	Status    string `json:"status"`     // 任务状态
-	Params    string `json:"params"`     // 任务参数
-	Result    string `json:"result"`     // 任务结果
-	Duration  int64  `json:"duration"`   // 任务耗时
-	Error     string `json:"error"`      // 任务错误
-	CreatedAt string `json:"created_at"` // 创建时间
-	UpdatedAt string `json:"updated_at"` // 更新时间
+	Pid              string `json:"pid"`               // 任务计划唯一标识
+	Name             string `json:"name"`              // 任务计划名称
+	BeforePid        string `json:"before_pid"`        // 上游任务计划唯一标识, 多个以逗号分隔
+	Next             string `json:"next"`              // 下游节点名称, 多个以逗号分隔
+	Index            int64  `json:"index"`             // 任务计划索引, 本次执行中的开始顺序
+	Status           string `json:"status"`            // 任务状态
+	Params           string `json:"params"`            // 任务参数
+	Result           string `json:"result"`            // 任务结果
+	Duration         int64  `json:"duration"`          // 任务耗时 ms
+	Error            string `json:"error"`             // 任务错误
+	StartedAt        string `json:"started_at"`        // 开始时间
+	EndedAt          string `json:"ended_at"`          // 结束时间
+	PromptTokens     int64  `json:"prompt_tokens"`     // 输入 token 数
+	CompletionTokens int64  `json:"completion_tokens"` // 输出 token 数
+	CreatedAt        string `json:"created_at"`        // 创建时间
+	UpdatedAt        string `json:"updated_at"`        // 更新时间
+}
+
+type TaskPlanEdge struct {
+	From string `json:"from"` // 上游任务计划唯一标识
+	To   string `json:"to"`   // 下游任务计划唯一标识
 }
 
 type TaskResponse struct {
@@ -275,10 +698,41 @@ type TaskResponse struct {
 	Duration     int64  `json:"duration"`      // 任务耗时
 	Error        string `json:"error"`         // 任务错误
 	Extend       string `json:"extend"`        // 扩展字段
+	NextRunAt    string `json:"next_run_at"`   // 下次执行时间
+	Priority     int64  `json:"priority"`      // 优先级
 	CreatedAt    string `json:"created_at"`    // 创建时间
 	UpdatedAt    string `json:"updated_at"`    // 更新时间
 }
 
+type TaskStatsRequest struct {
+	Start string `form:"start,optional"` // 开始时间 2006-01-02 15:04:05, 默认 7 天前
+	End   string `form:"end,optional"`   // 结束时间 2006-01-02 15:04:05, 默认当前时间
+}
+
+type TaskStatsResponse struct {
+	Start            string          `json:"start"`             // 开始时间
+	End              string          `json:"end"`               // 结束时间
+	Total            int64           `json:"total"`             // 任务总数
+	Success          int64           `json:"success"`           // 成功数
+	Failed           int64           `json:"failed"`            // 失败数, 包含死信
+	SuccessRate      float64         `json:"success_rate"`      // 成功率, 按已结束任务计算
+	PromptTokens     int64           `json:"prompt_tokens"`     // 输入 token 数
+	CompletionTokens int64           `json:"completion_tokens"` // 输出 token 数
+	Cost             float64         `json:"cost"`              // 大模型成本
+	Types            []TaskTypeStats `json:"types"`             // 按任务类型统计
+	Nodes            []TaskNodeStats `json:"nodes"`             // 按节点统计
+}
+
+type TaskTypeStats struct {
+	Types       string  `json:"types"`        // 任务类型
+	Total       int64   `json:"total"`        // 任务总数
+	Success     int64   `json:"success"`      // 成功数
+	Failed      int64   `json:"failed"`       // 失败数
+	SuccessRate float64 `json:"success_rate"` // 成功率
+	P50         int64   `json:"p50"`          // 耗时 p50 ms
+	P95         int64   `json:"p95"`          // 耗时 p95 ms
+}
+
 type TaskVisualizationResponse struct {
 	Tid          string           `json:"tid"`           // 任务唯一标识
 	Name         string           `json:"name"`          // 任务名称
@@ -287,7 +741,8 @@ type TaskVisualizationResponse struct {
 	CurrentState string           `json:"current_state"` // 当前状态机
 	TotalSteps   int64            `json:"total_steps"`   // 总步骤
 	CurrentStep  int64            `json:"current_step"`  // 当前步骤
-	Plans        []TaskPlanDetail `json:"plans"`         // 任务计划详情
+	Plans        []TaskPlanDetail `json:"plans"`         // 任务计划详情, 即执行图的节点
+	Edges        []TaskPlanEdge   `json:"edges"`         // 执行图的边
 	CreatedAt    string           `json:"created_at"`    // 创建时间
 	UpdatedAt    string           `json:"updated_at"`    // 更新时间
 }
@@ -324,11 +779,12 @@ type UpdateResourceRequest struct {
 }
 
 type UpdateTagRequest struct {
-	Uid         string `json:"uid,optional"` // 标签唯一标识
-	Name        string `json:"name"`         // 标签名称
-	Description string `json:"description"`  // 标签描述
-	Color       string `json:"color"`        // 标签颜色
-	Icon        string `json:"icon"`         // 标签图标
+	Uid         string `json:"uid,optional"`        // 标签唯一标识
+	Name        string `json:"name"`                // 标签名称
+	Description string `json:"description"`         // 标签描述
+	Color       string `json:"color"`               // 标签颜色
+	Icon        string `json:"icon"`                // 标签图标
+	ParentUid   string `json:"parent_uid,optional"` // 父标签唯一标识, 为空时作为根标签
 }
 
 type UpdateTagResponse struct {
@@ -338,10 +794,23 @@ type UpdateTagResponse struct {
 	Description string `json:"description"` // 标签描述
 	Color       string `json:"color"`       // 标签颜色
 	Icon        string `json:"icon"`        // 标签图标
+	ParentUid   string `json:"parent_uid"`  // 父标签唯一标识
 	CreatedAt   string `json:"created_at"`  // 创建时间
 	UpdatedAt   string `json:"updated_at"`  // 更新时间
 }
 
+type UpdateTagRuleRequest struct {
+	Uid      string `json:"uid"`                                                       // 规则唯一标识
+	Name     string `json:"name"`                                                      // 规则名称
+	Type     string `json:"type,options=domain|url_regex|keyword|resource_type|label"` // 匹配方式
+	Field    string `json:"field,optional"`                                            // keyword 匹配的字段 title,content, 为空时都匹配
+	Pattern  string `json:"pattern"`                                                   // 域名、正则、关键词、资源类型或原始标签
+	Action   string `json:"action,default=add,options=add|remove"`                     // 添加或移除标签
+	TagUid   string `json:"tag_uid"`                                                   // 标签唯一标识
+	Priority int64  `json:"priority,optional"`                                         // 优先级
+	Enabled  bool   `json:"enabled,default=true"`                                      // 是否启用
+}
+
 type UpdateTaskRequest struct {
 	Tid          string `json:"tid"`                    // 任务唯一标识
 	Name         string `json:"name,optional"`          // 任务名称
@@ -372,3 +841,8 @@ type UpdateTaskResponse struct {
 	CreatedAt    string `json:"created_at"`    // 创建时间
 	UpdatedAt    string `json:"updated_at"`    // 更新时间
 }
+
+type UploadBatchRequest struct {
+	Name   string `form:"name,optional"`    // 批次名称, 文件通过 file 字段上传, 支持 txt、Pocket/Raindrop csv、浏览器书签 html
+	DryRun bool   `form:"dry_run,optional"` // 仅预览, 不创建任务和标签
+}
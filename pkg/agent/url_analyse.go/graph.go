@@ -3,11 +3,13 @@ package url_analyse
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/compose"
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/XXueTu/wise/internal/event"
 	"github.com/XXueTu/wise/internal/model"
 	"github.com/XXueTu/wise/internal/svc"
 )
@@ -26,46 +28,56 @@ type UrlAnalyseStep struct {
 }
 
 var UrlAnalyseSteps = map[string]UrlAnalyseStep{
-	"start":  {Step: 0},
-	"check":  {Step: 1},
-	"read":   {Step: 2},
-	"split":  {Step: 3},
-	"mark":   {Step: 4},
-	"vector": {Step: 5},
-	"index":  {Step: 6},
+	"start":   {Step: 0},
+	"check":   {Step: 1},
+	"read":    {Step: 2},
+	"split":   {Step: 3},
+	"mark":    {Step: 4},
+	"rule":    {Step: 5},
+	"keyword": {Step: 6},
 }
 
 const (
-	nodeOfCheck  = "check"
-	nodeOfRead   = "read"
-	nodeOfSplit  = "split"
-	nodeOfMark   = "mark"
-	nodeOfVector = "vector"
-	nodeOfIndex  = "index"
+	nodeOfStart   = "start" // 整图
+	nodeOfCheck   = "check"
+	nodeOfRead    = "read"
+	nodeOfSplit   = "split"
+	nodeOfMark    = "mark"
+	nodeOfRule    = "rule"
+	nodeOfKeyword = "keyword"
 )
 
+// GraphPlanName 整图的计划名称, 其 token 消耗为全部节点之和
+const GraphPlanName = nodeOfStart
+
+// TotalSteps 工作流节点数, 整图不计入步骤
+func TotalSteps() int64 {
+	return int64(len(UrlAnalyseSteps) - 1)
+}
+
 func BuildAnalysisGraph(svct *svc.ServiceContext) error {
 	logx.Infof("build analysis graph")
 	svcCtx = svct
 	ctx := context.Background()
 	wf := compose.NewWorkflow[map[string]any, any]()
-	wf.AddLambdaNode(nodeOfCheck,
-		compose.InvokableLambda(CheckNodeHandler), compose.WithNodeName(nodeOfCheck)).AddInput(compose.START)
-
-	wf.AddLambdaNode(nodeOfRead,
-		compose.InvokableLambda(ReadNodeHandler), compose.WithNodeName(nodeOfRead)).
-		AddInput(nodeOfCheck)
-
-	wf.AddLambdaNode(nodeOfSplit,
-		compose.InvokableLambda(SplitNodeHandler), compose.WithNodeName(nodeOfSplit)).
-		AddInput(nodeOfRead)
-
-	wf.AddLambdaNode(nodeOfMark,
-		compose.InvokableLambda(MarkNodeHandler), compose.WithNodeName(nodeOfMark)).
-		AddInput(nodeOfSplit)
-
-	wf.End().AddInput(nodeOfMark)
-	runnable, err := wf.Compile(ctx)
+	nodeInputs = map[string][]string{}
+	addLambdaNode := func(name string, handler func(context.Context, map[string]any) (map[string]any, error), inputs ...string) {
+		node := wf.AddLambdaNode(name, compose.InvokableLambda(handler), compose.WithNodeName(name))
+		for _, input := range inputs {
+			node.AddInput(input)
+		}
+		nodeInputs[name] = inputs
+	}
+	addLambdaNode(nodeOfCheck, CheckNodeHandler, compose.START)
+	addLambdaNode(nodeOfRead, ReadNodeHandler, nodeOfCheck)
+	addLambdaNode(nodeOfSplit, SplitNodeHandler, nodeOfRead)
+	addLambdaNode(nodeOfMark, MarkNodeHandler, nodeOfSplit)
+	addLambdaNode(nodeOfRule, RuleNodeHandler, nodeOfMark)
+	addLambdaNode(nodeOfKeyword, KeywordNodeHandler, nodeOfRule)
+
+	wf.End().AddInput(nodeOfKeyword)
+	nodeInputs[compose.END] = []string{nodeOfKeyword}
+	runnable, err := wf.Compile(ctx, compose.WithGraphName(nodeOfStart))
 	if err != nil {
 		return err
 	}
@@ -73,31 +85,46 @@ func BuildAnalysisGraph(svct *svc.ServiceContext) error {
 	traceHandler.OnStartFn(
 		func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
 			logx.Infof("onStart, runInfo: %v, input: %v", info, input)
-			value := ctx.Value(urlAnalyseContextKey).(map[string]string)
-			tid := value["tid"]
+			trace := ctx.Value(urlAnalyseContextKey).(*planTrace)
+			tid := trace.tid
 			pid := model.GenUid()
 			name := tenl(info)
 			if name == "" {
 				name = pid
 			}
+			// 上下文中的计划为父计划, 即整图的计划
+			parent, _ := ctx.Value(TraceId).(string)
+			beforePid := trace.beforePid(name, parent)
+			index := trace.start(name, pid)
 			ctx = context.WithValue(ctx, TraceId, pid)
-			// 创建task_plans
+			ctx = withTokenUsage(ctx)
+			// 创建task_plans, 节点已开始执行, 记录为执行中, 与推送的事件一致
 			jsonInput, _ := json.Marshal(input)
-			_ = svcCtx.TaskPlansModel.Create(ctx, &model.TaskPlans{
+			plan := &model.TaskPlans{
 				Tid:       tid,
 				Pid:       pid,
-				BeforePid: "",
-				Next:      "",
+				BeforePid: beforePid,
+				Next:      nextNodes(name),
 				Types:     info.Type,
 				Name:      name,
-				Index:     0,
-				Status:    model.TaskPlanStatusInit,
+				Index:     index,
+				Status:    model.TaskPlanStatusRunning,
 				Params:    string(jsonInput),
 				Result:    "{}",
 				Duration:  0,
-			})
+				StartedAt: time.Now(),
+			}
+			_ = svcCtx.TaskPlansModel.Create(ctx, plan)
 			// 更新 task 当前步骤
-			_ = svcCtx.TasksModel.UpdateStateAndStep(ctx, tid, name, UrlAnalyseSteps[name].Step, "{}")
+			_ = svcCtx.TasksModel.UpdateStateAndStep(ctx, tid, model.LeaseOwner(ctx), name, UrlAnalyseSteps[name].Step, "{}")
+			svcCtx.TaskEvents.Publish(event.TaskEvent{
+				Tid:    tid,
+				Type:   event.TaskEventPlanStart,
+				Status: plan.Status,
+				Node:   name,
+				Pid:    pid,
+				Step:   UrlAnalyseSteps[name].Step,
+			})
 			return ctx
 		}).OnEndFn(
 		func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
@@ -107,7 +134,16 @@ func BuildAnalysisGraph(svct *svc.ServiceContext) error {
 			jsonOutput, _ := json.Marshal(output)
 			taskPlan.Result = string(jsonOutput)
 			taskPlan.Status = model.TaskPlanStatusSuccess
+			finishPlan(ctx, taskPlan)
 			_ = svcCtx.TaskPlansModel.Update(ctx, taskPlan)
+			svcCtx.TaskEvents.Publish(event.TaskEvent{
+				Tid:    taskPlan.Tid,
+				Type:   event.TaskEventPlanEnd,
+				Status: taskPlan.Status,
+				Node:   taskPlan.Name,
+				Pid:    pid,
+				Step:   UrlAnalyseSteps[taskPlan.Name].Step,
+			})
 			return ctx
 		}).OnErrorFn(
 		func(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
@@ -118,7 +154,17 @@ func BuildAnalysisGraph(svct *svc.ServiceContext) error {
 			if err != nil {
 				taskPlan.Error = err.Error()
 			}
+			finishPlan(ctx, taskPlan)
 			_ = svcCtx.TaskPlansModel.Update(ctx, taskPlan)
+			svcCtx.TaskEvents.Publish(event.TaskEvent{
+				Tid:    taskPlan.Tid,
+				Type:   event.TaskEventPlanError,
+				Status: taskPlan.Status,
+				Node:   taskPlan.Name,
+				Pid:    pid,
+				Step:   UrlAnalyseSteps[taskPlan.Name].Step,
+				Error:  taskPlan.Error,
+			})
 			return ctx
 		})
 	url_analyse_runnable = runnable
@@ -126,6 +172,15 @@ func BuildAnalysisGraph(svct *svc.ServiceContext) error {
 	return nil
 }
 
+// finishPlan 记录任务计划的结束时间、耗时和 token 消耗
+func finishPlan(ctx context.Context, taskPlan *model.TaskPlans) {
+	taskPlan.EndedAt = time.Now()
+	if !taskPlan.StartedAt.IsZero() {
+		taskPlan.Duration = taskPlan.EndedAt.Sub(taskPlan.StartedAt).Milliseconds()
+	}
+	taskPlan.PromptTokens, taskPlan.CompletionTokens = getTokenUsage(ctx)
+}
+
 func tenl(info *callbacks.RunInfo) string {
 	if info.Name != "" {
 		return info.Name
@@ -139,17 +194,15 @@ type contextKey string
 const (
 	urlAnalyseContextKey contextKey = "url_analyse_context"
 	TraceId              contextKey = "trace_id"
+	tokenUsageKey        contextKey = "token_usage"
 )
 
-func RunUrlAnalyseAgent(tid string, url string) error {
+func RunUrlAnalyseAgent(ctx context.Context, tid string, url string) error {
 	start := map[string]any{
 		"tid": tid,
 		"url": url,
 	}
-	value := map[string]string{
-		"tid": tid,
-	}
-	ctx := context.WithValue(context.Background(), urlAnalyseContextKey, value)
+	ctx = context.WithValue(ctx, urlAnalyseContextKey, newPlanTrace(tid))
 	_, err := url_analyse_runnable.Invoke(ctx, start, compose.WithCallbacks(traceHandler.Build()))
 	if err != nil {
 		logx.Errorf("run url analyse agent error: %v", err)
@@ -32,7 +32,7 @@ func TestBuildAnalysisGraph(t *testing.T) {
 				t.Errorf("BuildAnalysisGraph() error = %v", err)
 				return
 			}
-			RunUrlAnalyseAgent("123", tt.args.url)
+			RunUrlAnalyseAgent(tt.args.ctx, "123", tt.args.url)
 			time.Sleep(10 * time.Second)
 		})
 	}
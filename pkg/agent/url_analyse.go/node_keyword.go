@@ -0,0 +1,131 @@
+package url_analyse
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	internalmodel "github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/pkg/model"
+)
+
+/*
+request:
+	{
+		"resource_id": 1,
+		"labels": [...],
+		"tags": [...],
+		"summarize": "golang 是一种编程语言",
+		"rule_added": [],
+		"rule_removed": []
+	}
+
+response:
+	{
+		"resource_id": 1,
+		"labels": [...],
+		"tags": [...],
+		"summarize": "golang 是一种编程语言",
+		"rule_added": [],
+		"rule_removed": [],
+		"keywords": [
+			{"name": "Go", "type": "technology", "weight": 0.9}
+		]
+	}
+*/
+
+// 每个资源最多保存的关键词数量
+const maxKeywords = 20
+
+type Keywords struct {
+	Keywords []Keyword `json:"keywords"`
+}
+
+// Keyword 大模型返回的关键词或命名实体
+type Keyword struct {
+	Name   string  `json:"name"`
+	Type   string  `json:"type"`
+	Weight float64 `json:"weight"`
+}
+
+var keywordTypes = map[string]bool{
+	internalmodel.KeywordTypeKeyword:      true,
+	internalmodel.KeywordTypePerson:       true,
+	internalmodel.KeywordTypeProduct:      true,
+	internalmodel.KeywordTypeOrganization: true,
+	internalmodel.KeywordTypeTechnology:   true,
+}
+
+// KeywordNodeHandler 从标题和总结中提取关键词和命名实体, 替换资源已有的关键词
+func KeywordNodeHandler(ctx context.Context, param map[string]any) (map[string]any, error) {
+	resourceId := param["resource_id"].(int64)
+	summarize, _ := param["summarize"].(string)
+	resource, err := svcCtx.ResourceModel.Get(ctx, resourceId)
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := llmKeywords(ctx, resource.Title+"\n"+summarize)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*internalmodel.ResourceKeywords, len(keywords))
+	for i, keyword := range keywords {
+		records[i] = &internalmodel.ResourceKeywords{Keyword: keyword.Name, Type: keyword.Type, Weight: keyword.Weight}
+	}
+	if err := svcCtx.ResourceKeywordsModel.SetKeywords(ctx, resourceId, records); err != nil {
+		return nil, err
+	}
+	logx.Infof("keyword resource: %d, keywords: %d", resourceId, len(keywords))
+	result := make(map[string]any, len(param)+1)
+	for k, v := range param {
+		result[k] = v
+	}
+	result["keywords"] = keywords
+	return result, nil
+}
+
+func llmKeywords(ctx context.Context, content string) ([]Keyword, error) {
+	ctModel, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := model.ChatPromptKeywords(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	respond, err := ctModel.Generate(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	addTokenUsage(ctx, respond)
+	entity, err := schema.NewMessageJSONParser[Keywords](&schema.MessageJSONParseConfig{
+		ParseFrom: schema.MessageParseFromContent,
+	}).Parse(ctx, respond)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeKeywords(entity.Keywords), nil
+}
+
+// normalizeKeywords 去掉空关键词, 未知类型归为普通关键词, 权重限制在 0 到 1, 最多保留 maxKeywords 个
+func normalizeKeywords(keywords []Keyword) []Keyword {
+	result := make([]Keyword, 0, len(keywords))
+	for _, keyword := range keywords {
+		keyword.Name = strings.TrimSpace(keyword.Name)
+		if keyword.Name == "" {
+			continue
+		}
+		keyword.Type = strings.ToLower(strings.TrimSpace(keyword.Type))
+		if !keywordTypes[keyword.Type] {
+			keyword.Type = internalmodel.KeywordTypeKeyword
+		}
+		keyword.Weight = min(max(keyword.Weight, 0), 1)
+		result = append(result, keyword)
+		if len(result) == maxKeywords {
+			break
+		}
+	}
+	return result
+}
@@ -2,10 +2,13 @@ package url_analyse
 
 import (
 	"context"
-	"strings"
+	"encoding/json"
 
 	"github.com/cloudwego/eino/schema"
+	"github.com/zeromicro/go-zero/core/logx"
 
+	internalmodel "github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/tagging"
 	"github.com/XXueTu/wise/pkg/model"
 )
 
@@ -21,16 +24,37 @@ request:
 
 response:
 	{
+		"labels": [
+			{"name": "golang", "confidence": 0.95},
+			{"name": "algorithm", "confidence": 0.6}
+		],
 		"tags": [
-			"golang",
-			"algorithm"
+			"Golang"
 		],
+		"resource_id": 1,
 		"summarize": "golang 是一种编程语言，算法是一种解决问题的思路",
 	}
 */
 
 type Tags struct {
-	Tags []string `json:"tags"`
+	Tags []Label `json:"tags"`
+}
+
+// Label 大模型返回的标签及置信度
+type Label struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+// UnmarshalJSON 兼容只返回标签名称的模型, 此时置信度为 1
+func (l *Label) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*l = Label{Name: name, Confidence: 1}
+		return nil
+	}
+	type label Label
+	return json.Unmarshal(data, (*label)(l))
 }
 
 func MarkNodeHandler(ctx context.Context, param map[string]any) (map[string]any, error) {
@@ -40,10 +64,12 @@ func MarkNodeHandler(ctx context.Context, param map[string]any) (map[string]any,
 	if err != nil {
 		return nil, err
 	}
-	err = updateResource(ctx, resourceId, summarize["tags"].([]string), summarize["summarize"].(string))
+	tags, err := updateResource(ctx, resourceId, summarize["labels"].([]Label), summarize["summarize"].(string))
 	if err != nil {
 		return nil, err
 	}
+	summarize["tags"] = tags
+	summarize["resource_id"] = resourceId
 	return summarize, nil
 }
 
@@ -69,6 +95,7 @@ func llmMark(ctx context.Context, segments []string) (map[string]any, error) {
 			if err != nil {
 				return nil, err
 			}
+			addTokenUsage(ctx, respond)
 			historyMessages = append(historyMessages, respond)
 			// 清空计数
 			totalWords = 0
@@ -85,6 +112,7 @@ func llmMark(ctx context.Context, segments []string) (map[string]any, error) {
 	if err != nil {
 		return nil, err
 	}
+	addTokenUsage(ctx, tags)
 	tagsEntity, err := schema.NewMessageJSONParser[Tags](&schema.MessageJSONParseConfig{
 		ParseFrom: schema.MessageParseFromContent,
 	}).Parse(ctx, tags)
@@ -94,24 +122,41 @@ func llmMark(ctx context.Context, segments []string) (map[string]any, error) {
 
 	// 每达到1000字左右就行总结一次
 	return map[string]any{
-		"tags":      tagsEntity.Tags,
+		"labels":    tagsEntity.Tags,
 		"summarize": sumarize.Content,
 	}, nil
 }
 
-func updateResource(ctx context.Context, resourceId int64, tags []string, describe string) error {
-	// 更新 resource 的 tags 和 describe
+// updateResource 更新资源描述, 将大模型返回的标签归并到已有标签后追加, 保留导入时的标签
+// 返回最终关联的标签名称
+func updateResource(ctx context.Context, resourceId int64, labels []Label, describe string) ([]string, error) {
 	resource, err := svcCtx.ResourceModel.Get(ctx, resourceId)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	resource.Tags = strings.Join(tags, ",")
-	// 创建 tag
-
 	resource.Describe = describe
 	err = svcCtx.ResourceModel.Update(ctx, resource)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	input := make([]tagging.Label, len(labels))
+	for i, label := range labels {
+		input[i] = tagging.Label{Name: label.Name, Confidence: label.Confidence}
+	}
+	matches, err := svcCtx.TagResolver.Resolve(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]*internalmodel.ResourceTags, len(matches))
+	names := make([]string, len(matches))
+	for i, match := range matches {
+		logx.Infof("mark resource: %d, label: %s, tag: %s, method: %s, confidence: %.2f",
+			resourceId, match.Label, match.TagName, match.Method, match.Confidence)
+		links[i] = &internalmodel.ResourceTags{TagUid: match.TagUid, Label: match.Label, Confidence: match.Confidence}
+		names[i] = match.TagName
+	}
+	if err := svcCtx.ResourceTagsModel.AddLabeled(ctx, resourceId, links); err != nil {
+		return nil, err
 	}
-	return nil
+	return names, nil
 }
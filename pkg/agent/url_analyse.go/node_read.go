@@ -2,6 +2,8 @@ package url_analyse
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/XXueTu/wise/internal/model"
 	"github.com/XXueTu/wise/pkg/spiders"
@@ -31,13 +33,7 @@ func ReadNodeHandler(ctx context.Context, param map[string]any) (map[string]any,
 	if err != nil {
 		return nil, err
 	}
-	resource := &model.Resource{
-		URL:     url,
-		Title:   title,
-		Content: content,
-		Type:    types,
-	}
-	err = svcCtx.ResourceModel.Create(ctx, resource)
+	resource, err := saveResource(ctx, url, types, title, content)
 	if err != nil {
 		return nil, err
 	}
@@ -49,3 +45,26 @@ func ReadNodeHandler(ctx context.Context, param map[string]any) (map[string]any,
 		"content":     content, // 内容
 	}, nil
 }
+
+// saveResource 保存解析内容, 导入时已保存的资源保留原标题、收藏时间和标签
+func saveResource(ctx context.Context, url, types, title, content string) (*model.Resource, error) {
+	resource, err := svcCtx.ResourceModel.GetByURL(ctx, url)
+	if errors.Is(err, sql.ErrNoRows) {
+		resource = &model.Resource{
+			URL:     url,
+			Title:   title,
+			Content: content,
+			Type:    types,
+		}
+		return resource, svcCtx.ResourceModel.Create(ctx, resource)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resource.Title == "" {
+		resource.Title = title
+	}
+	resource.Content = content
+	resource.Type = types
+	return resource, svcCtx.ResourceModel.Update(ctx, resource)
+}
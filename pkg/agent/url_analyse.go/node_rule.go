@@ -0,0 +1,55 @@
+package url_analyse
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+/*
+request:
+	{
+		"resource_id": 1,
+		"labels": [
+			{"name": "golang", "confidence": 0.95}
+		],
+		"tags": [
+			"Golang"
+		],
+		"summarize": "golang 是一种编程语言"
+	}
+
+response:
+	{
+		"resource_id": 1,
+		"labels": [...],
+		"tags": [...],
+		"summarize": "golang 是一种编程语言",
+		"rule_added": ["tag_uid"],
+		"rule_removed": []
+	}
+*/
+
+// RuleNodeHandler 标注完成后按自定义标签规则添加或移除标签
+func RuleNodeHandler(ctx context.Context, param map[string]any) (map[string]any, error) {
+	resourceId := param["resource_id"].(int64)
+	labels, _ := param["labels"].([]Label)
+	names := make([]string, len(labels))
+	for i, label := range labels {
+		names[i] = label.Name
+	}
+	effect, err := svcCtx.TagRules.ApplyResource(ctx, resourceId, names)
+	if err != nil {
+		return nil, err
+	}
+	if !effect.Empty() {
+		logx.Infof("rule resource: %d, added: %v, removed: %v", resourceId, effect.Add, effect.Remove)
+	}
+	result := make(map[string]any, len(param)+2)
+	for k, v := range param {
+		result[k] = v
+	}
+	result["rule_added"] = effect.Add
+	result["rule_removed"] = effect.Remove
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package url_analyse
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+// nodeInputs 节点的上游节点, 与工作流的 AddInput 保持一致, 用于记录计划之间的边
+var nodeInputs = map[string][]string{}
+
+// planTrace 单次执行的计划链路
+type planTrace struct {
+	tid   string
+	mu    sync.Mutex
+	index int64
+	pids  map[string]string // 节点名称 -> 任务计划唯一标识
+}
+
+func newPlanTrace(tid string) *planTrace {
+	return &planTrace{
+		tid:  tid,
+		pids: make(map[string]string),
+	}
+}
+
+// start 记录节点开始执行, 返回节点在本次执行中的序号
+func (t *planTrace) start(name string, pid string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	index := t.index
+	t.index++
+	t.pids[name] = pid
+	return index
+}
+
+// beforePid 获取上游计划的唯一标识, 多个上游以逗号分隔, parent 为整图的计划
+func (t *planTrace) beforePid(name string, parent string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pids := make([]string, 0, len(nodeInputs[name]))
+	for _, input := range nodeInputs[name] {
+		if input == compose.START {
+			if parent != "" {
+				pids = append(pids, parent)
+			}
+			continue
+		}
+		if pid, ok := t.pids[input]; ok {
+			pids = append(pids, pid)
+		}
+	}
+	return strings.Join(pids, ",")
+}
+
+// nextNodes 获取下游节点名称, 多个下游以逗号分隔, 整图返回入口节点
+func nextNodes(name string) string {
+	if name == nodeOfStart {
+		name = compose.START
+	}
+	next := make([]string, 0)
+	for node, inputs := range nodeInputs {
+		for _, input := range inputs {
+			if input == name {
+				next = append(next, node)
+			}
+		}
+	}
+	// 按步骤排序, 保证记录稳定
+	sort.Slice(next, func(i, j int) bool {
+		if nodeStep(next[i]) != nodeStep(next[j]) {
+			return nodeStep(next[i]) < nodeStep(next[j])
+		}
+		return next[i] < next[j]
+	})
+	return strings.Join(next, ",")
+}
+
+func nodeStep(name string) int64 {
+	if step, ok := UrlAnalyseSteps[name]; ok {
+		return step.Step
+	}
+	// 结束节点排在最后
+	return int64(len(UrlAnalyseSteps))
+}
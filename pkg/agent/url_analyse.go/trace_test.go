@@ -0,0 +1,56 @@
+package url_analyse
+
+import (
+	"testing"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+func TestPlanTrace(t *testing.T) {
+	nodeInputs = map[string][]string{
+		nodeOfCheck: {compose.START},
+		nodeOfRead:  {nodeOfCheck},
+		nodeOfSplit: {nodeOfRead},
+		nodeOfMark:  {nodeOfSplit, nodeOfRead},
+		compose.END: {nodeOfMark},
+	}
+	trace := newPlanTrace("tid")
+	tests := []struct {
+		name       string
+		pid        string
+		wantBefore string
+		wantNext   string
+		wantIndex  int64
+	}{
+		{name: nodeOfStart, pid: "p0", wantBefore: "", wantNext: "check", wantIndex: 0},
+		{name: nodeOfCheck, pid: "p1", wantBefore: "p0", wantNext: "read", wantIndex: 1},
+		{name: nodeOfRead, pid: "p2", wantBefore: "p1", wantNext: "split,mark", wantIndex: 2},
+		{name: nodeOfSplit, pid: "p3", wantBefore: "p2", wantNext: "mark", wantIndex: 3},
+		{name: nodeOfMark, pid: "p4", wantBefore: "p3,p2", wantNext: compose.END, wantIndex: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trace.beforePid(tt.name, "p0"); got != tt.wantBefore {
+				t.Errorf("beforePid() = %v, want %v", got, tt.wantBefore)
+			}
+			if got := nextNodes(tt.name); got != tt.wantNext {
+				t.Errorf("nextNodes() = %v, want %v", got, tt.wantNext)
+			}
+			if got := trace.start(tt.name, tt.pid); got != tt.wantIndex {
+				t.Errorf("start() = %v, want %v", got, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestTotalSteps(t *testing.T) {
+	// 最后一个节点完成时进度为 TotalSteps/TotalSteps
+	if got := UrlAnalyseSteps[nodeOfKeyword].Step; got != TotalSteps() {
+		t.Errorf("last node step = %d, want %d", got, TotalSteps())
+	}
+	for name, step := range UrlAnalyseSteps {
+		if name != nodeOfStart && (step.Step < 1 || step.Step > TotalSteps()) {
+			t.Errorf("%s step = %d, want in [1, %d]", name, step.Step, TotalSteps())
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package url_analyse
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// tokenUsage 记录单个计划的大模型 token 消耗, 节点的消耗同时累加到整图的计划
+type tokenUsage struct {
+	prompt     atomic.Int64
+	completion atomic.Int64
+	parent     *tokenUsage
+}
+
+// withTokenUsage 为计划创建独立的 token 计数, 上下文中已有的计数作为上级
+func withTokenUsage(ctx context.Context) context.Context {
+	parent, _ := ctx.Value(tokenUsageKey).(*tokenUsage)
+	return context.WithValue(ctx, tokenUsageKey, &tokenUsage{parent: parent})
+}
+
+// addTokenUsage 累加大模型返回的 token 消耗
+func addTokenUsage(ctx context.Context, message *schema.Message) {
+	usage, ok := ctx.Value(tokenUsageKey).(*tokenUsage)
+	if !ok || message == nil || message.ResponseMeta == nil || message.ResponseMeta.Usage == nil {
+		return
+	}
+	for ; usage != nil; usage = usage.parent {
+		usage.prompt.Add(int64(message.ResponseMeta.Usage.PromptTokens))
+		usage.completion.Add(int64(message.ResponseMeta.Usage.CompletionTokens))
+	}
+}
+
+// getTokenUsage 获取计划的 token 消耗
+func getTokenUsage(ctx context.Context) (prompt int64, completion int64) {
+	usage, ok := ctx.Value(tokenUsageKey).(*tokenUsage)
+	if !ok {
+		return 0, 0
+	}
+	return usage.prompt.Load(), usage.completion.Load()
+}
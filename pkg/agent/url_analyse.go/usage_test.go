@@ -0,0 +1,44 @@
+package url_analyse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestTokenUsage(t *testing.T) {
+	message := func(prompt, completion int) *schema.Message {
+		return &schema.Message{ResponseMeta: &schema.ResponseMeta{Usage: &schema.TokenUsage{
+			PromptTokens:     prompt,
+			CompletionTokens: completion,
+		}}}
+	}
+	graph := withTokenUsage(context.Background())
+	mark := withTokenUsage(graph)
+	keyword := withTokenUsage(graph)
+	addTokenUsage(mark, message(10, 2))
+	addTokenUsage(mark, message(5, 1))
+	addTokenUsage(keyword, message(7, 3))
+	addTokenUsage(keyword, nil)
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		wantPrompt     int64
+		wantCompletion int64
+	}{
+		{name: nodeOfStart, ctx: graph, wantPrompt: 22, wantCompletion: 6},
+		{name: nodeOfMark, ctx: mark, wantPrompt: 15, wantCompletion: 3},
+		{name: nodeOfKeyword, ctx: keyword, wantPrompt: 7, wantCompletion: 3},
+		{name: "none", ctx: context.Background(), wantPrompt: 0, wantCompletion: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt, completion := getTokenUsage(tt.ctx)
+			if prompt != tt.wantPrompt || completion != tt.wantCompletion {
+				t.Errorf("getTokenUsage() = %v, %v, want %v, %v", prompt, completion, tt.wantPrompt, tt.wantCompletion)
+			}
+		})
+	}
+}
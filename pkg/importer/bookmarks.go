@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ParseBookmarks 解析浏览器导出的书签 (Netscape Bookmark File), 记录链接所在的文件夹
+func ParseBookmarks(r io.Reader) ([]Link, error) {
+	tokenizer := html.NewTokenizer(r)
+	links := make([]Link, 0)
+	// 文件夹栈, 遇到 H3 记录名称, 紧随其后的 DL 入栈
+	folders := make([]string, 0)
+	pending := ""
+	var link *Link
+	inFolder := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return links, nil
+			}
+			return nil, tokenizer.Err()
+		case html.StartTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "h3":
+				inFolder = true
+				pending = ""
+			case "dl":
+				folders = append(folders, pending)
+				pending = ""
+			case "a":
+				href := attr(token, "href")
+				u, ok := Normalize(href)
+				if !ok {
+					continue
+				}
+				link = &Link{
+					URL:     u,
+					Folders: nonEmpty(folders),
+					Tags:    splitTags(attr(token, "tags")),
+					AddedAt: parseTime(attr(token, "add_date")),
+				}
+			}
+		case html.TextToken:
+			text := strings.TrimSpace(string(tokenizer.Text()))
+			if inFolder {
+				pending += text
+			} else if link != nil {
+				link.Title += text
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "h3":
+				inFolder = false
+			case "dl":
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			case "a":
+				if link != nil {
+					links = append(links, *link)
+					link = nil
+				}
+			}
+		}
+	}
+}
+
+func attr(token html.Token, key string) string {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// nonEmpty 复制非空的文件夹名称, 顶层 DL 没有名称
+func nonEmpty(folders []string) []string {
+	result := make([]string, 0, len(folders))
+	for _, f := range folders {
+		if f != "" {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 常见导出文件的列名, Pocket: title,url,time_added,tags; Raindrop: title,url,folder,tags,created
+var (
+	urlColumns    = []string{"url", "link", "href"}
+	titleColumns  = []string{"title", "name"}
+	tagsColumns   = []string{"tags", "tag"}
+	folderColumns = []string{"folder", "collection"}
+	timeColumns   = []string{"time_added", "created", "created_at", "add_date"}
+)
+
+// ParseCsv 解析 CSV, 有表头时按列名读取, 否则取每行第一个链接
+func ParseCsv(r io.Reader) ([]Link, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty csv")
+	}
+
+	header := make(map[string]int)
+	for i, name := range records[0] {
+		header[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	urlIndex := column(header, urlColumns)
+	links := make([]Link, 0, len(records))
+	if urlIndex < 0 {
+		// 没有表头, 每行取第一个链接
+		for _, record := range records {
+			for _, field := range record {
+				if u, ok := Normalize(field); ok {
+					links = append(links, Link{URL: u})
+					break
+				}
+			}
+		}
+		return links, nil
+	}
+
+	titleIndex := column(header, titleColumns)
+	tagsIndex := column(header, tagsColumns)
+	folderIndex := column(header, folderColumns)
+	timeIndex := column(header, timeColumns)
+	for _, record := range records[1:] {
+		u, ok := Normalize(field(record, urlIndex))
+		if !ok {
+			continue
+		}
+		link := Link{
+			URL:     u,
+			Title:   strings.TrimSpace(field(record, titleIndex)),
+			Tags:    splitTags(field(record, tagsIndex)),
+			AddedAt: parseTime(field(record, timeIndex)),
+		}
+		if folder := strings.TrimSpace(field(record, folderIndex)); folder != "" {
+			link.Folders = strings.Split(folder, "/")
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+func column(header map[string]int, names []string) int {
+	for _, name := range names {
+		if i, ok := header[name]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// splitTags 拆分标签, Pocket 使用 | 分隔, Raindrop 使用逗号分隔
+func splitTags(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '|' || r == ','
+	})
+	tags := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			tags = append(tags, f)
+		}
+	}
+	return tags
+}
+
+// parseTime 解析收藏时间, 支持 Unix 秒和 RFC3339
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0)
+	}
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
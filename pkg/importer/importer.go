@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 支持的文件格式
+const (
+	FormatText      = "txt"
+	FormatCsv       = "csv"
+	FormatBookmarks = "html"
+)
+
+// ErrUnsupportedFormat 不支持的文件格式
+var ErrUnsupportedFormat = errors.New("unsupported import format")
+
+// Link 导入的链接
+type Link struct {
+	URL     string    // 链接
+	Title   string    // 标题
+	Folders []string  // 所在文件夹, 由外到内
+	Tags    []string  // 标签
+	AddedAt time.Time // 收藏时间, 未知时为零值
+}
+
+// 浏览器书签的根目录和 Pocket 的占位标签, 不作为标签导入
+var ignoredTags = map[string]bool{
+	"bookmarks bar":     true,
+	"bookmarks toolbar": true,
+	"bookmarks menu":    true,
+	"other bookmarks":   true,
+	"mobile bookmarks":  true,
+	"unfiled":           true,
+	"书签栏":               true,
+	"其他书签":              true,
+	"移动设备书签":            true,
+	"书签工具栏":             true,
+	"书签菜单":              true,
+	"_untagged_":        true,
+}
+
+// TagNames 合并文件夹和标签作为标签名称, 去重并忽略浏览器根目录
+func (l Link) TagNames() []string {
+	names := make([]string, 0, len(l.Folders)+len(l.Tags))
+	seen := make(map[string]bool)
+	for _, name := range append(append([]string{}, l.Folders...), l.Tags...) {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || ignoredTags[key] || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// Detect 根据文件名和内容判断格式
+func Detect(filename string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".html", ".htm":
+		return FormatBookmarks
+	case ".csv":
+		return FormatCsv
+	case ".txt":
+		return FormatText
+	}
+	head := strings.ToLower(string(data[:min(len(data), 512)]))
+	if strings.Contains(head, "<!doctype netscape-bookmark-file") || strings.Contains(head, "<html") {
+		return FormatBookmarks
+	}
+	return FormatText
+}
+
+// Parse 解析文件中的链接, 格式为空时自动识别
+func Parse(format string, filename string, r io.Reader) ([]Link, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = Detect(filename, data)
+	}
+	var links []Link
+	switch format {
+	case FormatText:
+		links = ParseText(string(data))
+	case FormatCsv:
+		links, err = ParseCsv(bytes.NewReader(data))
+	case FormatBookmarks:
+		links, err = ParseBookmarks(bytes.NewReader(data))
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// ParseText 解析文本中的链接, 以换行、逗号或空白分隔, 只保留 http/https 链接
+func ParseText(text string) []Link {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r' || r == '\t' || r == ' '
+	})
+	links := make([]Link, 0, len(fields))
+	for _, field := range fields {
+		if u, ok := Normalize(field); ok {
+			links = append(links, Link{URL: u})
+		}
+	}
+	return links
+}
+
+// Normalize 清理链接, 只接受带域名的 http/https 链接
+func Normalize(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	return raw, true
+}
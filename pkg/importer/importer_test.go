@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func urls(links []Link) []string {
+	result := make([]string, 0, len(links))
+	for _, l := range links {
+		result = append(result, l.URL)
+	}
+	return result
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     []string
+	}{
+		{
+			name:     "text",
+			filename: "urls.txt",
+			content:  "https://a.com/1, https://b.com/2\nftp://c.com\n  http://d.com  \nnot-a-url",
+			want:     []string{"https://a.com/1", "https://b.com/2", "http://d.com"},
+		},
+		{
+			name:     "csv-without-header",
+			filename: "urls.csv",
+			content:  "1,https://a.com\n2,https://b.com",
+			want:     []string{"https://a.com", "https://b.com"},
+		},
+		{
+			name:     "detect-bookmarks",
+			filename: "export",
+			content:  `<!DOCTYPE NETSCAPE-Bookmark-file-1><DL><p><DT><A HREF="https://a.com">A</A></DL>`,
+			want:     []string{"https://a.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, err := Parse("", tt.filename, strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := urls(links); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCsv(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Link
+	}{
+		{
+			name:    "pocket",
+			content: "title,url,time_added,tags,status\nGo,https://go.dev,1700000000,lang|go,unread",
+			want:    Link{URL: "https://go.dev", Title: "Go", Tags: []string{"lang", "go"}, AddedAt: time.Unix(1700000000, 0)},
+		},
+		{
+			name:    "raindrop",
+			content: "id,title,note,excerpt,url,folder,tags,created\n1,Go,,,https://go.dev,Dev/Lang,\"lang, go\",2023-11-14T22:13:20Z",
+			want:    Link{URL: "https://go.dev", Title: "Go", Folders: []string{"Dev", "Lang"}, Tags: []string{"lang", "go"}, AddedAt: time.Unix(1700000000, 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, err := ParseCsv(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(links) != 1 {
+				t.Fatalf("ParseCsv() = %d links, want 1", len(links))
+			}
+			got := links[0]
+			if got.URL != tt.want.URL || got.Title != tt.want.Title || !reflect.DeepEqual(got.Folders, tt.want.Folders) ||
+				!reflect.DeepEqual(got.Tags, tt.want.Tags) || !got.AddedAt.Equal(tt.want.AddedAt) {
+				t.Errorf("ParseCsv() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBookmarks(t *testing.T) {
+	content := `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1700000000">Dev</H3>
+    <DL><p>
+        <DT><H3>Go</H3>
+        <DL><p>
+            <DT><A HREF="https://go.dev" ADD_DATE="1700000000" TAGS="lang">The Go Language</A>
+        </DL><p>
+        <DT><A HREF="https://github.com">GitHub</A>
+    </DL><p>
+    <DT><A HREF="https://example.com">Example</A>
+    <DT><A HREF="javascript:void(0)">Bookmarklet</A>
+</DL><p>`
+	links, err := ParseBookmarks(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Link{
+		{URL: "https://go.dev", Title: "The Go Language", Folders: []string{"Dev", "Go"}, Tags: []string{"lang"}, AddedAt: time.Unix(1700000000, 0)},
+		{URL: "https://github.com", Title: "GitHub", Folders: []string{"Dev"}, Tags: []string{}},
+		{URL: "https://example.com", Title: "Example", Folders: []string{}, Tags: []string{}},
+	}
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("ParseBookmarks() = %+v, want %+v", links, want)
+	}
+}
+
+func TestLinkTagNames(t *testing.T) {
+	tests := []struct {
+		name string
+		link Link
+		want []string
+	}{
+		{
+			name: "chrome",
+			link: Link{Folders: []string{"Bookmarks bar", "Dev", "Go"}, Tags: []string{"go", "lang"}},
+			want: []string{"Dev", "Go", "lang"},
+		},
+		{
+			name: "pocket-untagged",
+			link: Link{Tags: []string{"_untagged_"}},
+			want: []string{},
+		},
+		{
+			name: "chinese-root",
+			link: Link{Folders: []string{"书签栏", "阅读"}},
+			want: []string{"阅读"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.link.TagNames(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TagNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
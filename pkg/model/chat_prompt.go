@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"strings"
 
 	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/schema"
@@ -36,7 +37,7 @@ func ChatPromptSummarize(ctx context.Context, input []*schema.Message, history [
 
 func ChatPromptLabel(ctx context.Context, input []*schema.Message) ([]*schema.Message, error) {
 
-	systemTpl := "你是一个专业的内容分类助手，你的任务是根据用户的输入，生成5个左右的标签。用户输入：{user_input},你只能输出纯 JSON，不要包含任何额外文本、注释或格式标记（如 ```json ```）。请严格输出一个 JSON 格式的结果，不要包含任何额外文本,json key是 tags,value 是字符串数组"
+	systemTpl := "你是一个专业的内容分类助手，你的任务是根据用户的输入，生成5个左右的标签。用户输入：{user_input},你只能输出纯 JSON，不要包含任何额外文本、注释或格式标记（如 ```json ```）。请严格输出一个 JSON 格式的结果，不要包含任何额外文本,json key是 tags,value 是对象数组,每个对象包含 name(标签名称) 和 confidence(标签与内容的相关程度,0 到 1 之间的小数)"
 
 	chatTpl := prompt.FromMessages(schema.FString,
 		schema.SystemMessage(systemTpl),
@@ -51,3 +52,42 @@ func ChatPromptLabel(ctx context.Context, input []*schema.Message) ([]*schema.Me
 	}
 	return msgList, nil
 }
+
+// ChatPromptClassify 从候选标签中选择与内容相关的标签, 拆分标签时使用
+func ChatPromptClassify(ctx context.Context, content string, candidates []string) ([]*schema.Message, error) {
+
+	systemTpl := "你是一个专业的内容分类助手，你的任务是从候选标签中选择与用户输入内容相关的标签，可以选择多个，都不相关时返回空数组，不能返回候选标签以外的标签。候选标签：{candidates}。你只能输出纯 JSON，不要包含任何额外文本、注释或格式标记（如 ```json ```）,json key是 tags,value 是字符串数组"
+
+	chatTpl := prompt.FromMessages(schema.FString,
+		schema.SystemMessage(systemTpl),
+		schema.UserMessage("{content}"),
+	)
+	msgList, err := chatTpl.Format(ctx, map[string]any{
+		"candidates": strings.Join(candidates, "、"),
+		"content":    content,
+	})
+	if err != nil {
+		logx.Errorf("Format failed, err=%v", err)
+		return nil, err
+	}
+	return msgList, nil
+}
+
+// ChatPromptKeywords 提取内容中的关键词和命名实体
+func ChatPromptKeywords(ctx context.Context, content string) ([]*schema.Message, error) {
+
+	systemTpl := "你是一个专业的信息抽取助手，你的任务是从用户输入中提取10个左右的关键词和命名实体，包括人物、产品、组织和技术，使用内容中的原始名称，不要翻译或改写。你只能输出纯 JSON，不要包含任何额外文本、注释或格式标记（如 ```json ```）,json key是 keywords,value 是对象数组,每个对象包含 name(关键词), type(类型, 只能是 person、product、organization、technology 或 keyword, 不属于前四种实体的为 keyword) 和 weight(关键词在内容中的重要程度,0 到 1 之间的小数)"
+
+	chatTpl := prompt.FromMessages(schema.FString,
+		schema.SystemMessage(systemTpl),
+		schema.UserMessage("{content}"),
+	)
+	msgList, err := chatTpl.Format(ctx, map[string]any{
+		"content": content,
+	})
+	if err != nil {
+		logx.Errorf("Format failed, err=%v", err)
+		return nil, err
+	}
+	return msgList, nil
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"context"
+	"os"
+
+	"github.com/cloudwego/eino-ext/libs/acl/openai"
+	"github.com/cloudwego/eino/components/embedding"
+)
+
+// NewEmbeddingModel 创建向量模型, 与对话模型使用相同的服务地址和密钥
+func NewEmbeddingModel(ctx context.Context, modelName string) (embedding.Embedder, error) {
+	return openai.NewEmbeddingClient(ctx, &openai.EmbeddingConfig{
+		BaseURL: os.Getenv("DEFAULT_BASE_URL"),
+		APIKey:  os.Getenv("DEFAULT_API_KEY"),
+		Model:   modelName,
+	})
+}
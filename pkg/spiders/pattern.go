@@ -8,6 +8,9 @@ import (
 	"github.com/XXueTu/wise/pkg/spiders/wechat"
 )
 
+// ErrNotSupportedSpider 没有匹配的爬虫, 重试无意义
+var ErrNotSupportedSpider = errors.New("not supported spider")
+
 type Pattern struct {
 	patternMap map[string]PatternInterface
 }
@@ -43,5 +46,5 @@ func (p *Pattern) GetPattern(url string) (string, string, error) {
 		}
 	}
 	// title content error
-	return "unknown", "unknown", errors.New("not supported spider")
+	return "unknown", "unknown", ErrNotSupportedSpider
 }
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/proc"
 	"github.com/zeromicro/go-zero/rest"
 
 	"github.com/XXueTu/wise/internal/config"
 	"github.com/XXueTu/wise/internal/handler"
+	"github.com/XXueTu/wise/internal/model"
+	"github.com/XXueTu/wise/internal/model/migrations"
 	"github.com/XXueTu/wise/internal/svc"
 	"github.com/XXueTu/wise/internal/task"
 	"github.com/XXueTu/wise/pkg/agent/url_analyse.go"
@@ -18,11 +26,32 @@ import (
 var configFile = flag.String("f", "etc/wise-api.yaml", "the config file")
 
 func main() {
-	flag.Parse()
+	// wise migrate [up|down|status|unlock] -f etc/wise-api.yaml 执行数据库迁移
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		args := os.Args[2:]
+		action := "up"
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			action, args = args[0], args[1:]
+		}
+		_ = flag.CommandLine.Parse(args)
+		runMigrate(action)
+		return
+	}
+
+	// wise worker -f etc/wise-api.yaml 只运行任务调度器, 不启动 HTTP 服务
+	worker := len(os.Args) > 1 && os.Args[1] == "worker"
+	if worker {
+		_ = flag.CommandLine.Parse(os.Args[2:])
+	} else {
+		flag.Parse()
+	}
 
 	var c config.Config
 	conf.MustLoad(*configFile, &c)
-	fmt.Printf("config: %+v\n", c)
+	if worker {
+		runWorker(c)
+		return
+	}
 	server := rest.MustNewServer(c.RestConf,
 		rest.WithFileServer("/", http.Dir("dist")),
 		rest.WithCustomCors(func(header http.Header) {
@@ -47,12 +76,108 @@ func main() {
 	ctx := svc.NewServiceContext(c)
 	handler.RegisterHandlers(server, ctx)
 
-	// 初始化任务调度器
-	task.NewTaskScheduler(ctx).Start()
+	// 初始化任务调度器, 使用独立的 worker 时 API 进程只负责创建任务
+	scheduler := task.NewTaskScheduler(ctx)
+	if c.Task.Scheduler {
+		// 恢复备份时暂停本进程的调度器
+		ctx.Backup.SetScheduler(scheduler)
+		scheduler.Start()
+		// 收到退出信号后立即停止调度, 在宽限期内排空执行中的任务
+		proc.AddWrapUpListener(func() {
+			scheduler.Shutdown(c.Task.GracePeriod)
+		})
+	}
 
 	// 初始化url分析图
 	_ = url_analyse.BuildAnalysisGraph(ctx)
 
+	// 定时备份只在 API 进程中运行, 避免多个 worker 重复备份
+	ctx.Backup.Start()
+
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
 	server.Start()
+
+	// HTTP 服务已停止, 等待调度器排空后关闭数据库
+	scheduler.Shutdown(c.Task.GracePeriod)
+	if err := ctx.Close(); err != nil {
+		logx.Errorf("close db error: %v", err)
+	}
+}
+
+// runWorker 独立运行任务调度器, 收到退出信号后排空任务并关闭数据库
+func runWorker(c config.Config) {
+	c.MustSetUp()
+	ctx := svc.NewServiceContext(c)
+	_ = url_analyse.BuildAnalysisGraph(ctx)
+
+	scheduler := task.NewTaskScheduler(ctx)
+	scheduler.Start()
+	fmt.Printf("Starting worker, queue: %s...\n", c.Task.Queue.Type)
+
+	done := make(chan struct{})
+	proc.AddWrapUpListener(func() {
+		scheduler.Shutdown(c.Task.GracePeriod)
+		close(done)
+	})
+	<-done
+	if err := ctx.Close(); err != nil {
+		logx.Errorf("close db error: %v", err)
+	}
+}
+
+// runMigrate 手动执行数据库迁移, 服务启动时也会自动执行未执行的迁移
+func runMigrate(action string) {
+	var c config.Config
+	conf.MustLoad(*configFile, &c)
+	logx.MustSetup(c.Log)
+
+	db := model.OpenDB(c.Database)
+	defer db.Close()
+	ctx := context.Background()
+	switch action {
+	case "up":
+		group, err := migrations.Up(ctx, db.Writer)
+		if err != nil {
+			fmt.Printf("迁移失败: %v\n", err)
+			os.Exit(1)
+		}
+		if group.IsZero() {
+			fmt.Println("没有需要执行的迁移")
+			return
+		}
+		fmt.Printf("迁移完成: %s\n", group)
+	case "down":
+		group, err := migrations.Down(ctx, db.Writer)
+		if err != nil {
+			fmt.Printf("回滚失败: %v\n", err)
+			os.Exit(1)
+		}
+		if group.IsZero() {
+			fmt.Println("没有可以回滚的迁移")
+			return
+		}
+		fmt.Printf("回滚完成: %s\n", group)
+	case "status":
+		ms, err := migrations.Status(ctx, db.Writer)
+		if err != nil {
+			fmt.Printf("获取迁移状态失败: %v\n", err)
+			os.Exit(1)
+		}
+		for _, m := range ms {
+			if m.IsApplied() {
+				fmt.Printf("%s_%s\t已执行\t组 %d\t%s\n", m.Name, m.Comment, m.GroupID, m.MigratedAt.Format(time.DateTime))
+			} else {
+				fmt.Printf("%s_%s\t未执行\n", m.Name, m.Comment)
+			}
+		}
+	case "unlock":
+		if err := migrations.Unlock(ctx, db.Writer); err != nil {
+			fmt.Printf("释放迁移锁失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("已释放迁移锁")
+	default:
+		fmt.Printf("未知的迁移操作: %s, 可选 up|down|status|unlock\n", action)
+		os.Exit(1)
+	}
 }